@@ -1,28 +1,50 @@
 package queries
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
 	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/indexes"
 	"github.com/steve-care-software/database/domain/pointers"
 	"github.com/steve-care-software/database/domain/resources"
 	"github.com/steve-care-software/database/domain/states"
 	"github.com/steve-care-software/cryptography/domain/hash"
 )
 
+// liveCacheSize is the amount of states whose live keys are kept in memory
+const liveCacheSize = 8
+
 type application struct {
 	resRepository    resources.Repository
 	commitRepository commits.Repository
 	stateRepository  states.Repository
+	selector         Selector
+	indexRepository  indexes.Repository
+	mutex            sync.Mutex
+	liveCache        map[string]map[string][]hash.Hash
+	liveOrder        []string
 }
 
 func createApplication(
 	resRepository resources.Repository,
 	commitRepository commits.Repository,
 	stateRepository states.Repository,
+	selector Selector,
+	indexRepository indexes.Repository,
 ) Application {
 	out := application{
 		resRepository:    resRepository,
 		commitRepository: commitRepository,
 		stateRepository:  stateRepository,
+		selector:         selector,
+		indexRepository:  indexRepository,
+		liveCache:        map[string]map[string][]hash.Hash{},
+		liveOrder:        []string{},
 	}
 
 	return &out
@@ -45,9 +67,567 @@ func (app *application) State(hash hash.Hash) (states.State, error) {
 		return nil, err
 	}
 
+	if head == nil {
+		return nil, &failures.StateNotFoundError{
+			State: hash,
+		}
+	}
+
 	return head.Fetch(hash)
 }
 
+// Log returns the states from the head to the root, each exposing the commits, authors and messages it originates from
+func (app *application) Log() ([]states.State, error) {
+	head, err := app.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	out := []states.State{}
+	current := head
+	for current != nil {
+		out = append(out, current)
+		if !current.HasPrevious() {
+			break
+		}
+
+		previous, err := current.Previous()
+		if err != nil {
+			return nil, err
+		}
+
+		current = previous
+	}
+
+	return out, nil
+}
+
+// Get returns the value of a resource at the head state
+func (app *application) Get(namespace string, resource hash.Hash) (Entry, error) {
+	head, err := app.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	if head == nil {
+		return nil, &failures.ResourceNotFoundError{
+			Namespace: namespace,
+			Resource:  resource,
+		}
+	}
+
+	return app.get(head, namespace, resource)
+}
+
+// GetAt returns the value of a resource at a past state
+func (app *application) GetAt(stateHash hash.Hash, namespace string, resource hash.Hash) (Entry, error) {
+	state, err := app.State(stateHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.get(state, namespace, resource)
+}
+
+func (app *application) get(state states.State, namespace string, resource hash.Hash) (Entry, error) {
+	current, err := state.Writer(namespace, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	ptr, err := current.Pointers().Fetch(namespace, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := app.resRepository.Retrieve(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	return createEntry(res.Value(), ptr, current.Hash(), current.Height(), current.CreatedOn()), nil
+}
+
+// History returns every version of a resource across the states, including its deletions, newest first
+func (app *application) History(namespace string, resource hash.Hash) ([]Entry, error) {
+	head, err := app.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	out := []Entry{}
+	current := head
+	for current != nil {
+		ptrs := current.Pointers()
+		if ptrs.Exists(namespace, resource) {
+			ptr, err := ptrs.Fetch(namespace, resource)
+			if err != nil {
+				return nil, err
+			}
+
+			var value []byte
+			if !ptr.IsDeleted() {
+				res, err := app.resRepository.Retrieve(ptr)
+				if err != nil {
+					return nil, err
+				}
+
+				value = res.Value()
+			}
+
+			out = append(out, createEntry(value, ptr, current.Hash(), current.Height(), current.CreatedOn()))
+		}
+
+		if !current.HasPrevious() {
+			break
+		}
+
+		previous, err := current.Previous()
+		if err != nil {
+			return nil, err
+		}
+
+		current = previous
+	}
+
+	if len(out) <= 0 {
+		return nil, &failures.ResourceNotFoundError{
+			Namespace: namespace,
+			Resource:  resource,
+		}
+	}
+
+	return out, nil
+}
+
+// Namespaces returns the namespaces containing at least one live key at the head state, sorted
+func (app *application) Namespaces() ([]string, error) {
+	head, err := app.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	if head == nil {
+		return []string{}, nil
+	}
+
+	return app.namespaces(head)
+}
+
+// NamespacesAt returns the namespaces containing at least one live key at a past state, sorted
+func (app *application) NamespacesAt(stateHash hash.Hash) ([]string, error) {
+	state, err := app.State(stateHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.namespaces(state)
+}
+
+func (app *application) namespaces(state states.State) ([]string, error) {
+	live, err := app.live(state)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []string{}
+	for namespace := range live {
+		out = append(out, namespace)
+	}
+
+	sort.Strings(out)
+	return out, nil
+}
+
+// Keys returns a page of the live keys of a namespace at the head state, starting after the cursor, an empty cursor starting at the first key
+func (app *application) Keys(namespace string, cursor hash.Hash, amount uint) (Page, error) {
+	head, err := app.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	if head == nil {
+		return createPage([]pointers.Pointer{}), nil
+	}
+
+	return app.keys(head, namespace, cursor, amount)
+}
+
+// KeysAt returns a page of the live keys of a namespace at a past state, starting after the cursor, an empty cursor starting at the first key
+func (app *application) KeysAt(stateHash hash.Hash, namespace string, cursor hash.Hash, amount uint) (Page, error) {
+	state, err := app.State(stateHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.keys(state, namespace, cursor, amount)
+}
+
+func (app *application) keys(state states.State, namespace string, cursor hash.Hash, amount uint) (Page, error) {
+	if amount <= 0 {
+		return nil, errors.New("the amount of keys of a page must be greater than zero")
+	}
+
+	live, err := app.live(state)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := live[namespace]
+	start := 0
+	if len(cursor) > 0 {
+		start = sort.Search(len(keys), func(i int) bool {
+			return bytes.Compare(keys[i].Bytes(), cursor.Bytes()) > 0
+		})
+	}
+
+	end := len(keys)
+	if uint(end-start) > amount {
+		end = start + int(amount)
+	}
+
+	// only the pointers of the page are resolved, since the live keys are cached without their pointers:
+	list := []pointers.Pointer{}
+	for _, oneResource := range keys[start:end] {
+		ptr, err := state.Pointer(namespace, oneResource)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, ptr)
+	}
+
+	if end < len(keys) {
+		return createPageWithNext(list, keys[end-1]), nil
+	}
+
+	return createPage(list), nil
+}
+
+// live returns the live keys at a state, by namespace, sorted, the latest version of a key winning across the chain.
+//
+// The live keys of the latest states are cached by state hash, the live keys of a state being derived from the cached
+// live keys of its nearest predecessor, so the chain is only walked back to the root once.  A compaction relocates the
+// pointers of the retained states without changing their live keys, which is why the pointers are not cached.
+func (app *application) live(state states.State) (map[string][]hash.Hash, error) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	walked := []states.State{}
+	out := map[string]map[string]hash.Hash{}
+	for current := state; current != nil; {
+		if cached, ok := app.liveCache[current.Hash().String()]; ok {
+			if len(walked) <= 0 {
+				return cached, nil
+			}
+
+			for namespace, keys := range cached {
+				out[namespace] = map[string]hash.Hash{}
+				for _, oneResource := range keys {
+					out[namespace][oneResource.String()] = oneResource
+				}
+			}
+
+			break
+		}
+
+		walked = append(walked, current)
+		if !current.HasPrevious() {
+			break
+		}
+
+		previous, err := current.Previous()
+		if err != nil {
+			return nil, err
+		}
+
+		current = previous
+	}
+
+	// the states are replayed from the oldest, so that the latest version of a key wins:
+	for i := len(walked) - 1; i >= 0; i-- {
+		for _, onePointer := range walked[i].Pointers().List() {
+			namespace := onePointer.Namespace()
+			if _, ok := out[namespace]; !ok {
+				out[namespace] = map[string]hash.Hash{}
+			}
+
+			keyname := onePointer.Resource().String()
+			if onePointer.IsDeleted() {
+				delete(out[namespace], keyname)
+				continue
+			}
+
+			out[namespace][keyname] = onePointer.Resource()
+		}
+	}
+
+	live := map[string][]hash.Hash{}
+	for namespace, keys := range out {
+		if len(keys) <= 0 {
+			continue
+		}
+
+		list := []hash.Hash{}
+		for _, oneResource := range keys {
+			list = append(list, oneResource)
+		}
+
+		sort.Slice(list, func(i int, j int) bool {
+			return bytes.Compare(list[i].Bytes(), list[j].Bytes()) < 0
+		})
+
+		live[namespace] = list
+	}
+
+	keyname := state.Hash().String()
+	app.liveCache[keyname] = live
+	app.liveOrder = append(app.liveOrder, keyname)
+	if len(app.liveOrder) > liveCacheSize {
+		delete(app.liveCache, app.liveOrder[0])
+		app.liveOrder = app.liveOrder[1:]
+	}
+
+	return live, nil
+}
+
+// Diff returns the resources added, modified and deleted from a state to another, in any order of the chain
+func (app *application) Diff(fromHash hash.Hash, toHash hash.Hash, withValues bool) (Diff, error) {
+	from, err := app.State(fromHash)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := app.State(toHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the resources written by the states between the older and the newer one can differ:
+	older, newer := from, to
+	if older.Height() > newer.Height() {
+		older, newer = to, from
+	}
+
+	keys := []string{}
+	touched := map[string]pointers.Pointer{}
+	for current := newer; !current.Hash().Compare(older.Hash()); {
+		for _, onePointer := range current.Pointers().List() {
+			keyname := fmt.Sprintf("%s/%s", onePointer.Namespace(), onePointer.Resource().String())
+			if _, ok := touched[keyname]; ok {
+				continue
+			}
+
+			keys = append(keys, keyname)
+			touched[keyname] = onePointer
+		}
+
+		if !current.HasPrevious() {
+			return nil, &failures.StateNotFoundError{
+				State: older.Hash(),
+			}
+		}
+
+		previous, err := current.Previous()
+		if err != nil {
+			return nil, err
+		}
+
+		current = previous
+	}
+
+	sort.Strings(keys)
+	added := []Change{}
+	modified := []Change{}
+	deleted := []Change{}
+	for _, keyname := range keys {
+		namespace := touched[keyname].Namespace()
+		resource := touched[keyname].Resource()
+		fromPtr, err := app.resolve(from, namespace, resource)
+		if err != nil {
+			return nil, err
+		}
+
+		toPtr, err := app.resolve(to, namespace, resource)
+		if err != nil {
+			return nil, err
+		}
+
+		if fromPtr == nil && toPtr == nil {
+			continue
+		}
+
+		if fromPtr != nil && toPtr != nil && fromPtr.Hash().Compare(toPtr.Hash()) {
+			continue
+		}
+
+		var fromValue []byte
+		var toValue []byte
+		if withValues {
+			fromValue, err = app.value(fromPtr)
+			if err != nil {
+				return nil, err
+			}
+
+			toValue, err = app.value(toPtr)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		change := createChange(namespace, resource, fromPtr, toPtr, fromValue, toValue)
+		if fromPtr == nil {
+			added = append(added, change)
+			continue
+		}
+
+		if toPtr == nil {
+			deleted = append(deleted, change)
+			continue
+		}
+
+		modified = append(modified, change)
+	}
+
+	return createDiff(added, modified, deleted), nil
+}
+
+// resolve returns the pointer of a live resource at a state, nil if it does not exists or has been deleted
+func (app *application) resolve(state states.State, namespace string, resource hash.Hash) (pointers.Pointer, error) {
+	ptr, err := state.Pointer(namespace, resource)
+	if errors.Is(err, failures.ErrResourceNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ptr, nil
+}
+
+func (app *application) value(ptr pointers.Pointer) ([]byte, error) {
+	if ptr == nil {
+		return nil, nil
+	}
+
+	res, err := app.resRepository.Retrieve(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Value(), nil
+}
+
+// Select returns the live resources of a namespace, at the head state, whose value matches a selector script
+func (app *application) Select(namespace string, script []byte) ([]Entry, error) {
+	head, err := app.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	if head == nil {
+		return []Entry{}, nil
+	}
+
+	return app.selectAt(head, namespace, script)
+}
+
+// SelectAt returns the live resources of a namespace, at a past state, whose value matches a selector script
+func (app *application) SelectAt(stateHash hash.Hash, namespace string, script []byte) ([]Entry, error) {
+	state, err := app.State(stateHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.selectAt(state, namespace, script)
+}
+
+func (app *application) selectAt(state states.State, namespace string, script []byte) ([]Entry, error) {
+	if app.selector == nil {
+		return nil, errors.New("the selector is mandatory in order to select resources using a script")
+	}
+
+	live, err := app.live(state)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []Entry{}
+	for _, oneResource := range live[namespace] {
+		written, err := state.Writer(namespace, oneResource)
+		if err != nil {
+			return nil, err
+		}
+
+		ptr, err := written.Pointers().Fetch(namespace, oneResource)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := app.resRepository.Retrieve(ptr)
+		if err != nil {
+			return nil, err
+		}
+
+		isMatch, err := app.selector.Match(script, res.Value())
+		if err != nil {
+			return nil, err
+		}
+
+		if !isMatch {
+			continue
+		}
+
+		out = append(out, createEntry(res.Value(), ptr, written.Hash(), written.Height(), written.CreatedOn()))
+	}
+
+	return out, nil
+}
+
+// Lookup returns the live resources, at the head state, whose field indexed by the named secondary index equals the passed field
+func (app *application) Lookup(index string, field interface{}) ([]Entry, error) {
+	if app.indexRepository == nil {
+		return nil, errors.New("the index repository is mandatory in order to lookup resources using a secondary index")
+	}
+
+	ins, err := app.indexRepository.Retrieve(index)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := app.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	if head == nil {
+		return []Entry{}, nil
+	}
+
+	list, err := app.indexRepository.Find(index, field)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []Entry{}
+	for _, oneResource := range list {
+		entry, err := app.get(head, ins.Namespace(), oneResource)
+		if err != nil {
+			// the indexes could already reflect a state pushed after the head was retrieved:
+			if errors.Is(err, failures.ErrResourceNotFound) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		out = append(out, entry)
+	}
+
+	return out, nil
+}
+
 // Commits returns the commits list
 func (app *application) Commits() ([]hash.Hash, error) {
 	return app.commitRepository.List()
@@ -0,0 +1,766 @@
+package queries
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	domain_bytes "github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/indexes"
+	"github.com/steve-care-software/database/domain/states"
+	"github.com/steve-care-software/database/infrastructure/disks"
+)
+
+func newApplicationHashForTests() hash.Hash {
+	application, err := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	if err != nil {
+		panic(err)
+	}
+
+	return *application
+}
+
+func newApplicationForTests(baseDir string) (Application, states.Service) {
+	application := newApplicationHashForTests()
+
+	index, err := indexes.NewBuilder().Create().WithName("user_by_email").WithNamespace("users").WithPath([]string{"Contact", "Email"}).WithAdapter(newUserAdapterForTests()).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	services, err := disks.NewBuilder(baseDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(application).WithIndexes([]indexes.Index{
+		index,
+	}).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	return createApplication(services.ResourceRepository(), services.CommitRepository(), services.StateRepository(), &selectorForTests{}, services.IndexRepository()), services.StateService()
+}
+
+type userForTests struct {
+	Name    string
+	Contact *contactForTests
+}
+
+type contactForTests struct {
+	Email string
+}
+
+func newUserAdapterForTests() domain_bytes.Adapter {
+	adapter, err := domain_bytes.NewAdapterBuilder().Create().WithMapping(map[string]interface{}{
+		"github.com/steve-care-software/database/applications/queries/userForTests":    userForTests{},
+		"github.com/steve-care-software/database/applications/queries/contactForTests": contactForTests{},
+	}).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	return adapter
+}
+
+func newUserForTests(name string, email string) []byte {
+	user := userForTests{
+		Name: name,
+	}
+
+	if email != "" {
+		user.Contact = &contactForTests{
+			Email: email,
+		}
+	}
+
+	data, err := newUserAdapterForTests().ToBytes(user)
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+// selectorForTests matches the values containing the script
+type selectorForTests struct {
+}
+
+// Match returns true if the value contains the script
+func (app *selectorForTests) Match(script []byte, value []byte) (bool, error) {
+	if len(script) <= 0 {
+		return false, errors.New("the script is mandatory in order to match a value")
+	}
+
+	return bytes.Contains(value, script), nil
+}
+
+func pushForTests(stateService states.Service, values map[string]map[string][]byte) {
+	commit, err := commits.NewBuilder().Create().WithValues(values).CreatedOn(time.Now().UTC()).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	err = stateService.Insert(
+		commit,
+		func(ctx commits.Commit, state states.State) error {
+			return nil
+		},
+		func(ctx commits.Commit, err error) error {
+			return err
+		},
+	)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+func newResourceForTests(name string) hash.Hash {
+	resource, err := hash.NewAdapter().FromBytes([]byte(name))
+	if err != nil {
+		panic(err)
+	}
+
+	return *resource
+}
+
+func TestApplication_Get_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateService := newApplicationForTests(baseDir)
+	first := newResourceForTests("first")
+	second := newResourceForTests("second")
+	_, err := app.Get("my_namespace", first)
+	if !errors.Is(err, failures.ErrResourceNotFound) {
+		t.Errorf("the error was expected to be a resource not found error, %v returned", err)
+		return
+	}
+
+	pushForTests(stateService, map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			first.String():  []byte("first value"),
+			second.String(): []byte("second value"),
+		},
+	})
+
+	original, _ := app.Head()
+	pushForTests(stateService, map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			first.String():  []byte("first value, updated"),
+			second.String(): nil,
+		},
+	})
+
+	pushForTests(stateService, map[string]map[string][]byte{
+		"other_namespace": map[string][]byte{
+			first.String(): []byte("other value"),
+		},
+	})
+
+	entry, err := app.Get("my_namespace", first)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if string(entry.Value()) != "first value, updated" {
+		t.Errorf("the value was expected to be %s, %s returned", "first value, updated", entry.Value())
+		return
+	}
+
+	if entry.Height() != 2 {
+		t.Errorf("the height was expected to be %d, %d returned", 2, entry.Height())
+		return
+	}
+
+	var notFound *failures.ResourceNotFoundError
+	_, err = app.Get("my_namespace", second)
+	if !errors.As(err, &notFound) || !notFound.IsDeleted {
+		t.Errorf("the error was expected to be a deleted resource error, %v returned", err)
+		return
+	}
+
+	entry, err = app.GetAt(original.Hash(), "my_namespace", second)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if string(entry.Value()) != "second value" {
+		t.Errorf("the value was expected to be %s, %s returned", "second value", entry.Value())
+		return
+	}
+
+	if !entry.State().Compare(original.Hash()) || !entry.Pointer().Resource().Compare(second) {
+		t.Errorf("the entry was expected to point to the second resource in the original state")
+		return
+	}
+
+	_, err = app.GetAt(second, "my_namespace", first)
+	if !errors.Is(err, failures.ErrStateNotFound) {
+		t.Errorf("the error was expected to be a state not found error, %v returned", err)
+		return
+	}
+}
+
+func TestApplication_Keys_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateService := newApplicationForTests(baseDir)
+	namespaces, err := app.Namespaces()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(namespaces) != 0 {
+		t.Errorf("%d namespaces were expected, %d returned", 0, len(namespaces))
+		return
+	}
+
+	values := map[string][]byte{}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		values[newResourceForTests(name).String()] = []byte(name)
+	}
+
+	pushForTests(stateService, map[string]map[string][]byte{
+		"my_namespace": values,
+		"removed_namespace": map[string][]byte{
+			newResourceForTests("removed").String(): []byte("removed value"),
+		},
+	})
+
+	original, _ := app.Head()
+	pushForTests(stateService, map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			newResourceForTests("a").String(): []byte("a, updated"),
+			newResourceForTests("b").String(): nil,
+			newResourceForTests("f").String(): []byte("f"),
+		},
+		"removed_namespace": map[string][]byte{
+			newResourceForTests("removed").String(): nil,
+		},
+	})
+
+	namespaces, err = app.Namespaces()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(namespaces) != 1 || namespaces[0] != "my_namespace" {
+		t.Errorf("only the my_namespace was expected to be live, %v returned", namespaces)
+		return
+	}
+
+	namespaces, _ = app.NamespacesAt(original.Hash())
+	if len(namespaces) != 2 {
+		t.Errorf("%d namespaces were expected at the original state, %d returned", 2, len(namespaces))
+		return
+	}
+
+	// iterate the live keys, 2 at a time:
+	seen := map[string]bool{}
+	pages := 0
+	var cursor hash.Hash
+	for {
+		page, err := app.Keys("my_namespace", cursor, 2)
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+
+		pages++
+		for _, onePointer := range page.List() {
+			keyname := onePointer.Resource().String()
+			if _, ok := seen[keyname]; ok {
+				t.Errorf("the key (hash: %s) was returned twice", keyname)
+				return
+			}
+
+			seen[keyname] = true
+		}
+
+		if !page.HasNext() {
+			break
+		}
+
+		cursor = page.Next()
+	}
+
+	if len(seen) != 5 || pages != 3 {
+		t.Errorf("%d keys were expected in %d pages, %d keys returned in %d pages", 5, 3, len(seen), pages)
+		return
+	}
+
+	if _, ok := seen[newResourceForTests("b").String()]; ok {
+		t.Errorf("the deleted key was not expected to be listed")
+		return
+	}
+
+	page, err := app.KeysAt(original.Hash(), "my_namespace", nil, 10)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(page.List()) != 5 || page.HasNext() {
+		t.Errorf("%d keys were expected at the original state, %d returned", 5, len(page.List()))
+		return
+	}
+}
+
+func TestApplication_Keys_withCachedStates_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateService := newApplicationForTests(baseDir)
+	pushForTests(stateService, map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			newResourceForTests("a").String(): []byte("a"),
+			newResourceForTests("b").String(): []byte("b"),
+		},
+	})
+
+	original, _ := app.Head()
+	_, err := app.Keys("my_namespace", nil, 10)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// the live keys of the next states are derived from the cached ones, until the first state is evicted:
+	for i := 0; i <= liveCacheSize; i++ {
+		pushForTests(stateService, map[string]map[string][]byte{
+			"my_namespace": map[string][]byte{
+				newResourceForTests("a").String(): []byte(fmt.Sprintf("a, version %d", i)),
+				newResourceForTests("b").String(): nil,
+				newResourceForTests("c").String(): []byte("c"),
+			},
+		})
+
+		page, err := app.Keys("my_namespace", nil, 10)
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+
+		values := map[string]string{}
+		for _, onePointer := range page.List() {
+			res, err := app.Resource(onePointer)
+			if err != nil {
+				t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+				return
+			}
+
+			values[onePointer.Resource().String()] = string(res.Value())
+		}
+
+		if len(values) != 2 || values[newResourceForTests("a").String()] != fmt.Sprintf("a, version %d", i) || values[newResourceForTests("c").String()] != "c" {
+			t.Errorf("the live keys were expected to be derived from the cached ones, %v returned", values)
+			return
+		}
+	}
+
+	page, err := app.KeysAt(original.Hash(), "my_namespace", nil, 1)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(page.List()) != 1 || !page.HasNext() {
+		t.Errorf("the evicted state was expected to be walked again")
+		return
+	}
+
+	page, err = app.KeysAt(original.Hash(), "my_namespace", page.Next(), 1)
+	if err != nil || len(page.List()) != 1 || page.HasNext() {
+		t.Errorf("the second page of the evicted state was expected to be its last")
+		return
+	}
+}
+
+func TestApplication_History_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateService := newApplicationForTests(baseDir)
+	resource := newResourceForTests("resource")
+	other := newResourceForTests("other")
+	_, err := app.History("my_namespace", resource)
+	if !errors.Is(err, failures.ErrResourceNotFound) {
+		t.Errorf("the error was expected to be a resource not found error, %v returned", err)
+		return
+	}
+
+	for _, oneValue := range [][]byte{[]byte("first version"), []byte("second version"), nil, []byte("third version")} {
+		pushForTests(stateService, map[string]map[string][]byte{
+			"my_namespace": map[string][]byte{
+				resource.String(): oneValue,
+			},
+		})
+
+		pushForTests(stateService, map[string]map[string][]byte{
+			"my_namespace": map[string][]byte{
+				other.String(): []byte("unrelated value"),
+			},
+		})
+	}
+
+	list, err := app.History("my_namespace", resource)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 4 {
+		t.Errorf("%d versions were expected, %d returned", 4, len(list))
+		return
+	}
+
+	expected := []string{"third version", "", "second version", "first version"}
+	for index, oneEntry := range list {
+		if string(oneEntry.Value()) != expected[index] {
+			t.Errorf("the version at index %d was expected to be %s, %s returned", index, expected[index], oneEntry.Value())
+			return
+		}
+
+		if oneEntry.IsDeleted() != (index == 1) {
+			t.Errorf("only the version at index %d was expected to be a deletion", 1)
+			return
+		}
+
+		if oneEntry.Height() != uint(7-(index*2)) {
+			t.Errorf("the version at index %d was expected to be at height %d, %d returned", index, 7-(index*2), oneEntry.Height())
+			return
+		}
+
+		if index > 0 && oneEntry.CreatedOn().After(list[index-1].CreatedOn()) {
+			t.Errorf("the versions were expected to be sorted newest first")
+			return
+		}
+	}
+}
+
+func TestApplication_Diff_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateService := newApplicationForTests(baseDir)
+	kept := newResourceForTests("kept")
+	modified := newResourceForTests("modified")
+	deleted := newResourceForTests("deleted")
+	added := newResourceForTests("added")
+	transient := newResourceForTests("transient")
+	pushForTests(stateService, map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			kept.String():     []byte("kept value"),
+			modified.String(): []byte("original value"),
+			deleted.String():  []byte("deleted value"),
+		},
+	})
+
+	from, _ := app.Head()
+	pushForTests(stateService, map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			modified.String():  []byte("modified value"),
+			transient.String(): []byte("transient value"),
+		},
+	})
+
+	pushForTests(stateService, map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			deleted.String():   nil,
+			transient.String(): nil,
+			added.String():     []byte("added value"),
+		},
+	})
+
+	to, _ := app.Head()
+	diff, err := app.Diff(from.Hash(), to.Hash(), true)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(diff.Added()) != 1 || !diff.Added()[0].Resource().Compare(added) || string(diff.Added()[0].ToValue()) != "added value" {
+		t.Errorf("the added resource was expected to be listed along with its value")
+		return
+	}
+
+	if len(diff.Modified()) != 1 || !diff.Modified()[0].Resource().Compare(modified) {
+		t.Errorf("the modified resource was expected to be listed")
+		return
+	}
+
+	change := diff.Modified()[0]
+	if string(change.FromValue()) != "original value" || string(change.ToValue()) != "modified value" {
+		t.Errorf("the modified resource was expected to contain its old and new values")
+		return
+	}
+
+	if len(diff.Deleted()) != 1 || !diff.Deleted()[0].Resource().Compare(deleted) || diff.Deleted()[0].HasTo() {
+		t.Errorf("the deleted resource was expected to be listed")
+		return
+	}
+
+	// the reversed diff swaps the added and deleted resources:
+	reversed, err := app.Diff(to.Hash(), from.Hash(), false)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(reversed.Added()) != 1 || !reversed.Added()[0].Resource().Compare(deleted) {
+		t.Errorf("the deleted resource was expected to be added in the reversed diff")
+		return
+	}
+
+	if len(reversed.Deleted()) != 1 || !reversed.Deleted()[0].Resource().Compare(added) {
+		t.Errorf("the added resource was expected to be deleted in the reversed diff")
+		return
+	}
+
+	if reversed.Modified()[0].FromValue() != nil {
+		t.Errorf("the values were not expected to be retrieved")
+		return
+	}
+}
+
+func TestApplication_Select_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateService := newApplicationForTests(baseDir)
+	first := newResourceForTests("first")
+	second := newResourceForTests("second")
+	third := newResourceForTests("third")
+	pushForTests(stateService, map[string]map[string][]byte{
+		"users": map[string][]byte{
+			first.String():  []byte(`{"name": "roger", "active": true}`),
+			second.String(): []byte(`{"name": "paul", "active": true}`),
+			third.String():  []byte(`{"name": "john", "active": false}`),
+		},
+		"other": map[string][]byte{
+			first.String(): []byte(`{"name": "jean", "active": true}`),
+		},
+	})
+
+	original, _ := app.Head()
+	pushForTests(stateService, map[string]map[string][]byte{
+		"users": map[string][]byte{
+			second.String(): []byte(`{"name": "paul", "active": false}`),
+		},
+	})
+
+	list, err := app.Select("users", []byte(`"active": true`))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 1 || !list[0].Pointer().Resource().Compare(first) {
+		t.Errorf("only the first resource was expected to match")
+		return
+	}
+
+	if !list[0].State().Compare(original.Hash()) {
+		t.Errorf("the matching resource was expected to be written by the original state")
+		return
+	}
+
+	list, err = app.SelectAt(original.Hash(), "users", []byte(`"active": true`))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 2 {
+		t.Errorf("%d resources were expected to match at the original state, %d returned", 2, len(list))
+		return
+	}
+
+	_, err = app.Select("users", nil)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+}
+
+func TestApplication_Lookup_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateService := newApplicationForTests(baseDir)
+	list, err := app.Lookup("user_by_email", "roger@example.com")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 0 {
+		t.Errorf("the list was expected to be empty, %d returned", len(list))
+		return
+	}
+
+	roger := newResourceForTests("roger")
+	lucie := newResourceForTests("lucie")
+	anonymous := newResourceForTests("anonymous")
+	pushForTests(stateService, map[string]map[string][]byte{
+		"users": map[string][]byte{
+			roger.String():     newUserForTests("roger", "roger@example.com"),
+			lucie.String():     newUserForTests("lucie", "lucie@example.com"),
+			anonymous.String(): newUserForTests("anonymous", ""),
+		},
+	})
+
+	pushForTests(stateService, map[string]map[string][]byte{
+		"users": map[string][]byte{
+			lucie.String(): newUserForTests("lucie", "roger@example.com"),
+		},
+	})
+
+	list, err = app.Lookup("user_by_email", "roger@example.com")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 2 {
+		t.Errorf("the list was expected to contain %d entries, %d returned", 2, len(list))
+		return
+	}
+
+	// lucie changed her email, so the old one no longer matches:
+	list, _ = app.Lookup("user_by_email", "lucie@example.com")
+	if len(list) != 0 {
+		t.Errorf("the list was expected to be empty, %d returned", len(list))
+		return
+	}
+
+	pushForTests(stateService, map[string]map[string][]byte{
+		"users": map[string][]byte{
+			roger.String(): nil,
+		},
+	})
+
+	// the journal is rebuilt from the states when it is missing:
+	err = os.Remove(filepath.Join(baseDir, newApplicationHashForTests().String(), "database.db.indexes"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	reopened, _ := newApplicationForTests(baseDir)
+	list, err = reopened.Lookup("user_by_email", "roger@example.com")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 1 || !list[0].Pointer().Resource().Compare(lucie) {
+		t.Errorf("the list was expected to only contain the lucie resource")
+		return
+	}
+
+	// the journal is replayed when it is present:
+	replayed, _ := newApplicationForTests(baseDir)
+	list, _ = replayed.Lookup("user_by_email", "roger@example.com")
+	if len(list) != 1 || !list[0].Pointer().Resource().Compare(lucie) {
+		t.Errorf("the list was expected to only contain the lucie resource")
+		return
+	}
+
+	_, err = app.Lookup("invalid_index", "roger@example.com")
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+}
+
+func TestApplication_Lookup_withUndecodableValue_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateService := newApplicationForTests(baseDir)
+	roger := newResourceForTests("roger")
+	lucie := newResourceForTests("lucie")
+	pushForTests(stateService, map[string]map[string][]byte{
+		"users": map[string][]byte{
+			roger.String(): newUserForTests("roger", "roger@example.com"),
+			lucie.String(): newUserForTests("lucie", "lucie@example.com"),
+		},
+	})
+
+	// a value the index cannot decode is not indexed, and replaces the key of its resource:
+	pushForTests(stateService, map[string]map[string][]byte{
+		"users": map[string][]byte{
+			lucie.String(): []byte{0, 0xff},
+		},
+	})
+
+	// the later pushes are still indexed:
+	pushForTests(stateService, map[string]map[string][]byte{
+		"users": map[string][]byte{
+			roger.String(): newUserForTests("roger", "roger@example.org"),
+		},
+	})
+
+	list, err := app.Lookup("user_by_email", "roger@example.org")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 1 || !list[0].Pointer().Resource().Compare(roger) {
+		t.Errorf("the list was expected to only contain the roger resource")
+		return
+	}
+
+	list, _ = app.Lookup("user_by_email", "lucie@example.com")
+	if len(list) != 0 {
+		t.Errorf("the list was expected to be empty, %d returned", len(list))
+		return
+	}
+
+	// the journal can still be rebuilt from the states:
+	err = os.Remove(filepath.Join(baseDir, newApplicationHashForTests().String(), "database.db.indexes"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	reopened, _ := newApplicationForTests(baseDir)
+	list, err = reopened.Lookup("user_by_email", "roger@example.org")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 1 || !list[0].Pointer().Resource().Compare(roger) {
+		t.Errorf("the list was expected to only contain the roger resource after a rebuild")
+		return
+	}
+}
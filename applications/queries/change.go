@@ -0,0 +1,75 @@
+package queries
+
+import (
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/pointers"
+)
+
+type change struct {
+	namespace string
+	resource  hash.Hash
+	from      pointers.Pointer
+	to        pointers.Pointer
+	fromValue []byte
+	toValue   []byte
+}
+
+func createChange(
+	namespace string,
+	resource hash.Hash,
+	from pointers.Pointer,
+	to pointers.Pointer,
+	fromValue []byte,
+	toValue []byte,
+) Change {
+	out := change{
+		namespace: namespace,
+		resource:  resource,
+		from:      from,
+		to:        to,
+		fromValue: fromValue,
+		toValue:   toValue,
+	}
+
+	return &out
+}
+
+// Namespace returns the namespace
+func (obj *change) Namespace() string {
+	return obj.namespace
+}
+
+// Resource returns the resource hash
+func (obj *change) Resource() hash.Hash {
+	return obj.resource
+}
+
+// HasFrom returns true if the resource is live in the from state, false otherwise
+func (obj *change) HasFrom() bool {
+	return obj.from != nil
+}
+
+// From returns the pointer of the resource in the from state, if any
+func (obj *change) From() pointers.Pointer {
+	return obj.from
+}
+
+// HasTo returns true if the resource is live in the to state, false otherwise
+func (obj *change) HasTo() bool {
+	return obj.to != nil
+}
+
+// To returns the pointer of the resource in the to state, if any
+func (obj *change) To() pointers.Pointer {
+	return obj.to
+}
+
+// FromValue returns the value of the resource in the from state, if requested
+func (obj *change) FromValue() []byte {
+	return obj.fromValue
+}
+
+// ToValue returns the value of the resource in the to state, if requested
+func (obj *change) ToValue() []byte {
+	return obj.toValue
+}
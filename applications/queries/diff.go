@@ -0,0 +1,36 @@
+package queries
+
+type diff struct {
+	added    []Change
+	modified []Change
+	deleted  []Change
+}
+
+func createDiff(
+	added []Change,
+	modified []Change,
+	deleted []Change,
+) Diff {
+	out := diff{
+		added:    added,
+		modified: modified,
+		deleted:  deleted,
+	}
+
+	return &out
+}
+
+// Added returns the resources live in the to state only
+func (obj *diff) Added() []Change {
+	return obj.added
+}
+
+// Modified returns the resources live in both states, with different pointers
+func (obj *diff) Modified() []Change {
+	return obj.modified
+}
+
+// Deleted returns the resources live in the from state only
+func (obj *diff) Deleted() []Change {
+	return obj.deleted
+}
@@ -0,0 +1,64 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/pointers"
+)
+
+type entry struct {
+	value     []byte
+	pointer   pointers.Pointer
+	state     hash.Hash
+	height    uint
+	createdOn time.Time
+}
+
+func createEntry(
+	value []byte,
+	pointer pointers.Pointer,
+	state hash.Hash,
+	height uint,
+	createdOn time.Time,
+) Entry {
+	out := entry{
+		value:     value,
+		pointer:   pointer,
+		state:     state,
+		height:    height,
+		createdOn: createdOn,
+	}
+
+	return &out
+}
+
+// Value returns the value
+func (obj *entry) Value() []byte {
+	return obj.value
+}
+
+// Pointer returns the pointer
+func (obj *entry) Pointer() pointers.Pointer {
+	return obj.pointer
+}
+
+// State returns the hash of the state that wrote the value
+func (obj *entry) State() hash.Hash {
+	return obj.state
+}
+
+// Height returns the height of the state that wrote the value
+func (obj *entry) Height() uint {
+	return obj.height
+}
+
+// CreatedOn returns the creation time of the state that wrote the value
+func (obj *entry) CreatedOn() time.Time {
+	return obj.createdOn
+}
+
+// IsDeleted returns true if the entry is a deletion, false otherwise
+func (obj *entry) IsDeleted() bool {
+	return obj.pointer.IsDeleted()
+}
@@ -0,0 +1,51 @@
+package queries
+
+import (
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/pointers"
+)
+
+type page struct {
+	list []pointers.Pointer
+	next hash.Hash
+}
+
+func createPage(
+	list []pointers.Pointer,
+) Page {
+	return createPageInternally(list, nil)
+}
+
+func createPageWithNext(
+	list []pointers.Pointer,
+	next hash.Hash,
+) Page {
+	return createPageInternally(list, next)
+}
+
+func createPageInternally(
+	list []pointers.Pointer,
+	next hash.Hash,
+) Page {
+	out := page{
+		list: list,
+		next: next,
+	}
+
+	return &out
+}
+
+// List returns the pointers of the live keys
+func (obj *page) List() []pointers.Pointer {
+	return obj.list
+}
+
+// HasNext returns true if there is a next page, false otherwise
+func (obj *page) HasNext() bool {
+	return len(obj.next) > 0
+}
+
+// Next returns the cursor of the next page, if any
+func (obj *page) Next() hash.Hash {
+	return obj.next
+}
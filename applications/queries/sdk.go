@@ -1,7 +1,10 @@
 package queries
 
 import (
+	"time"
+
 	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/indexes"
 	"github.com/steve-care-software/database/domain/pointers"
 	"github.com/steve-care-software/database/domain/resources"
 	"github.com/steve-care-software/database/domain/states"
@@ -13,11 +16,15 @@ import (
 	resRepository resources.Repository,
 	commitRepository commits.Repository,
 	stateRepository states.Repository,
+	selector Selector,
+	indexRepository indexes.Repository,
 ) Application {
 	return createApplication(
 		resRepository,
 		commitRepository,
 		stateRepository,
+		selector,
+		indexRepository,
 	)
 }*/
 
@@ -27,6 +34,8 @@ type Builder interface {
 	WithResourceRepository(resRepository resources.Repository) Builder
 	WithCommitRepository(commitRepository commits.Repository) Builder
 	WithStateRepository(stateRepository states.Repository) Builder
+	WithSelector(selector Selector) Builder
+	WithIndexRepository(indexRepository indexes.Repository) Builder
 	Now() (Application, error)
 }
 
@@ -34,7 +43,60 @@ type Builder interface {
 type Application interface {
 	Head() (states.State, error)
 	State(hash hash.Hash) (states.State, error)
+	Log() ([]states.State, error)
 	Commits() ([]hash.Hash, error)
 	Commit(hash hash.Hash) (commits.Commit, error)
 	Resource(ptr pointers.Pointer) (resources.Resource, error)
+	Get(namespace string, resource hash.Hash) (Entry, error)
+	GetAt(state hash.Hash, namespace string, resource hash.Hash) (Entry, error)
+	Namespaces() ([]string, error)
+	NamespacesAt(state hash.Hash) ([]string, error)
+	Keys(namespace string, cursor hash.Hash, amount uint) (Page, error)
+	KeysAt(state hash.Hash, namespace string, cursor hash.Hash, amount uint) (Page, error)
+	History(namespace string, resource hash.Hash) ([]Entry, error)
+	Diff(from hash.Hash, to hash.Hash, withValues bool) (Diff, error)
+	Select(namespace string, script []byte) ([]Entry, error)
+	SelectAt(state hash.Hash, namespace string, script []byte) ([]Entry, error)
+	Lookup(index string, field interface{}) ([]Entry, error)
+}
+
+// Selector represents the selector engine that decodes a value and returns true if it matches a selector script
+type Selector interface {
+	Match(script []byte, value []byte) (bool, error)
+}
+
+// Diff represents the resources that differ between two states, sorted by namespace then by resource
+type Diff interface {
+	Added() []Change
+	Modified() []Change
+	Deleted() []Change
+}
+
+// Change represents a resource that differs between two states, the values being present only when requested
+type Change interface {
+	Namespace() string
+	Resource() hash.Hash
+	HasFrom() bool
+	From() pointers.Pointer
+	HasTo() bool
+	To() pointers.Pointer
+	FromValue() []byte
+	ToValue() []byte
+}
+
+// Page represents a page of live keys sorted by resource hash, the next cursor being the last key of the page
+type Page interface {
+	List() []pointers.Pointer
+	HasNext() bool
+	Next() hash.Hash
+}
+
+// Entry represents the value of a resource, along with the pointer and the state that wrote it, a deletion containing no value
+type Entry interface {
+	Value() []byte
+	Pointer() pointers.Pointer
+	State() hash.Hash
+	Height() uint
+	CreatedOn() time.Time
+	IsDeleted() bool
 }
@@ -4,21 +4,45 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/contexts"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/resources"
+	"github.com/steve-care-software/database/domain/schemas"
 	"github.com/steve-care-software/database/domain/states"
 	"github.com/steve-care-software/cryptography/domain/hash"
 )
 
 type application struct {
-	hashAdapter      hash.Adapter
-	commitBuilder    commits.Builder
-	commitRepository commits.Repository
-	commitService    commits.Service
-	stateService     states.Service
-	queue            map[string]map[string]map[string][]byte
-	commits          map[string]hash.Hash
+	hashAdapter       hash.Adapter
+	commitBuilder     commits.Builder
+	commitRepository  commits.Repository
+	commitService     commits.Service
+	resRepository     resources.Repository
+	contextBuilder    contexts.Builder
+	contextRepository contexts.Repository
+	contextService    contexts.Service
+	stateRepository   states.Repository
+	stateService      states.Service
+	contextTTL        time.Duration
+	commitTTL         time.Duration
+	validator         Validator
+	schemaBuilder     schemas.Builder
+	schemaRepository  schemas.Repository
+	schemaService     schemas.Service
+	once              sync.Once
+	recoverErr        error
+	mutex             sync.RWMutex
+	counter           uint64
+	contexts          map[string]*context
+	commits           map[string]hash.Hash
+	pushing           map[string]bool
+	reaper            chan struct{}
+	bus               *bus
+	schemas           map[string][]byte
 }
 
 func createApplication(
@@ -26,16 +50,44 @@ func createApplication(
 	commitBuilder commits.Builder,
 	commitRepository commits.Repository,
 	commitService commits.Service,
+	resRepository resources.Repository,
+	contextBuilder contexts.Builder,
+	contextRepository contexts.Repository,
+	contextService contexts.Service,
+	stateRepository states.Repository,
 	stateService states.Service,
+	contextTTL time.Duration,
+	commitTTL time.Duration,
+	validator Validator,
+	schemaBuilder schemas.Builder,
+	schemaRepository schemas.Repository,
+	schemaService schemas.Service,
 ) Application {
 	out := application{
-		hashAdapter:      hashAdapter,
-		commitBuilder:    commitBuilder,
-		commitRepository: commitRepository,
-		commitService:    commitService,
-		stateService:     stateService,
-		queue:            map[string]map[string]map[string][]byte{},
-		commits:          map[string]hash.Hash{},
+		hashAdapter:       hashAdapter,
+		commitBuilder:     commitBuilder,
+		commitRepository:  commitRepository,
+		commitService:     commitService,
+		resRepository:     resRepository,
+		contextBuilder:    contextBuilder,
+		contextRepository: contextRepository,
+		contextService:    contextService,
+		stateRepository:   stateRepository,
+		stateService:      stateService,
+		contextTTL:        contextTTL,
+		commitTTL:         commitTTL,
+		validator:         validator,
+		schemaBuilder:     schemaBuilder,
+		schemaRepository:  schemaRepository,
+		schemaService:     schemaService,
+		recoverErr:        nil,
+		counter:           0,
+		contexts:          map[string]*context{},
+		commits:           map[string]hash.Hash{},
+		pushing:           map[string]bool{},
+		reaper:            nil,
+		bus:               createBus(),
+		schemas:           map[string][]byte{},
 	}
 
 	return &out
@@ -43,49 +95,397 @@ func createApplication(
 
 // Begin creates a context
 func (app *application) Begin() (*hash.Hash, error) {
-	now := time.Now().UTC().UnixNano()
-	str := fmt.Sprintf("%d", now)
+	err := app.recover()
+	if err != nil {
+		return nil, err
+	}
+
+	head, _, err := app.stateRepository.Retrieve()
+	if err != nil {
+		return nil, err
+	}
+
+	var base hash.Hash
+	if head != nil {
+		base = head.Hash()
+	}
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	app.counter++
+	createdOn := time.Now().UTC()
+	str := fmt.Sprintf("%d-%d", createdOn.UnixNano(), app.counter)
 	hash, err := app.hashAdapter.FromBytes([]byte(str))
 	if err != nil {
 		return nil, err
 	}
 
-	keyname := hash.String()
-	app.queue[keyname] = map[string]map[string][]byte{}
+	builder := app.contextBuilder.Create().WithHash(*hash).CreatedOn(createdOn)
+	if base != nil {
+		builder.WithBase(base)
+	}
+
+	journal, err := builder.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.contextService.Insert(journal)
+	if err != nil {
+		return nil, err
+	}
+
+	app.contexts[hash.String()] = createContext(base, map[string]map[string][]byte{}, nil, createdOn)
+	app.bus.emit(createEventWithContext(EventContextBegun, *hash))
 	return hash, nil
 }
 
 // Insert inserts a resource to a context
 func (app *application) Insert(ctx hash.Hash, namespace string, resource hash.Hash, value []byte) error {
-	resCommit := ctx.String()
-	if _, ok := app.queue[resCommit]; !ok {
-		str := fmt.Sprintf("the commit (hash: %s) does not exists", resCommit)
-		return errors.New(str)
+	if value == nil {
+		return errors.New("the value is mandatory in order to insert a resource")
+	}
+
+	return app.write(ctx, namespace, resource, value)
+}
+
+// Delete deletes a resource from a context
+func (app *application) Delete(ctx hash.Hash, namespace string, resource hash.Hash) error {
+	return app.write(ctx, namespace, resource, nil)
+}
+
+func (app *application) write(ctx hash.Hash, namespace string, resource hash.Hash, value []byte) error {
+	err := app.recover()
+	if err != nil {
+		return err
+	}
+
+	if value != nil {
+		err := app.validate(namespace, resource, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ins, ok := app.fetchContext(ctx); ok {
+		ins.mutex.Lock()
+		defer ins.mutex.Unlock()
+		if !ins.isClosed {
+			err := app.contextService.Write(ctx, namespace, resource, value)
+			if err != nil {
+				return err
+			}
+
+			ins.write(namespace, resource, value)
+			if value == nil {
+				app.bus.emit(createEventWithResource(EventValueDeleted, ctx, namespace, resource))
+				return nil
+			}
+
+			app.bus.emit(createEventWithResource(EventValueInserted, ctx, namespace, resource))
+			return nil
+		}
+	}
+
+	return &failures.ContextNotFoundError{
+		Context: ctx,
+	}
+}
+
+// Get returns the value of a resource as seen from a context, first from its queue then from its base state
+func (app *application) Get(ctx hash.Hash, namespace string, resource hash.Hash) ([]byte, error) {
+	err := app.recover()
+	if err != nil {
+		return nil, err
+	}
+
+	if ins, ok := app.fetchContext(ctx); ok {
+		ins.mutex.Lock()
+		defer ins.mutex.Unlock()
+		if !ins.isClosed {
+			if value, ok := ins.fetch(namespace, resource); ok {
+				if value == nil {
+					return nil, &failures.ResourceNotFoundError{
+						Namespace: namespace,
+						Resource:  resource,
+						IsDeleted: true,
+					}
+				}
+
+				return value, nil
+			}
+
+			// keep track of the read so that the push conflicts if a later state changes it:
+			err := app.read(ctx, ins, namespace, resource)
+			if err != nil {
+				return nil, err
+			}
+
+			return app.fetchFromBase(ins.base, namespace, resource)
+		}
+	}
+
+	return nil, &failures.ContextNotFoundError{
+		Context: ctx,
+	}
+}
+
+// Read records a resource read outside of a context, from its base state, so that the push conflicts if a later state changes it
+func (app *application) Read(ctx hash.Hash, namespace string, resource hash.Hash) error {
+	err := app.recover()
+	if err != nil {
+		return err
+	}
+
+	if ins, ok := app.fetchContext(ctx); ok {
+		ins.mutex.Lock()
+		defer ins.mutex.Unlock()
+		if !ins.isClosed {
+			return app.read(ctx, ins, namespace, resource)
+		}
+	}
+
+	return &failures.ContextNotFoundError{
+		Context: ctx,
+	}
+}
+
+func (app *application) read(ctx hash.Hash, ins *context, namespace string, resource hash.Hash) error {
+	if ins.hasRead(namespace, resource) {
+		return nil
 	}
 
-	if _, ok := app.queue[resCommit][namespace]; !ok {
-		app.queue[resCommit][namespace] = map[string][]byte{}
+	err := app.contextService.Read(ctx, namespace, resource)
+	if err != nil {
+		return err
 	}
 
-	app.queue[resCommit][namespace][resource.String()] = value
+	ins.read(namespace, resource)
 	return nil
 }
 
+func (app *application) fetchFromBase(base hash.Hash, namespace string, resource hash.Hash) ([]byte, error) {
+	if len(base) <= 0 {
+		return nil, &failures.ResourceNotFoundError{
+			Namespace: namespace,
+			Resource:  resource,
+		}
+	}
+
+	head, _, err := app.stateRepository.Retrieve()
+	if err != nil {
+		return nil, err
+	}
+
+	if head == nil {
+		return nil, &failures.StateNotFoundError{
+			State: base,
+		}
+	}
+
+	state, err := head.Fetch(base)
+	if err != nil {
+		return nil, err
+	}
+
+	ptr, err := state.Pointer(namespace, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := app.resRepository.Retrieve(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Value(), nil
+}
+
+// Savepoint creates a savepoint in a context
+func (app *application) Savepoint(ctx hash.Hash) (*hash.Hash, error) {
+	err := app.recover()
+	if err != nil {
+		return nil, err
+	}
+
+	if ins, ok := app.fetchContext(ctx); ok {
+		ins.mutex.Lock()
+		defer ins.mutex.Unlock()
+		if !ins.isClosed {
+			// the savepoints are journaled, so their hash must not collide with the ones created before a restart:
+			app.mutex.Lock()
+			app.counter++
+			str := fmt.Sprintf("%s-savepoint-%d-%d", ctx.String(), app.counter, time.Now().UTC().UnixNano())
+			app.mutex.Unlock()
+
+			hash, err := app.hashAdapter.FromBytes([]byte(str))
+			if err != nil {
+				return nil, err
+			}
+
+			err = app.contextService.Savepoint(ctx, *hash)
+			if err != nil {
+				return nil, err
+			}
+
+			ins.savepoints = append(ins.savepoints, createSavepoint(*hash))
+			return hash, nil
+		}
+	}
+
+	return nil, &failures.ContextNotFoundError{
+		Context: ctx,
+	}
+}
+
+// RollbackTo discards the values written to a context since a savepoint, the savepoint itself remains
+func (app *application) RollbackTo(ctx hash.Hash, savepoint hash.Hash) error {
+	return app.onSavepoint(ctx, savepoint, func(ins *context, index int) error {
+		for i := len(ins.savepoints) - 1; i >= index; i-- {
+			for namespace, resources := range ins.savepoints[i].undo {
+				for _, onePrior := range resources {
+					if !onePrior.exists {
+						err := app.contextService.Remove(ctx, namespace, onePrior.resource)
+						if err != nil {
+							return err
+						}
+
+						ins.unset(namespace, onePrior.resource)
+						continue
+					}
+
+					err := app.contextService.Write(ctx, namespace, onePrior.resource, onePrior.value)
+					if err != nil {
+						return err
+					}
+
+					ins.set(namespace, onePrior.resource, onePrior.value)
+				}
+			}
+		}
+
+		err := app.contextService.RollbackTo(ctx, savepoint)
+		if err != nil {
+			return err
+		}
+
+		ins.savepoints = append(ins.savepoints[:index], createSavepoint(savepoint))
+		return nil
+	})
+}
+
+// ReleaseSavepoint releases a savepoint and the ones created after it, keeping their values
+func (app *application) ReleaseSavepoint(ctx hash.Hash, savepoint hash.Hash) error {
+	return app.onSavepoint(ctx, savepoint, func(ins *context, index int) error {
+		err := app.contextService.Release(ctx, savepoint)
+		if err != nil {
+			return err
+		}
+
+		if index > 0 {
+			for _, oneSavepoint := range ins.savepoints[index:] {
+				ins.savepoints[index-1].merge(oneSavepoint)
+			}
+		}
+
+		ins.savepoints = ins.savepoints[:index]
+		return nil
+	})
+}
+
+func (app *application) onSavepoint(ctx hash.Hash, savepoint hash.Hash, fn func(ins *context, index int) error) error {
+	err := app.recover()
+	if err != nil {
+		return err
+	}
+
+	if ins, ok := app.fetchContext(ctx); ok {
+		ins.mutex.Lock()
+		defer ins.mutex.Unlock()
+		if !ins.isClosed {
+			for index, oneSavepoint := range ins.savepoints {
+				if oneSavepoint.hash.Compare(savepoint) {
+					return fn(ins, index)
+				}
+			}
+
+			str := fmt.Sprintf("the savepoint (hash: %s) does not exists in the context (hash: %s)", savepoint.String(), ctx.String())
+			return errors.New(str)
+		}
+	}
+
+	return &failures.ContextNotFoundError{
+		Context: ctx,
+	}
+}
+
 // Commit commits a context
 func (app *application) Commit(ctx hash.Hash) error {
+	return app.commit(ctx, nil)
+}
+
+// CommitWithMetadata commits a context along with its author, message and labels
+func (app *application) CommitWithMetadata(ctx hash.Hash, metadata commits.Metadata) error {
+	if metadata == nil {
+		return errors.New("the metadata is mandatory in order to commit a context with metadata")
+	}
+
+	return app.commit(ctx, metadata)
+}
+
+func (app *application) commit(ctx hash.Hash, metadata commits.Metadata) error {
+	err := app.recover()
+	if err != nil {
+		return err
+	}
+
 	resCommit := ctx.String()
-	if values, ok := app.queue[resCommit]; ok {
+	if ins, ok := app.fetchContext(ctx); ok {
+		// lock the context during the whole commit so that no value can be added to it meanwhile:
+		ins.mutex.Lock()
+		defer ins.mutex.Unlock()
+		if ins.isClosed {
+			return &failures.ContextNotFoundError{
+				Context: ctx,
+			}
+		}
+
+		// validate the queue again, since a schema could have been registered after the values were inserted:
+		err := app.validateAll(ins.values)
+		if err != nil {
+			return err
+		}
+
 		createdOn := time.Now().UTC()
-		commitIns, err := app.commitBuilder.Create().WithValues(values).CreatedOn(createdOn).Now()
+		builder := app.commitBuilder.Create().WithValues(ins.values).WithReads(ins.readList()).CreatedOn(createdOn)
+		if len(ins.base) > 0 {
+			builder.WithBase(ins.base)
+		}
+
+		if metadata != nil {
+			builder.WithMetadata(metadata)
+		}
+
+		commitIns, err := builder.Now()
 		if err != nil {
 			return err
 		}
 
 		err = app.commitService.Insert(
 			commitIns,
-			func(ctx commits.Commit) error {
-				delete(app.queue, resCommit)
+			func(commit commits.Commit) error {
+				err := app.contextService.Commit(ctx, commit.Hash())
+				if err != nil {
+					return err
+				}
+
+				ins.isClosed = true
+				app.mutex.Lock()
+				defer app.mutex.Unlock()
+				delete(app.contexts, resCommit)
 				app.commits[resCommit] = commitIns.Hash()
+				app.bus.emit(createEvent(EventCommitStored, []hash.Hash{ctx}, []hash.Hash{commit.Hash()}))
 				return nil
 			},
 			func(ctx commits.Commit, err error) error {
@@ -101,23 +501,36 @@ func (app *application) Commit(ctx hash.Hash) error {
 		return nil
 	}
 
-	str := fmt.Sprintf("the commit (hash: %s) does not exists", resCommit)
-	return errors.New(str)
+	return &failures.ContextNotFoundError{
+		Context: ctx,
+	}
 }
 
 // Queue returns the queue
 func (app *application) Queue(ctx hash.Hash) (map[string]map[string][]byte, error) {
-	resCommit := ctx.String()
-	if values, ok := app.queue[resCommit]; ok {
-		return values, nil
+	err := app.recover()
+	if err != nil {
+		return nil, err
 	}
 
-	str := fmt.Sprintf("the commit (hash: %s) does not exists", resCommit)
-	return nil, errors.New(str)
+	if ins, ok := app.fetchContext(ctx); ok {
+		if values, ok := ins.queue(); ok {
+			return values, nil
+		}
+	}
+
+	return nil, &failures.ContextNotFoundError{
+		Context: ctx,
+	}
 }
 
 // RollBack rollbacks a commit
 func (app *application) RollBack(commit hash.Hash) error {
+	err := app.recover()
+	if err != nil {
+		return err
+	}
+
 	retCtx, err := app.commitRepository.Retrieve(commit)
 	if err != nil {
 		return err
@@ -126,50 +539,761 @@ func (app *application) RollBack(commit hash.Hash) error {
 	return app.commitService.Delete(
 		retCtx,
 		func(ctx commits.Commit) error {
-			delete(app.commits, commit.String())
+			contexts := app.forget(commit)
+			app.bus.emit(createEvent(EventCommitRolledBack, contexts, []hash.Hash{commit}))
 			log.Printf("the rollback was successfully executed on commit (hash: %s)", ctx.Hash().String())
 			return nil
 		},
 		func(ctx commits.Commit, err error) error {
-			delete(app.commits, commit.String())
+			app.forget(commit)
 			log.Printf("the rollback failed on commit (hash: %s): %s", ctx.Hash().String(), err.Error())
 			return nil
 		},
 	)
 }
 
+// Contexts returns the contexts that are either pending or committed but not yet pushed
+func (app *application) Contexts() ([]hash.Hash, error) {
+	err := app.recover()
+	if err != nil {
+		return nil, err
+	}
+
+	return app.contextRepository.List()
+}
+
 // Push pushes a commit to the database
 func (app *application) Push(ctx hash.Hash) error {
+	return app.push(ctx, app.stateService.Insert)
+}
+
+// PushOnto pushes a commit to the database only if its head state is the expected one, an empty hash expecting an empty database
+func (app *application) PushOnto(ctx hash.Hash, expectedHead hash.Hash) error {
+	return app.push(ctx, func(commit commits.Commit, worked states.SuccessCallBackFn, failed states.FailCallBackFn) error {
+		return app.stateService.InsertOnto(commit, expectedHead, worked, failed)
+	})
+}
+
+func (app *application) push(ctx hash.Hash, insert func(commit commits.Commit, worked states.SuccessCallBackFn, failed states.FailCallBackFn) error) error {
+	err := app.recover()
+	if err != nil {
+		return err
+	}
+
 	keyname := ctx.String()
-	if ctxHash, ok := app.commits[keyname]; ok {
+	if ctxHash, ok := app.claim(keyname); ok {
 		retCtx, err := app.commitRepository.Retrieve(ctxHash)
 		if err != nil {
+			app.release(keyname, ctxHash)
+			app.bus.emit(createEventWithFailure(EventPushFailed, []hash.Hash{ctx}, []hash.Hash{ctxHash}, err))
 			return err
 		}
 
-		return app.stateService.Insert(
+		var state hash.Hash
+		err = insert(
 			retCtx,
-			func(workedCtx commits.Commit) error {
-				delete(app.commits, ctx.String())
-				return app.commitService.Delete(
-					workedCtx,
-					func(ctx commits.Commit) error {
-						log.Printf("the delete commit (hash: %s) was successful after creating a new state", ctx.Hash().String())
-						return nil
-					},
-					func(ctx commits.Commit, err error) error {
-						log.Printf("the rollback failed on commit (hash: %s): %s", ctx.Hash().String(), err.Error())
-						return nil
-					},
-				)
+			func(workedCtx commits.Commit, workedState states.State) error {
+				state = workedState.Hash()
+				return app.pushed(ctx, workedCtx)
 			},
 			func(failedCtx commits.Commit, err error) error {
+				app.release(keyname, ctxHash)
 				log.Printf("the state from commit (hash: %s) was expected to be successful but failed: %s", failedCtx.Hash().String(), err.Error())
-				return nil
+				return err
 			},
 		)
+
+		if err != nil {
+			app.bus.emit(createEventWithFailure(EventPushFailed, []hash.Hash{ctx}, []hash.Hash{ctxHash}, err))
+			return err
+		}
+
+		app.bus.emit(createEventWithState(EventStatePushed, []hash.Hash{ctx}, []hash.Hash{ctxHash}, state))
+		return nil
+	}
+
+	return &failures.CommitNotFoundError{
+		Context: ctx,
+	}
+}
+
+// Revert pushes a new state that undoes the pointers of a past state, restoring the previous value of each of its resources or deleting the ones it introduced
+func (app *application) Revert(state hash.Hash) error {
+	return app.revert(state, nil)
+}
+
+// RevertWithMetadata reverts a past state along with the author, message and labels of the revert
+func (app *application) RevertWithMetadata(state hash.Hash, metadata commits.Metadata) error {
+	if metadata == nil {
+		return errors.New("the metadata is mandatory in order to revert a state with metadata")
+	}
+
+	return app.revert(state, metadata)
+}
+
+func (app *application) revert(stateHash hash.Hash, metadata commits.Metadata) error {
+	err := app.recover()
+	if err != nil {
+		return err
+	}
+
+	head, _, err := app.stateRepository.Retrieve()
+	if err != nil {
+		return err
+	}
+
+	if head == nil {
+		return &failures.StateNotFoundError{
+			State: stateHash,
+		}
+	}
+
+	state, err := head.Fetch(stateHash)
+	if err != nil {
+		return err
+	}
+
+	// the pointers of a compacted root cumulate the dropped states, whose values cannot be restored:
+	if state.IsCompacted() && !state.HasPrevious() {
+		return &failures.CompactedRevertError{
+			State: stateHash,
+		}
 	}
 
-	str := fmt.Sprintf("the commit (hash: %s) does not point to a valid commit", keyname)
-	return errors.New(str)
+	values := map[string]map[string][]byte{}
+	for _, onePointer := range state.Pointers().List() {
+		namespace := onePointer.Namespace()
+		resource := onePointer.Resource()
+		value, err := app.previousValue(state, namespace, resource)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := values[namespace]; !ok {
+			values[namespace] = map[string][]byte{}
+		}
+
+		values[namespace][resource.String()] = value
+	}
+
+	// the reverted state is the base of the commit, so that the revert conflicts with any later state changing the same resources:
+	builder := app.commitBuilder.Create().WithValues(values).WithBase(stateHash).CreatedOn(time.Now().UTC())
+	if metadata != nil {
+		builder.WithMetadata(metadata)
+	}
+
+	commit, err := builder.Now()
+	if err != nil {
+		return err
+	}
+
+	var pushed hash.Hash
+	err = app.stateService.Insert(
+		commit,
+		func(workedCommit commits.Commit, workedState states.State) error {
+			pushed = workedState.Hash()
+			return nil
+		},
+		func(failedCommit commits.Commit, err error) error {
+			log.Printf("the revert of the state (hash: %s) failed: %s", stateHash.String(), err.Error())
+			return err
+		},
+	)
+
+	if err != nil {
+		app.bus.emit(createEventWithFailure(EventPushFailed, []hash.Hash{}, []hash.Hash{commit.Hash()}, err))
+		return err
+	}
+
+	app.bus.emit(createEventWithState(EventStatePushed, []hash.Hash{}, []hash.Hash{commit.Hash()}, pushed))
+	return nil
+}
+
+// previousValue returns the value of a resource before a state, nil if it did not exist or was deleted
+func (app *application) previousValue(state states.State, namespace string, resource hash.Hash) ([]byte, error) {
+	if !state.HasPrevious() {
+		return nil, nil
+	}
+
+	previous, err := state.Previous()
+	if err != nil {
+		return nil, err
+	}
+
+	ptr, err := previous.Pointer(namespace, resource)
+	if errors.Is(err, failures.ErrResourceNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := app.resRepository.Retrieve(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Value(), nil
+}
+
+// PushAll pushes the commits of multiple contexts to the database as a single state, atomically
+func (app *application) PushAll(list []hash.Hash) error {
+	err := app.recover()
+	if err != nil {
+		return err
+	}
+
+	claimed := map[string]hash.Hash{}
+	releaseAll := func() {
+		for keyname, commit := range claimed {
+			app.release(keyname, commit)
+		}
+	}
+
+	commitHashes := []hash.Hash{}
+	commitsList := []commits.Commit{}
+	for _, oneCtx := range list {
+		keyname := oneCtx.String()
+		ctxHash, ok := app.claim(keyname)
+		if !ok {
+			releaseAll()
+			return &failures.CommitNotFoundError{
+				Context: oneCtx,
+			}
+		}
+
+		claimed[keyname] = ctxHash
+		commitHashes = append(commitHashes, ctxHash)
+		retCtx, err := app.commitRepository.Retrieve(ctxHash)
+		if err != nil {
+			releaseAll()
+			app.bus.emit(createEventWithFailure(EventPushFailed, list, commitHashes, err))
+			return err
+		}
+
+		commitsList = append(commitsList, retCtx)
+	}
+
+	var state hash.Hash
+	err = app.stateService.InsertAll(
+		commitsList,
+		func(workedList []commits.Commit, workedState states.State) error {
+			state = workedState.Hash()
+			for index, oneCommit := range workedList {
+				err := app.pushed(list[index], oneCommit)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		func(failedList []commits.Commit, err error) error {
+			releaseAll()
+			log.Printf("the state from %d commits was expected to be successful but failed: %s", len(failedList), err.Error())
+			return err
+		},
+	)
+
+	if err != nil {
+		app.bus.emit(createEventWithFailure(EventPushFailed, list, commitHashes, err))
+		return err
+	}
+
+	app.bus.emit(createEventWithState(EventStatePushed, list, commitHashes, state))
+	return nil
+}
+
+// pushed cleans up the journal and the commit of a context once its state has been created
+func (app *application) pushed(ctx hash.Hash, commit commits.Commit) error {
+	app.mutex.Lock()
+	delete(app.pushing, commit.Hash().String())
+	app.mutex.Unlock()
+
+	err := app.contextService.Delete(ctx)
+	if err != nil {
+		log.Printf("the journal of the context (hash: %s) could not be deleted after creating a new state: %s", ctx.String(), err.Error())
+	}
+
+	return app.commitService.Delete(
+		commit,
+		func(ctx commits.Commit) error {
+			log.Printf("the delete commit (hash: %s) was successful after creating a new state", ctx.Hash().String())
+			return nil
+		},
+		func(ctx commits.Commit, err error) error {
+			log.Printf("the rollback failed on commit (hash: %s): %s", ctx.Hash().String(), err.Error())
+			return nil
+		},
+	)
+}
+
+func (app *application) fetchContext(ctx hash.Hash) (*context, bool) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+	ins, ok := app.contexts[ctx.String()]
+	return ins, ok
+}
+
+// claim removes the commit of a context so that it can only be pushed once at a time
+func (app *application) claim(keyname string) (hash.Hash, bool) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	commit, ok := app.commits[keyname]
+	if ok {
+		delete(app.commits, keyname)
+		app.pushing[commit.String()] = true
+	}
+
+	return commit, ok
+}
+
+// release gives back the commit of a context after a failed push
+func (app *application) release(keyname string, commit hash.Hash) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.commits[keyname] = commit
+	delete(app.pushing, commit.String())
+}
+
+func (app *application) forget(commit hash.Hash) []hash.Hash {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	out := []hash.Hash{}
+	for keyname, oneCommit := range app.commits {
+		if !oneCommit.Compare(commit) {
+			continue
+		}
+
+		delete(app.commits, keyname)
+		ctx, err := app.hashAdapter.FromString(keyname)
+		if err != nil {
+			log.Printf("the context (hash: %s) could not be parsed: %s", keyname, err.Error())
+			continue
+		}
+
+		out = append(out, *ctx)
+		err = app.contextService.Delete(*ctx)
+		if err != nil {
+			log.Printf("the journal of the context (hash: %s) could not be deleted after its rollback: %s", keyname, err.Error())
+		}
+	}
+
+	return out
+}
+
+// Stale returns the open contexts and the unpushed commits that outlived their time-to-live
+func (app *application) Stale() ([]Stale, error) {
+	err := app.recover()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	out := []Stale{}
+	if app.contextTTL > 0 {
+		app.mutex.RLock()
+		for keyname, oneContext := range app.contexts {
+			age := now.Sub(oneContext.createdOn)
+			if age <= app.contextTTL {
+				continue
+			}
+
+			ctx, err := app.hashAdapter.FromString(keyname)
+			if err != nil {
+				app.mutex.RUnlock()
+				return nil, err
+			}
+
+			out = append(out, createStaleWithContext(*ctx, age))
+		}
+
+		app.mutex.RUnlock()
+	}
+
+	if app.commitTTL > 0 {
+		list, err := app.commitRepository.List()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, oneHash := range list {
+			if app.isPushing(oneHash) {
+				continue
+			}
+
+			commit, err := app.commitRepository.Retrieve(oneHash)
+			if err != nil {
+				return nil, err
+			}
+
+			age := now.Sub(commit.CreatedOn())
+			if age <= app.commitTTL {
+				continue
+			}
+
+			out = append(out, createStaleWithCommit(oneHash, age))
+		}
+	}
+
+	return out, nil
+}
+
+// Reap rolls back the stale contexts and commits
+func (app *application) Reap() error {
+	list, err := app.Stale()
+	if err != nil {
+		return err
+	}
+
+	for _, oneStale := range list {
+		if oneStale.IsCommit() {
+			err := app.reap(oneStale.Hash())
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err := app.abandon(oneStale.Hash())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reap rolls back a stale commit, unless a push claimed it since it was listed
+func (app *application) reap(commit hash.Hash) error {
+	keynames, ok := app.claimStale(commit)
+	if !ok {
+		return nil
+	}
+
+	retCommit, err := app.commitRepository.Retrieve(commit)
+	if err != nil {
+		app.restore(keynames, commit)
+		return err
+	}
+
+	return app.commitService.Delete(
+		retCommit,
+		func(ctx commits.Commit) error {
+			contexts := app.reaped(keynames, commit)
+			app.bus.emit(createEvent(EventCommitRolledBack, contexts, []hash.Hash{commit}))
+			log.Printf("the stale commit (hash: %s) was rolled back", commit.String())
+			return nil
+		},
+		func(ctx commits.Commit, err error) error {
+			app.restore(keynames, commit)
+			log.Printf("the rollback failed on stale commit (hash: %s): %s", commit.String(), err.Error())
+			return err
+		},
+	)
+}
+
+// claimStale removes a commit from its contexts and flags it as claimed, in the same critical section as the check that no push
+// claimed it, returning false if a push did
+func (app *application) claimStale(commit hash.Hash) ([]string, bool) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if _, ok := app.pushing[commit.String()]; ok {
+		return nil, false
+	}
+
+	keynames := []string{}
+	for keyname, oneCommit := range app.commits {
+		if !oneCommit.Compare(commit) {
+			continue
+		}
+
+		delete(app.commits, keyname)
+		keynames = append(keynames, keyname)
+	}
+
+	app.pushing[commit.String()] = true
+	return keynames, true
+}
+
+// restore gives back a commit claimed by the reaper to its contexts
+func (app *application) restore(keynames []string, commit hash.Hash) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	for _, keyname := range keynames {
+		app.commits[keyname] = commit
+	}
+
+	delete(app.pushing, commit.String())
+}
+
+// reaped deletes the journals of the contexts of a reaped commit, returning the contexts
+func (app *application) reaped(keynames []string, commit hash.Hash) []hash.Hash {
+	out := []hash.Hash{}
+	for _, keyname := range keynames {
+		ctx, err := app.hashAdapter.FromString(keyname)
+		if err != nil {
+			log.Printf("the context (hash: %s) could not be parsed: %s", keyname, err.Error())
+			continue
+		}
+
+		out = append(out, *ctx)
+		err = app.contextService.Delete(*ctx)
+		if err != nil {
+			log.Printf("the journal of the context (hash: %s) could not be deleted after its rollback: %s", keyname, err.Error())
+		}
+	}
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	delete(app.pushing, commit.String())
+	return out
+}
+
+// StartReaper starts reaping the stale contexts and commits in the background, at every interval
+func (app *application) StartReaper(interval time.Duration) error {
+	if interval <= 0 {
+		str := fmt.Sprintf("the reaper interval (%s) must be greater than zero (0)", interval.String())
+		return errors.New(str)
+	}
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if app.reaper != nil {
+		return errors.New("the reaper is already started")
+	}
+
+	stop := make(chan struct{})
+	app.reaper = stop
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				err := app.Reap()
+				if err != nil {
+					log.Printf("the reaper failed: %s", err.Error())
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// StopReaper stops the background reaper
+func (app *application) StopReaper() error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if app.reaper == nil {
+		return errors.New("the reaper is not started")
+	}
+
+	close(app.reaper)
+	app.reaper = nil
+	return nil
+}
+
+// Subscribe registers an event handler and returns its subscription
+func (app *application) Subscribe(handler EventFn) uint {
+	return app.bus.subscribe(handler)
+}
+
+// Unsubscribe removes the event handler of a subscription
+func (app *application) Unsubscribe(subscription uint) error {
+	return app.bus.unsubscribe(subscription)
+}
+
+// RegisterSchema registers the schema the values of a namespace must respect, replacing its previous schema, if any
+func (app *application) RegisterSchema(namespace string, schema []byte) error {
+	if app.validator == nil {
+		return errors.New("the validator is mandatory in order to register a schema")
+	}
+
+	if len(schema) <= 0 {
+		return errors.New("the schema is mandatory in order to register a schema")
+	}
+
+	err := app.recover()
+	if err != nil {
+		return err
+	}
+
+	ins, err := app.schemaBuilder.Create().WithNamespace(namespace).WithData(schema).Now()
+	if err != nil {
+		return err
+	}
+
+	// the schema is persisted first, so that it is enforced again after a restart:
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	err = app.schemaService.Insert(ins)
+	if err != nil {
+		return err
+	}
+
+	app.schemas[namespace] = schema
+	return nil
+}
+
+// RemoveSchema removes the schema of a namespace
+func (app *application) RemoveSchema(namespace string) error {
+	err := app.recover()
+	if err != nil {
+		return err
+	}
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if _, ok := app.schemas[namespace]; !ok {
+		str := fmt.Sprintf("the namespace (name: %s) does not have a schema", namespace)
+		return errors.New(str)
+	}
+
+	err = app.schemaService.Delete(namespace)
+	if err != nil {
+		return err
+	}
+
+	delete(app.schemas, namespace)
+	return nil
+}
+
+// validate verifies a value against the schema of its namespace, if any
+func (app *application) validate(namespace string, resource hash.Hash, value []byte) error {
+	app.mutex.RLock()
+	schema, ok := app.schemas[namespace]
+	app.mutex.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	rule, err := app.validator.Validate(schema, value)
+	if err != nil {
+		return err
+	}
+
+	if rule != nil {
+		return &failures.ValidationError{
+			Namespace: namespace,
+			Resource:  resource,
+			Rule:      *rule,
+		}
+	}
+
+	return nil
+}
+
+func (app *application) validateAll(values map[string]map[string][]byte) error {
+	for namespace, resources := range values {
+		for keyname, value := range resources {
+			if value == nil {
+				continue
+			}
+
+			resource, err := app.hashAdapter.FromString(keyname)
+			if err != nil {
+				return err
+			}
+
+			err = app.validate(namespace, *resource, value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// abandon drops an open context and its journal
+func (app *application) abandon(ctx hash.Hash) error {
+	ins, ok := app.fetchContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	ins.mutex.Lock()
+	defer ins.mutex.Unlock()
+	if ins.isClosed {
+		return nil
+	}
+
+	err := app.contextService.Delete(ctx)
+	if err != nil {
+		return err
+	}
+
+	ins.isClosed = true
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	delete(app.contexts, ctx.String())
+	log.Printf("the stale context (hash: %s) was abandoned", ctx.String())
+	return nil
+}
+
+func (app *application) isPushing(commit hash.Hash) bool {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+	_, ok := app.pushing[commit.String()]
+	return ok
+}
+
+// recover loads the journaled contexts once, so that in-flight contexts survive a restart
+func (app *application) recover() error {
+	app.once.Do(func() {
+		app.recoverErr = app.load()
+	})
+
+	return app.recoverErr
+}
+
+func (app *application) load() error {
+	list, err := app.contextRepository.List()
+	if err != nil {
+		return err
+	}
+
+	schemaList, err := app.schemaRepository.List()
+	if err != nil {
+		return err
+	}
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	for _, oneSchema := range schemaList {
+		app.schemas[oneSchema.Namespace()] = oneSchema.Data()
+	}
+
+	for _, oneHash := range list {
+		journal, err := app.contextRepository.Retrieve(oneHash)
+		if err != nil {
+			return err
+		}
+
+		keyname := oneHash.String()
+		if journal.HasCommit() {
+			app.commits[keyname] = journal.Commit()
+			continue
+		}
+
+		ins := createContext(journal.Base(), journal.Values(), journal.Reads(), journal.CreatedOn())
+		for _, oneSavepoint := range journal.Savepoints() {
+			savepoint, err := createSavepointFromJournal(app.hashAdapter, oneSavepoint)
+			if err != nil {
+				return err
+			}
+
+			ins.savepoints = append(ins.savepoints, savepoint)
+		}
+
+		app.contexts[keyname] = ins
+	}
+
+	return nil
 }
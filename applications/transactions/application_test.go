@@ -0,0 +1,1680 @@
+package transactions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/contexts"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/schemas"
+	"github.com/steve-care-software/database/domain/states"
+	"github.com/steve-care-software/database/infrastructure/disks"
+)
+
+func newApplicationForTests(baseDir string) (Application, states.Repository) {
+	return newApplicationWithTTLForTests(baseDir, 0, 0)
+}
+
+func newApplicationWithTTLForTests(baseDir string, contextTTL time.Duration, commitTTL time.Duration) (Application, states.Repository) {
+	app, services := newApplicationWithServicesForTests(baseDir, contextTTL, commitTTL)
+	return app, services.StateRepository()
+}
+
+func newApplicationWithServicesForTests(baseDir string, contextTTL time.Duration, commitTTL time.Duration) (Application, disks.Services) {
+	application, err := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	if err != nil {
+		panic(err)
+	}
+
+	services, err := disks.NewBuilder(baseDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	commitRepository := services.CommitRepository()
+	commitService := services.CommitService()
+	contextRepository := services.ContextRepository()
+	contextService := services.ContextService()
+	resourceRepository := services.ResourceRepository()
+	stateRepository := services.StateRepository()
+	stateService := services.StateService()
+
+	return createApplication(
+		hash.NewAdapter(),
+		commits.NewBuilder(),
+		commitRepository,
+		commitService,
+		resourceRepository,
+		contexts.NewBuilder(),
+		contextRepository,
+		contextService,
+		stateRepository,
+		stateService,
+		contextTTL,
+		commitTTL,
+		&validatorForTests{},
+		schemas.NewBuilder(),
+		services.SchemaRepository(),
+		services.SchemaService(),
+	), services
+}
+
+// validatorForTests requires the values to start with the schema
+type validatorForTests struct {
+}
+
+// Validate returns the prefix rule if the value does not start with the schema
+func (app *validatorForTests) Validate(schema []byte, value []byte) (*string, error) {
+	if bytes.HasPrefix(value, schema) {
+		return nil, nil
+	}
+
+	rule := "prefix"
+	return &rule, nil
+}
+
+func newResourceForTests(prefix string, index int) hash.Hash {
+	resource, err := hash.NewAdapter().FromBytes([]byte(fmt.Sprintf("%s: %d", prefix, index)))
+	if err != nil {
+		panic(err)
+	}
+
+	return *resource
+}
+
+func TestApplication_withConcurrentContexts_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateRepository := newApplicationForTests(baseDir)
+
+	amount := 200
+	amountPushed := 0
+	amountValues := 5
+	pushedMutex := sync.Mutex{}
+	errs := make(chan error, amount)
+	wg := sync.WaitGroup{}
+	for i := 0; i < amount; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			ctx, err := app.Begin()
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			for j := 0; j < amountValues; j++ {
+				resource := newResourceForTests(fmt.Sprintf("context %d", index), j)
+				err := app.Insert(*ctx, "my_namespace", resource, []byte(fmt.Sprintf("value %d of context %d", j, index)))
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+
+			queue, err := app.Queue(*ctx)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			if len(queue["my_namespace"]) != amountValues {
+				errs <- fmt.Errorf("the queue was expected to contain %d values, %d returned", amountValues, len(queue["my_namespace"]))
+				return
+			}
+
+			err = app.Commit(*ctx)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			// only push half the contexts, the others are rolled back:
+			if index%2 != 0 {
+				casted := app.(*application)
+				casted.mutex.RLock()
+				commit := casted.commits[ctx.String()]
+				casted.mutex.RUnlock()
+
+				err = app.RollBack(commit)
+				if err != nil {
+					errs <- err
+				}
+
+				return
+			}
+
+			err = app.Push(*ctx)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			pushedMutex.Lock()
+			amountPushed++
+			pushedMutex.Unlock()
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	head, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if head.Height() != uint(amountPushed) {
+		t.Errorf("the head height was expected to be %d, %d returned", amountPushed, head.Height())
+		return
+	}
+
+	for i := 0; i < amount; i += 2 {
+		for j := 0; j < amountValues; j++ {
+			_, err := head.Pointer("my_namespace", newResourceForTests(fmt.Sprintf("context %d", i), j))
+			if err != nil {
+				t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+				return
+			}
+		}
+	}
+}
+
+func TestApplication_withConcurrentInsertsOnSameContext_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, _ := newApplicationForTests(baseDir)
+	ctx, err := app.Begin()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	amount := 500
+	wg := sync.WaitGroup{}
+	for i := 0; i < amount; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			resource := newResourceForTests("resource", index)
+			err := app.Insert(*ctx, "my_namespace", resource, []byte(fmt.Sprintf("value %d", index)))
+			if err != nil {
+				t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			}
+
+			_, err = app.Queue(*ctx)
+			if err != nil {
+				t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	queue, err := app.Queue(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(queue["my_namespace"]) != amount {
+		t.Errorf("the queue was expected to contain %d values, %d returned", amount, len(queue["my_namespace"]))
+		return
+	}
+}
+
+func TestApplication_withConcurrentPushesOfSameContext_pushesOnce(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateRepository := newApplicationForTests(baseDir)
+	ctx, err := app.Begin()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.Insert(*ctx, "my_namespace", newResourceForTests("resource", 0), []byte("some value"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.Commit(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	amount := 50
+	amountPushed := 0
+	pushedMutex := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for i := 0; i < amount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := app.Push(*ctx)
+			if err != nil {
+				return
+			}
+
+			pushedMutex.Lock()
+			amountPushed++
+			pushedMutex.Unlock()
+		}()
+	}
+
+	wg.Wait()
+	if amountPushed != 1 {
+		t.Errorf("the context was expected to be pushed %d time, %d returned", 1, amountPushed)
+		return
+	}
+
+	head, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if head.Height() != 1 {
+		t.Errorf("the head height was expected to be %d, %d returned", 1, head.Height())
+		return
+	}
+}
+
+func TestApplication_afterRestart_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, _ := newApplicationForTests(baseDir)
+	firstCtx, err := app.Begin()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	secondCtx, err := app.Begin()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	deleted := newResourceForTests("deleted", 0)
+	err = app.Insert(*firstCtx, "my_namespace", newResourceForTests("first", 0), []byte("first value"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.Delete(*firstCtx, "my_namespace", deleted)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.Insert(*secondCtx, "my_namespace", newResourceForTests("second", 0), []byte("second value"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.Commit(*secondCtx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// reopen the application:
+	app, stateRepository := newApplicationForTests(baseDir)
+	list, err := app.Contexts()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 2 {
+		t.Errorf("%d contexts were expected to be recoverable, %d returned", 2, len(list))
+		return
+	}
+
+	queue, err := app.Queue(*firstCtx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(queue["my_namespace"]) != 2 {
+		t.Errorf("the queue was expected to contain %d values, %d returned", 2, len(queue["my_namespace"]))
+		return
+	}
+
+	if value, ok := queue["my_namespace"][deleted.String()]; !ok || value != nil {
+		t.Errorf("the deleted resource was expected to be recovered as a deletion")
+		return
+	}
+
+	err = app.Push(*secondCtx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.Commit(*firstCtx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// reopen the application again:
+	app, _ = newApplicationForTests(baseDir)
+	err = app.Push(*firstCtx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	list, err = app.Contexts()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 0 {
+		t.Errorf("%d contexts were expected to be recoverable, %d returned", 0, len(list))
+		return
+	}
+
+	head, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if head.Height() != 2 {
+		t.Errorf("the head height was expected to be %d, %d returned", 2, head.Height())
+		return
+	}
+}
+
+func TestApplication_Get_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, _ := newApplicationForTests(baseDir)
+	first := newResourceForTests("resource", 0)
+	second := newResourceForTests("resource", 1)
+	ctx, err := app.Begin()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	_, err = app.Get(*ctx, "my_namespace", first)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	app.Insert(*ctx, "my_namespace", first, []byte("first value"))
+	app.Insert(*ctx, "my_namespace", second, []byte("second value"))
+	value, err := app.Get(*ctx, "my_namespace", first)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if string(value) != "first value" {
+		t.Errorf("the value was expected to be %s, %s returned", "first value", value)
+		return
+	}
+
+	app.Commit(*ctx)
+	err = app.Push(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	ctx, err = app.Begin()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// read from the base state, then modify and read again:
+	value, err = app.Get(*ctx, "my_namespace", first)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if string(value) != "first value" {
+		t.Errorf("the value was expected to be %s, %s returned", "first value", value)
+		return
+	}
+
+	app.Insert(*ctx, "my_namespace", first, append(value, []byte(", updated")...))
+	value, err = app.Get(*ctx, "my_namespace", first)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if string(value) != "first value, updated" {
+		t.Errorf("the value was expected to be %s, %s returned", "first value, updated", value)
+		return
+	}
+
+	app.Delete(*ctx, "my_namespace", second)
+	_, err = app.Get(*ctx, "my_namespace", second)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	var notFound *failures.ResourceNotFoundError
+	if !errors.As(err, &notFound) || !notFound.IsDeleted {
+		t.Errorf("the error was expected to be a deleted resource error, %s returned", err.Error())
+		return
+	}
+
+	app.Commit(*ctx)
+	err = app.Push(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	ctx, err = app.Begin()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	_, err = app.Get(*ctx, "my_namespace", second)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	if !errors.Is(err, failures.ErrResourceNotFound) {
+		t.Errorf("the error was expected to be a resource not found error, %s returned", err.Error())
+		return
+	}
+}
+
+func TestApplication_Get_withConflictingRead_returnsError(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, _ := newApplicationForTests(baseDir)
+	read := newResourceForTests("read", 0)
+	written := newResourceForTests("written", 0)
+	ctx, _ := app.Begin()
+	app.Insert(*ctx, "my_namespace", read, []byte("original value"))
+	app.Commit(*ctx)
+	err := app.Push(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	reader, _ := app.Begin()
+	writer, _ := app.Begin()
+	_, err = app.Get(*reader, "my_namespace", read)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	app.Insert(*reader, "my_namespace", written, []byte("derived value"))
+	app.Insert(*writer, "my_namespace", read, []byte("updated value"))
+	app.Commit(*writer)
+	err = app.Push(*writer)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	app.Commit(*reader)
+	err = app.Push(*reader)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	var conflict *states.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Errorf("the error was expected to be a conflict, %s returned", err.Error())
+		return
+	}
+}
+
+func TestApplication_Read_withConflictingRead_returnsError(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, _ := newApplicationForTests(baseDir)
+	ctx, _ := app.Begin()
+	for i := 0; i < 5; i++ {
+		app.Insert(*ctx, "my_namespace", newResourceForTests("read", i), []byte("original value"))
+	}
+
+	app.Commit(*ctx)
+	err := app.Push(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// the reader reads its values outside of the context, then declares its reads:
+	reader, _ := app.Begin()
+	writer, _ := app.Begin()
+	for i := 0; i < 5; i++ {
+		err = app.Read(*reader, "my_namespace", newResourceForTests("read", i))
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+	}
+
+	app.Insert(*reader, "my_namespace", newResourceForTests("written", 0), []byte("derived value"))
+	app.Insert(*writer, "my_namespace", newResourceForTests("read", 3), []byte("updated value"))
+	app.Commit(*writer)
+	err = app.Push(*writer)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	app.Commit(*reader)
+	err = app.Push(*reader)
+	if !errors.Is(err, failures.ErrConflict) {
+		t.Errorf("the error was expected to be a conflict")
+		return
+	}
+
+	err = app.Read(*writer, "my_namespace", newResourceForTests("read", 0))
+	if !errors.Is(err, failures.ErrContextNotFound) {
+		t.Errorf("the pushed context was not expected to be found")
+		return
+	}
+}
+
+func TestApplication_PushAll_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateRepository := newApplicationForTests(baseDir)
+	shared := newResourceForTests("shared", 0)
+	ctxs := []hash.Hash{}
+	for i := 0; i < 3; i++ {
+		ctx, err := app.Begin()
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+
+		app.Insert(*ctx, "my_namespace", newResourceForTests("resource", i), []byte(fmt.Sprintf("value %d", i)))
+		app.Insert(*ctx, "my_namespace", shared, []byte(fmt.Sprintf("shared value %d", i)))
+		err = app.Commit(*ctx)
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+
+		ctxs = append(ctxs, *ctx)
+	}
+
+	invalid := newResourceForTests("invalid context", 0)
+	err := app.PushAll(append(ctxs, invalid))
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	// push in reverse order, the latest commit must still win:
+	err = app.PushAll([]hash.Hash{
+		ctxs[2],
+		ctxs[1],
+		ctxs[0],
+	})
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	head, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if head.Height() != 1 {
+		t.Errorf("the head height was expected to be %d, %d returned", 1, head.Height())
+		return
+	}
+
+	if len(head.Pointers().List()) != 4 {
+		t.Errorf("the head was expected to contain %d pointers, %d returned", 4, len(head.Pointers().List()))
+		return
+	}
+
+	ctx, _ := app.Begin()
+	value, err := app.Get(*ctx, "my_namespace", shared)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if string(value) != "shared value 2" {
+		t.Errorf("the value was expected to be %s, %s returned", "shared value 2", value)
+		return
+	}
+
+	list, err := app.Contexts()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 1 {
+		t.Errorf("%d contexts were expected to be recoverable, %d returned", 1, len(list))
+		return
+	}
+}
+
+func TestApplication_Reap_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	ttl := 50 * time.Millisecond
+	app, _ := newApplicationWithTTLForTests(baseDir, ttl, ttl)
+	open, _ := app.Begin()
+	app.Insert(*open, "my_namespace", newResourceForTests("open", 0), []byte("open value"))
+	committed, _ := app.Begin()
+	app.Insert(*committed, "my_namespace", newResourceForTests("committed", 0), []byte("committed value"))
+	err := app.Commit(*committed)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	time.Sleep(ttl * 2)
+	fresh, _ := app.Begin()
+	list, err := app.Stale()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 2 {
+		t.Errorf("%d stale elements were expected, %d returned", 2, len(list))
+		return
+	}
+
+	for _, oneStale := range list {
+		if oneStale.Age() <= ttl {
+			t.Errorf("the stale element (hash: %s) was expected to be older than %s, %s returned", oneStale.Hash().String(), ttl, oneStale.Age())
+			return
+		}
+	}
+
+	err = app.Reap()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	contexts, err := app.Contexts()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(contexts) != 1 || !contexts[0].Compare(*fresh) {
+		t.Errorf("only the fresh context (hash: %s) was expected to remain", fresh.String())
+		return
+	}
+
+	err = app.Insert(*open, "my_namespace", newResourceForTests("open", 1), []byte("another value"))
+	if !errors.Is(err, failures.ErrContextNotFound) {
+		t.Errorf("the error was expected to be a context not found error, %v returned", err)
+		return
+	}
+
+	err = app.Push(*committed)
+	if !errors.Is(err, failures.ErrCommitNotFound) {
+		t.Errorf("the error was expected to be a commit not found error, %v returned", err)
+		return
+	}
+
+	err = app.StartReaper(0)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	// the reaper eventually abandons the fresh context as well:
+	err = app.StartReaper(10 * time.Millisecond)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	time.Sleep(ttl * 3)
+	err = app.StopReaper()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	contexts, err = app.Contexts()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(contexts) != 0 {
+		t.Errorf("%d contexts were expected to remain, %d returned", 0, len(contexts))
+		return
+	}
+}
+
+func TestApplication_Reap_withClaimedCommit_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	ttl := 10 * time.Millisecond
+	app, stateRepository := newApplicationWithTTLForTests(baseDir, 0, ttl)
+	ctx, _ := app.Begin()
+	app.Insert(*ctx, "my_namespace", newResourceForTests("committed", 0), []byte("committed value"))
+	app.Commit(*ctx)
+	time.Sleep(ttl * 2)
+	list, _ := app.Stale()
+	if len(list) != 1 {
+		t.Errorf("%d stale elements were expected, %d returned", 1, len(list))
+		return
+	}
+
+	// a push claims the commit after it was listed as stale:
+	ins := app.(*application)
+	commit, ok := ins.claim(ctx.String())
+	if !ok {
+		t.Errorf("the commit was expected to be claimed")
+		return
+	}
+
+	err := ins.reap(list[0].Hash())
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	_, err = ins.commitRepository.Retrieve(commit)
+	if err != nil {
+		t.Errorf("the claimed commit was not expected to be rolled back: %s", err.Error())
+		return
+	}
+
+	ins.release(ctx.String(), commit)
+	err = app.Push(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	head, _, _ := stateRepository.Retrieve()
+	if head == nil {
+		t.Errorf("the claimed commit was expected to be pushed")
+		return
+	}
+}
+
+func TestApplication_Savepoint_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, _ := newApplicationForTests(baseDir)
+	first := newResourceForTests("resource", 0)
+	second := newResourceForTests("resource", 1)
+	third := newResourceForTests("resource", 2)
+	ctx, _ := app.Begin()
+	app.Insert(*ctx, "my_namespace", first, []byte("first value"))
+	outer, err := app.Savepoint(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	app.Insert(*ctx, "my_namespace", first, []byte("first value, updated"))
+	app.Insert(*ctx, "my_namespace", second, []byte("second value"))
+	inner, err := app.Savepoint(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	app.Delete(*ctx, "my_namespace", second)
+	app.Insert(*ctx, "other_namespace", third, []byte("third value"))
+
+	// rollback the inner savepoint:
+	err = app.RollbackTo(*ctx, *inner)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	queue, _ := app.Queue(*ctx)
+	if _, ok := queue["other_namespace"]; ok {
+		t.Errorf("the other_namespace was expected to be rolled back")
+		return
+	}
+
+	if value := queue["my_namespace"][second.String()]; string(value) != "second value" {
+		t.Errorf("the value was expected to be %s, %s returned", "second value", value)
+		return
+	}
+
+	// the inner savepoint remains after a rollback, so it can be rolled back again:
+	app.Insert(*ctx, "my_namespace", second, []byte("second value, updated"))
+	err = app.RollbackTo(*ctx, *inner)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	value, _ := app.Get(*ctx, "my_namespace", second)
+	if string(value) != "second value" {
+		t.Errorf("the value was expected to be %s, %s returned", "second value", value)
+		return
+	}
+
+	// release the inner savepoint, then rollback the outer one:
+	err = app.ReleaseSavepoint(*ctx, *inner)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.RollbackTo(*ctx, *inner)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	err = app.RollbackTo(*ctx, *outer)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	queue, _ = app.Queue(*ctx)
+	if len(queue["my_namespace"]) != 1 {
+		t.Errorf("%d values were expected in the queue, %d returned", 1, len(queue["my_namespace"]))
+		return
+	}
+
+	if value := queue["my_namespace"][first.String()]; string(value) != "first value" {
+		t.Errorf("the value was expected to be %s, %s returned", "first value", value)
+		return
+	}
+
+	// the journal reflects the rollbacks after a restart:
+	app, _ = newApplicationForTests(baseDir)
+	queue, err = app.Queue(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(queue["my_namespace"]) != 1 || len(queue) != 1 {
+		t.Errorf("only the first value was expected in the recovered queue")
+		return
+	}
+}
+
+func TestApplication_Savepoint_afterRestart_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, _ := newApplicationForTests(baseDir)
+	first := newResourceForTests("resource", 0)
+	second := newResourceForTests("resource", 1)
+	ctx, _ := app.Begin()
+	app.Insert(*ctx, "my_namespace", first, []byte("first value"))
+	outer, _ := app.Savepoint(*ctx)
+	app.Insert(*ctx, "my_namespace", first, []byte("first value, updated"))
+	inner, _ := app.Savepoint(*ctx)
+	app.Insert(*ctx, "my_namespace", second, []byte("second value"))
+	released, _ := app.Savepoint(*ctx)
+	app.Delete(*ctx, "my_namespace", first)
+	err := app.ReleaseSavepoint(*ctx, *released)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// reopen the application, the savepoints are recovered from the journal:
+	app, _ = newApplicationForTests(baseDir)
+	err = app.RollbackTo(*ctx, *released)
+	if err == nil {
+		t.Errorf("the released savepoint was not expected to be recovered")
+		return
+	}
+
+	err = app.RollbackTo(*ctx, *inner)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	queue, _ := app.Queue(*ctx)
+	if _, ok := queue["my_namespace"][second.String()]; ok || len(queue["my_namespace"]) != 1 {
+		t.Errorf("the values written after the inner savepoint were expected to be rolled back")
+		return
+	}
+
+	if value := queue["my_namespace"][first.String()]; string(value) != "first value, updated" {
+		t.Errorf("the value was expected to be %s, %s returned", "first value, updated", value)
+		return
+	}
+
+	// reopen the application again, the rollback is recovered and the outer savepoint remains:
+	app, _ = newApplicationForTests(baseDir)
+	err = app.RollbackTo(*ctx, *outer)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	value, _ := app.Get(*ctx, "my_namespace", first)
+	if string(value) != "first value" {
+		t.Errorf("the value was expected to be %s, %s returned", "first value", value)
+		return
+	}
+
+	// a savepoint created after a restart does not collide with the recovered ones:
+	app, _ = newApplicationForTests(baseDir)
+	later, err := app.Savepoint(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if later.Compare(*outer) || later.Compare(*inner) {
+		t.Errorf("the savepoint created after a restart was not expected to collide with a recovered one")
+		return
+	}
+}
+
+func TestApplication_CommitWithMetadata_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	metadata, err := commits.NewMetadataBuilder().Create().WithAuthor("roger").WithMessage("add the first resource").WithLabels(map[string]string{
+		"ticket": "123",
+	}).Now()
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	app, stateRepository := newApplicationForTests(baseDir)
+	ctx, _ := app.Begin()
+	app.Insert(*ctx, "my_namespace", newResourceForTests("resource", 0), []byte("first value"))
+	err = app.CommitWithMetadata(*ctx, metadata)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// the metadata survives a restart, since it is stored in the commit:
+	app, _ = newApplicationForTests(baseDir)
+	err = app.Push(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	head, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	origins := head.Origins()
+	if len(origins) != 1 {
+		t.Errorf("%d origins were expected, %d returned", 1, len(origins))
+		return
+	}
+
+	if !origins[0].HasMetadata() {
+		t.Errorf("the origin was expected to contain metadata")
+		return
+	}
+
+	retMetadata := origins[0].Metadata()
+	if retMetadata.Author() != "roger" || retMetadata.Message() != "add the first resource" {
+		t.Errorf("the metadata was expected to contain the author and message of the commit")
+		return
+	}
+
+	if ticket, _ := retMetadata.Label("ticket"); ticket != "123" {
+		t.Errorf("the ticket label was expected to be %s, %s returned", "123", ticket)
+		return
+	}
+}
+
+func TestApplication_Subscribe_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateRepository := newApplicationForTests(baseDir)
+	events := make(chan Event, 32)
+	subscription := app.Subscribe(func(evt Event) {
+		events <- evt
+	})
+
+	resource := newResourceForTests("resource", 0)
+	ctx, _ := app.Begin()
+	app.Insert(*ctx, "my_namespace", resource, []byte("first value"))
+	app.Delete(*ctx, "my_namespace", newResourceForTests("resource", 1))
+	app.Commit(*ctx)
+	err := app.Push(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// a context conflicting with the pushed one fails to push, then gets rolled back:
+	conflicting, _ := app.Begin()
+	app.Get(*conflicting, "my_namespace", resource)
+	other, _ := app.Begin()
+	app.Insert(*other, "my_namespace", resource, []byte("other value"))
+	app.Commit(*other)
+	app.Push(*other)
+	app.Insert(*conflicting, "my_namespace", newResourceForTests("resource", 2), []byte("conflicting value"))
+	app.Commit(*conflicting)
+	err = app.Push(*conflicting)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, _ := disks.NewBuilder(baseDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	commitRepository := services.CommitRepository()
+	list, _ := commitRepository.List()
+	if len(list) != 1 {
+		t.Errorf("the conflicting commit was expected to remain")
+		return
+	}
+
+	err = app.RollBack(list[0])
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.Unsubscribe(subscription)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.Unsubscribe(subscription)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	head, _, _ := stateRepository.Retrieve()
+	expected := []uint8{
+		EventContextBegun,
+		EventValueInserted,
+		EventValueDeleted,
+		EventCommitStored,
+		EventStatePushed,
+		EventContextBegun,
+		EventContextBegun,
+		EventValueInserted,
+		EventCommitStored,
+		EventStatePushed,
+		EventValueInserted,
+		EventCommitStored,
+		EventPushFailed,
+		EventCommitRolledBack,
+	}
+
+	for index, kind := range expected {
+		select {
+		case evt := <-events:
+			if evt.Kind() != kind {
+				t.Errorf("the event at index %d was expected to be of kind %d, %d returned", index, kind, evt.Kind())
+				return
+			}
+
+			if kind == EventValueDeleted && !evt.HasResource() {
+				t.Errorf("the deletion event was expected to contain a resource")
+				return
+			}
+
+			if kind == EventPushFailed && !evt.HasFailure() {
+				t.Errorf("the push failed event was expected to contain a failure")
+				return
+			}
+
+			if kind == EventCommitRolledBack && (len(evt.Contexts()) != 1 || !evt.Contexts()[0].Compare(*conflicting)) {
+				t.Errorf("the rolled back event was expected to contain the conflicting context")
+				return
+			}
+
+			if index == 9 && !evt.State().Compare(head.Hash()) {
+				t.Errorf("the pushed state was expected to be %s, %s returned", head.Hash().String(), evt.State().String())
+				return
+			}
+		case <-time.After(time.Second):
+			t.Errorf("the event at index %d was expected to be delivered", index)
+			return
+		}
+	}
+}
+
+func TestApplication_PushOnto_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateRepository := newApplicationForTests(baseDir)
+	first, _ := app.Begin()
+	app.Insert(*first, "my_namespace", newResourceForTests("first", 0), []byte("first value"))
+	app.Commit(*first)
+	second, _ := app.Begin()
+	app.Insert(*second, "my_namespace", newResourceForTests("second", 0), []byte("second value"))
+	app.Commit(*second)
+
+	// the database is empty, so an empty hash is expected:
+	err := app.PushOnto(*first, hash.Hash{})
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	head, _, _ := stateRepository.Retrieve()
+	err = app.PushOnto(*second, hash.Hash{})
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	var mismatch *failures.HeadMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, failures.ErrHeadMismatch) {
+		t.Errorf("the error was expected to be a head mismatch error, %s returned", err.Error())
+		return
+	}
+
+	if !mismatch.Current.Compare(head.Hash()) {
+		t.Errorf("the current head was expected to be %s, %s returned", head.Hash().String(), mismatch.Current.String())
+		return
+	}
+
+	// the commit remains after a mismatch, so it can be pushed onto the current head:
+	err = app.PushOnto(*second, head.Hash())
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	retHead, _, _ := stateRepository.Retrieve()
+	retPrevious, err := retHead.Previous()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !retPrevious.Hash().Compare(head.Hash()) {
+		t.Errorf("the new head was expected to be on top of the expected head")
+		return
+	}
+}
+
+func TestApplication_Push_withContextBegunBeforeCompaction_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, services := newApplicationWithServicesForTests(baseDir, 0, 0)
+	push := func(resource hash.Hash, value string) {
+		ctx, _ := app.Begin()
+		app.Insert(*ctx, "my_namespace", resource, []byte(value))
+		app.Commit(*ctx)
+		err := app.Push(*ctx)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	overwritten := newResourceForTests("overwritten", 0)
+	push(overwritten, "first value")
+
+	// the first context is based on the state dropped by the compaction, the second one on the head:
+	early, _ := app.Begin()
+	push(overwritten, "second value")
+	push(overwritten, "third value")
+	late, _ := app.Begin()
+	app.Insert(*early, "my_namespace", newResourceForTests("early", 0), []byte("early value"))
+	app.Insert(*late, "my_namespace", newResourceForTests("late", 0), []byte("late value"))
+	_, err := services.Compactor().Compact(disks.NewLastStatesRetention(2))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	for _, oneContext := range []hash.Hash{*late, *early} {
+		err = app.Commit(oneContext)
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+
+		err = app.Push(oneContext)
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+	}
+
+	ctx, _ := app.Begin()
+	value, err := app.Get(*ctx, "my_namespace", newResourceForTests("early", 0))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if string(value) != "early value" {
+		t.Errorf("the value was expected to be %s, %s returned", "early value", value)
+		return
+	}
+
+	value, err = app.Get(*ctx, "my_namespace", overwritten)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if string(value) != "third value" {
+		t.Errorf("the value was expected to be %s, %s returned", "third value", value)
+		return
+	}
+}
+
+func TestApplication_Revert_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateRepository := newApplicationForTests(baseDir)
+	updated := newResourceForTests("updated", 0)
+	introduced := newResourceForTests("introduced", 0)
+	deleted := newResourceForTests("deleted", 0)
+	push := func(values map[string][]byte, deletions []hash.Hash) {
+		ctx, _ := app.Begin()
+		for keyname, value := range values {
+			resource, _ := hash.NewAdapter().FromString(keyname)
+			app.Insert(*ctx, "my_namespace", *resource, value)
+		}
+
+		for _, oneResource := range deletions {
+			app.Delete(*ctx, "my_namespace", oneResource)
+		}
+
+		app.Commit(*ctx)
+		err := app.Push(*ctx)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	push(map[string][]byte{
+		updated.String(): []byte("original value"),
+		deleted.String(): []byte("deleted value"),
+	}, nil)
+
+	push(map[string][]byte{
+		updated.String():    []byte("updated value"),
+		introduced.String(): []byte("introduced value"),
+	}, []hash.Hash{
+		deleted,
+	})
+
+	reverted, _, _ := stateRepository.Retrieve()
+	err := app.Revert(reverted.Hash())
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	head, _, _ := stateRepository.Retrieve()
+	if head.Height() != 3 {
+		t.Errorf("the revert was expected to be pushed as a new state")
+		return
+	}
+
+	ctx, _ := app.Begin()
+	value, err := app.Get(*ctx, "my_namespace", updated)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if string(value) != "original value" {
+		t.Errorf("the value was expected to be %s, %s returned", "original value", value)
+		return
+	}
+
+	value, err = app.Get(*ctx, "my_namespace", deleted)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if string(value) != "deleted value" {
+		t.Errorf("the value was expected to be %s, %s returned", "deleted value", value)
+		return
+	}
+
+	_, err = app.Get(*ctx, "my_namespace", introduced)
+	if !errors.Is(err, failures.ErrResourceNotFound) {
+		t.Errorf("the introduced resource was expected to be deleted, %v returned", err)
+		return
+	}
+
+	// reverting a state whose resources were changed by a later state conflicts:
+	err = app.Revert(reverted.Hash())
+	if !errors.Is(err, failures.ErrConflict) {
+		t.Errorf("the error was expected to be a conflict, %v returned", err)
+		return
+	}
+}
+
+func TestApplication_Revert_withCompactedRoot_returnsError(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, services := newApplicationWithServicesForTests(baseDir, 0, 0)
+	for i := 0; i < 3; i++ {
+		ctx, _ := app.Begin()
+		app.Insert(*ctx, "my_namespace", newResourceForTests("resource", i), []byte(fmt.Sprintf("value %d", i)))
+		app.Commit(*ctx)
+		err := app.Push(*ctx)
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+	}
+
+	_, err := services.Compactor().Compact(disks.NewLastStatesRetention(1))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// the root carries the values of the dropped states, so reverting it would delete all of them:
+	head, _, _ := services.StateRepository().Retrieve()
+	root, _ := head.Root()
+	err = app.Revert(root.Hash())
+	if !errors.Is(err, failures.ErrCompactedRevert) {
+		t.Errorf("the error was expected to be a compacted revert error, %v returned", err)
+		return
+	}
+
+	retHead, _, _ := services.StateRepository().Retrieve()
+	if !retHead.Hash().Compare(head.Hash()) {
+		t.Errorf("the revert was not expected to push a state")
+		return
+	}
+
+	// the retained states remain revertable:
+	err = app.Revert(head.Hash())
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	ctx, _ := app.Begin()
+	_, err = app.Get(*ctx, "my_namespace", newResourceForTests("resource", 2))
+	if !errors.Is(err, failures.ErrResourceNotFound) {
+		t.Errorf("the reverted resource was expected to be deleted, %v returned", err)
+		return
+	}
+
+	value, err := app.Get(*ctx, "my_namespace", newResourceForTests("resource", 0))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if string(value) != "value 0" {
+		t.Errorf("the value was expected to be %s, %s returned", "value 0", value)
+		return
+	}
+}
+
+func TestApplication_Revert_withCorruptJournal_returnsError(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, stateRepository := newApplicationForTests(baseDir)
+	ctx, _ := app.Begin()
+	app.Insert(*ctx, "my_namespace", newResourceForTests("first", 0), []byte("first value"))
+	app.Commit(*ctx)
+	err := app.Push(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// a journal whose only entry cannot be decoded:
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	corrupt := newResourceForTests("corrupt", 0)
+	path := filepath.Join(baseDir, application.String(), "contexts", corrupt.String())
+	err = ioutil.WriteFile(path, []byte{4, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff}, 0777)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// the journals are recovered before the revert, so the corrupt one is reported:
+	app, _ = newApplicationForTests(baseDir)
+	head, _, _ := stateRepository.Retrieve()
+	err = app.Revert(head.Hash())
+	if !errors.Is(err, failures.ErrCorruptData) {
+		t.Errorf("the corrupt journal was expected to be reported")
+		return
+	}
+}
+
+func TestApplication_RegisterSchema_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, _ := newApplicationForTests(baseDir)
+	err := app.RegisterSchema("my_namespace", []byte("{"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	ctx, _ := app.Begin()
+	err = app.Insert(*ctx, "my_namespace", newResourceForTests("valid", 0), []byte("{valid}"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	invalid := newResourceForTests("invalid", 0)
+	err = app.Insert(*ctx, "my_namespace", invalid, []byte("invalid"))
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	var validation *failures.ValidationError
+	if !errors.As(err, &validation) || !errors.Is(err, failures.ErrValidation) {
+		t.Errorf("the error was expected to be a validation error, %s returned", err.Error())
+		return
+	}
+
+	if validation.Rule != "prefix" || !validation.Resource.Compare(invalid) {
+		t.Errorf("the validation error does not describe the rejected resource")
+		return
+	}
+
+	// deletions are never validated:
+	err = app.Delete(*ctx, "my_namespace", newResourceForTests("valid", 0))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// other namespaces are not bound by the schema:
+	err = app.Insert(*ctx, "other_namespace", invalid, []byte("invalid"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// a schema registered after the insert is verified at commit:
+	err = app.RegisterSchema("other_namespace", []byte("["))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.Commit(*ctx)
+	if !errors.Is(err, failures.ErrValidation) {
+		t.Errorf("the error was expected to be a validation error")
+		return
+	}
+
+	err = app.RemoveSchema("other_namespace")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.Commit(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.RemoveSchema("other_namespace")
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+}
+
+func TestApplication_RegisterSchema_afterRestart_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	app, _ := newApplicationForTests(baseDir)
+	err := app.RegisterSchema("my_namespace", []byte("{"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.RegisterSchema("removed_namespace", []byte("["))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = app.RemoveSchema("removed_namespace")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// reopen the application, the registered schemas are still enforced:
+	app, _ = newApplicationForTests(baseDir)
+	ctx, _ := app.Begin()
+	err = app.Insert(*ctx, "my_namespace", newResourceForTests("invalid", 0), []byte("invalid"))
+	if !errors.Is(err, failures.ErrValidation) {
+		t.Errorf("the schema was expected to be enforced after a restart")
+		return
+	}
+
+	err = app.Insert(*ctx, "removed_namespace", newResourceForTests("invalid", 0), []byte("invalid"))
+	if err != nil {
+		t.Errorf("the removed schema was not expected to be enforced after a restart")
+		return
+	}
+
+	err = app.RemoveSchema("my_namespace")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+}
@@ -0,0 +1,105 @@
+package transactions
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// bus delivers the events to the subscribed handlers, in order, from a single goroutine so that
+// the handlers can call back into the application without deadlocking on its locks
+type bus struct {
+	mutex     sync.Mutex
+	counter   uint
+	handlers  map[uint]EventFn
+	queue     []*delivery
+	isRunning bool
+}
+
+// delivery represents an event along with the handlers subscribed when it was emitted
+type delivery struct {
+	evt      Event
+	handlers []EventFn
+}
+
+func createBus() *bus {
+	out := bus{
+		counter:   0,
+		handlers:  map[uint]EventFn{},
+		queue:     []*delivery{},
+		isRunning: false,
+	}
+
+	return &out
+}
+
+func (obj *bus) subscribe(handler EventFn) uint {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+	obj.counter++
+	obj.handlers[obj.counter] = handler
+	return obj.counter
+}
+
+func (obj *bus) unsubscribe(subscription uint) error {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+	if _, ok := obj.handlers[subscription]; !ok {
+		str := fmt.Sprintf("the subscription (id: %d) does not exists", subscription)
+		return errors.New(str)
+	}
+
+	delete(obj.handlers, subscription)
+	return nil
+}
+
+func (obj *bus) emit(evt Event) {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+	if len(obj.handlers) <= 0 {
+		return
+	}
+
+	ids := []uint{}
+	for id := range obj.handlers {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i int, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	handlers := []EventFn{}
+	for _, id := range ids {
+		handlers = append(handlers, obj.handlers[id])
+	}
+
+	obj.queue = append(obj.queue, &delivery{
+		evt:      evt,
+		handlers: handlers,
+	})
+
+	if !obj.isRunning {
+		obj.isRunning = true
+		go obj.run()
+	}
+}
+
+func (obj *bus) run() {
+	for {
+		obj.mutex.Lock()
+		if len(obj.queue) <= 0 {
+			obj.isRunning = false
+			obj.mutex.Unlock()
+			return
+		}
+
+		next := obj.queue[0]
+		obj.queue = obj.queue[1:]
+		obj.mutex.Unlock()
+		for _, oneHandler := range next.handlers {
+			oneHandler(next.evt)
+		}
+	}
+}
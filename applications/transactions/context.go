@@ -0,0 +1,130 @@
+package transactions
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+type context struct {
+	mutex      sync.Mutex
+	values     map[string]map[string][]byte
+	reads      map[string]map[string]hash.Hash
+	savepoints []*savepoint
+	base       hash.Hash
+	createdOn  time.Time
+	isClosed   bool
+}
+
+func createContext(
+	base hash.Hash,
+	values map[string]map[string][]byte,
+	reads map[string][]hash.Hash,
+	createdOn time.Time,
+) *context {
+	out := context{
+		values:     values,
+		reads:      map[string]map[string]hash.Hash{},
+		savepoints: []*savepoint{},
+		base:       base,
+		createdOn:  createdOn,
+		isClosed:   false,
+	}
+
+	for namespace, resources := range reads {
+		for _, oneResource := range resources {
+			out.read(namespace, oneResource)
+		}
+	}
+
+	return &out
+}
+
+func (obj *context) write(namespace string, resource hash.Hash, value []byte) {
+	if amount := len(obj.savepoints); amount > 0 {
+		prev, exists := obj.fetch(namespace, resource)
+		obj.savepoints[amount-1].record(namespace, resource, prev, exists)
+	}
+
+	obj.set(namespace, resource, value)
+}
+
+func (obj *context) set(namespace string, resource hash.Hash, value []byte) {
+	if _, ok := obj.values[namespace]; !ok {
+		obj.values[namespace] = map[string][]byte{}
+	}
+
+	obj.values[namespace][resource.String()] = value
+}
+
+func (obj *context) unset(namespace string, resource hash.Hash) {
+	if resources, ok := obj.values[namespace]; ok {
+		delete(resources, resource.String())
+		if len(resources) <= 0 {
+			delete(obj.values, namespace)
+		}
+	}
+}
+
+func (obj *context) fetch(namespace string, resource hash.Hash) ([]byte, bool) {
+	if resources, ok := obj.values[namespace]; ok {
+		value, ok := resources[resource.String()]
+		return value, ok
+	}
+
+	return nil, false
+}
+
+func (obj *context) hasRead(namespace string, resource hash.Hash) bool {
+	if resources, ok := obj.reads[namespace]; ok {
+		_, ok := resources[resource.String()]
+		return ok
+	}
+
+	return false
+}
+
+func (obj *context) read(namespace string, resource hash.Hash) {
+	if _, ok := obj.reads[namespace]; !ok {
+		obj.reads[namespace] = map[string]hash.Hash{}
+	}
+
+	obj.reads[namespace][resource.String()] = resource
+}
+
+func (obj *context) readList() map[string][]hash.Hash {
+	out := map[string][]hash.Hash{}
+	for namespace, resources := range obj.reads {
+		keynames := []string{}
+		for keyname := range resources {
+			keynames = append(keynames, keyname)
+		}
+
+		sort.Strings(keynames)
+		for _, keyname := range keynames {
+			out[namespace] = append(out[namespace], resources[keyname])
+		}
+	}
+
+	return out
+}
+
+func (obj *context) queue() (map[string]map[string][]byte, bool) {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+	if obj.isClosed {
+		return nil, false
+	}
+
+	out := map[string]map[string][]byte{}
+	for namespace, resources := range obj.values {
+		out[namespace] = map[string][]byte{}
+		for keyname, value := range resources {
+			out[namespace][keyname] = value
+		}
+	}
+
+	return out, true
+}
@@ -0,0 +1,138 @@
+package transactions
+
+import (
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+type event struct {
+	kind      uint8
+	contexts  []hash.Hash
+	commits   []hash.Hash
+	namespace string
+	resource  hash.Hash
+	state     hash.Hash
+	failure   error
+	createdOn time.Time
+}
+
+func createEvent(
+	kind uint8,
+	contexts []hash.Hash,
+	commits []hash.Hash,
+) Event {
+	return createEventInternally(kind, contexts, commits, "", nil, nil, nil)
+}
+
+func createEventWithContext(
+	kind uint8,
+	context hash.Hash,
+) Event {
+	return createEventInternally(kind, []hash.Hash{context}, []hash.Hash{}, "", nil, nil, nil)
+}
+
+func createEventWithResource(
+	kind uint8,
+	context hash.Hash,
+	namespace string,
+	resource hash.Hash,
+) Event {
+	return createEventInternally(kind, []hash.Hash{context}, []hash.Hash{}, namespace, resource, nil, nil)
+}
+
+func createEventWithState(
+	kind uint8,
+	contexts []hash.Hash,
+	commits []hash.Hash,
+	state hash.Hash,
+) Event {
+	return createEventInternally(kind, contexts, commits, "", nil, state, nil)
+}
+
+func createEventWithFailure(
+	kind uint8,
+	contexts []hash.Hash,
+	commits []hash.Hash,
+	failure error,
+) Event {
+	return createEventInternally(kind, contexts, commits, "", nil, nil, failure)
+}
+
+func createEventInternally(
+	kind uint8,
+	contexts []hash.Hash,
+	commits []hash.Hash,
+	namespace string,
+	resource hash.Hash,
+	state hash.Hash,
+	failure error,
+) Event {
+	out := event{
+		kind:      kind,
+		contexts:  contexts,
+		commits:   commits,
+		namespace: namespace,
+		resource:  resource,
+		state:     state,
+		failure:   failure,
+		createdOn: time.Now().UTC(),
+	}
+
+	return &out
+}
+
+// Kind returns the kind of event
+func (obj *event) Kind() uint8 {
+	return obj.kind
+}
+
+// Contexts returns the hashes of the contexts involved, if any
+func (obj *event) Contexts() []hash.Hash {
+	return obj.contexts
+}
+
+// Commits returns the hashes of the commits involved, if any
+func (obj *event) Commits() []hash.Hash {
+	return obj.commits
+}
+
+// HasResource returns true if the event concerns a resource, false otherwise
+func (obj *event) HasResource() bool {
+	return len(obj.resource) > 0
+}
+
+// Namespace returns the namespace of the resource, if any
+func (obj *event) Namespace() string {
+	return obj.namespace
+}
+
+// Resource returns the resource hash, if any
+func (obj *event) Resource() hash.Hash {
+	return obj.resource
+}
+
+// HasState returns true if the event concerns a state, false otherwise
+func (obj *event) HasState() bool {
+	return len(obj.state) > 0
+}
+
+// State returns the state hash, if any
+func (obj *event) State() hash.Hash {
+	return obj.state
+}
+
+// HasFailure returns true if the event carries a failure, false otherwise
+func (obj *event) HasFailure() bool {
+	return obj.failure != nil
+}
+
+// Failure returns the failure, if any
+func (obj *event) Failure() error {
+	return obj.failure
+}
+
+// CreatedOn returns the creation time
+func (obj *event) CreatedOn() time.Time {
+	return obj.createdOn
+}
@@ -0,0 +1,77 @@
+package transactions
+
+import (
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/contexts"
+)
+
+type savepoint struct {
+	hash hash.Hash
+	undo map[string]map[string]*prior
+}
+
+type prior struct {
+	resource hash.Hash
+	value    []byte
+	exists   bool
+}
+
+func createSavepoint(
+	hash hash.Hash,
+) *savepoint {
+	out := savepoint{
+		hash: hash,
+		undo: map[string]map[string]*prior{},
+	}
+
+	return &out
+}
+
+func createSavepointFromJournal(hashAdapter hash.Adapter, journal contexts.Savepoint) (*savepoint, error) {
+	out := createSavepoint(journal.Hash())
+	for namespace, resources := range journal.Absents() {
+		for _, oneResource := range resources {
+			out.record(namespace, oneResource, nil, false)
+		}
+	}
+
+	for namespace, resources := range journal.Priors() {
+		for keyname, value := range resources {
+			resource, err := hashAdapter.FromString(keyname)
+			if err != nil {
+				return nil, err
+			}
+
+			out.record(namespace, *resource, value, true)
+		}
+	}
+
+	return out, nil
+}
+
+// record keeps the value a resource had before its first write since the savepoint
+func (obj *savepoint) record(namespace string, resource hash.Hash, value []byte, exists bool) {
+	if _, ok := obj.undo[namespace]; !ok {
+		obj.undo[namespace] = map[string]*prior{}
+	}
+
+	keyname := resource.String()
+	if _, ok := obj.undo[namespace][keyname]; ok {
+		return
+	}
+
+	obj.undo[namespace][keyname] = &prior{
+		resource: resource,
+		value:    value,
+		exists:   exists,
+	}
+}
+
+// merge adds the undo entries of a later savepoint, keeping the older values
+func (obj *savepoint) merge(later *savepoint) {
+	for namespace, resources := range later.undo {
+		for _, onePrior := range resources {
+			obj.record(namespace, onePrior.resource, onePrior.value, onePrior.exists)
+		}
+	}
+}
@@ -1,26 +1,71 @@
 package transactions
 
 import (
+	"time"
+
 	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/contexts"
+	"github.com/steve-care-software/database/domain/resources"
+	"github.com/steve-care-software/database/domain/schemas"
 	"github.com/steve-care-software/database/domain/states"
 	"github.com/steve-care-software/cryptography/domain/hash"
 )
 
+const (
+	// EventContextBegun represents a context that has begun
+	EventContextBegun uint8 = iota
+
+	// EventValueInserted represents a value inserted in a context
+	EventValueInserted
+
+	// EventValueDeleted represents a value deleted in a context
+	EventValueDeleted
+
+	// EventCommitStored represents a context that has been committed
+	EventCommitStored
+
+	// EventCommitRolledBack represents a commit that has been rolled back
+	EventCommitRolledBack
+
+	// EventStatePushed represents a state pushed from one or multiple commits
+	EventStatePushed
+
+	// EventPushFailed represents a push that failed
+	EventPushFailed
+)
+
+// EventFn represents an event handler
+type EventFn func(evt Event)
+
 // NewApplication creates a new application instance
 /*func NewApplication(
 	commitRepository commits.Repository,
 	commitService commits.Service,
+	stateRepository states.Repository,
 	stateService states.Service,
+	contextTTL time.Duration,
+	commitTTL time.Duration,
+	validator Validator,
+	schemaRepository schemas.Repository,
+	schemaService schemas.Service,
 ) Application {
 	hashAdapter := hash.NewAdapter()
 	commitBuilder := commits.NewBuilder()
+	schemaBuilder := schemas.NewBuilder()
 	lexerApp := lexers.NewApplication()
 	return createApplication(
 		hashAdapter,
 		commitBuilder,
 		commitRepository,
 		commitService,
+		stateRepository,
 		stateService,
+		contextTTL,
+		commitTTL,
+		validator,
+		schemaBuilder,
+		schemaRepository,
+		schemaService,
 		lexerApp,
 	)
 }*/
@@ -30,16 +75,72 @@ type Builder interface {
 	Create() Builder
 	WithCommitRepository(commitRepository commits.Repository) Builder
 	WithCommitService(commitService commits.Service) Builder
+	WithResourceRepository(resRepository resources.Repository) Builder
+	WithContextRepository(contextRepository contexts.Repository) Builder
+	WithContextService(contextService contexts.Service) Builder
+	WithStateRepository(stateRepository states.Repository) Builder
 	WithStateService(stateService states.Service) Builder
+	WithContextTTL(contextTTL time.Duration) Builder
+	WithCommitTTL(commitTTL time.Duration) Builder
+	WithValidator(validator Validator) Builder
+	WithSchemaRepository(schemaRepository schemas.Repository) Builder
+	WithSchemaService(schemaService schemas.Service) Builder
 	Now() (Application, error)
 }
 
-// Application represents a transaction application
+// Application represents a transaction application, a nil value in the queue represents a deleted resource
 type Application interface {
 	Begin() (*hash.Hash, error)
 	Insert(context hash.Hash, namespace string, resource hash.Hash, value []byte) error
+	Delete(context hash.Hash, namespace string, resource hash.Hash) error
+	Get(context hash.Hash, namespace string, resource hash.Hash) ([]byte, error)
+	Read(context hash.Hash, namespace string, resource hash.Hash) error
+	Savepoint(context hash.Hash) (*hash.Hash, error)
+	RollbackTo(context hash.Hash, savepoint hash.Hash) error
+	ReleaseSavepoint(context hash.Hash, savepoint hash.Hash) error
 	Commit(context hash.Hash) error
+	CommitWithMetadata(context hash.Hash, metadata commits.Metadata) error
 	Queue(context hash.Hash) (map[string]map[string][]byte, error)
 	RollBack(context hash.Hash) error
+	Contexts() ([]hash.Hash, error)
 	Push(context hash.Hash) error
+	PushOnto(context hash.Hash, expectedHead hash.Hash) error
+	PushAll(contexts []hash.Hash) error
+	Revert(state hash.Hash) error
+	RevertWithMetadata(state hash.Hash, metadata commits.Metadata) error
+	Stale() ([]Stale, error)
+	Reap() error
+	StartReaper(interval time.Duration) error
+	StopReaper() error
+	Subscribe(handler EventFn) uint
+	Unsubscribe(subscription uint) error
+	RegisterSchema(namespace string, schema []byte) error
+	RemoveSchema(namespace string) error
+}
+
+// Validator represents the validator engine that verifies a value against a schema, returning the violated rule, if any
+type Validator interface {
+	Validate(schema []byte, value []byte) (*string, error)
+}
+
+// Event represents a transaction lifecycle event, delivered in order to the subscribed handlers
+type Event interface {
+	Kind() uint8
+	Contexts() []hash.Hash
+	Commits() []hash.Hash
+	HasResource() bool
+	Namespace() string
+	Resource() hash.Hash
+	HasState() bool
+	State() hash.Hash
+	HasFailure() bool
+	Failure() error
+	CreatedOn() time.Time
+}
+
+// Stale represents a context or a commit that outlived its time-to-live
+type Stale interface {
+	Hash() hash.Hash
+	IsCommit() bool
+	Age() time.Duration
 }
@@ -0,0 +1,56 @@
+package transactions
+
+import (
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+type stale struct {
+	hash     hash.Hash
+	isCommit bool
+	age      time.Duration
+}
+
+func createStaleWithContext(
+	hash hash.Hash,
+	age time.Duration,
+) Stale {
+	return createStaleInternally(hash, false, age)
+}
+
+func createStaleWithCommit(
+	hash hash.Hash,
+	age time.Duration,
+) Stale {
+	return createStaleInternally(hash, true, age)
+}
+
+func createStaleInternally(
+	hash hash.Hash,
+	isCommit bool,
+	age time.Duration,
+) Stale {
+	out := stale{
+		hash:     hash,
+		isCommit: isCommit,
+		age:      age,
+	}
+
+	return &out
+}
+
+// Hash returns the hash of the context or commit
+func (obj *stale) Hash() hash.Hash {
+	return obj.hash
+}
+
+// IsCommit returns true if the stale element is a commit, false if it is a context
+func (obj *stale) IsCommit() bool {
+	return obj.isCommit
+}
+
+// Age returns the age
+func (obj *stale) Age() time.Duration {
+	return obj.age
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"path/filepath"
 	"reflect"
+
+	"github.com/steve-care-software/database/domain/failures"
 )
 
 type adapter struct {
@@ -41,15 +43,17 @@ func (app *adapter) ToInstance(bytes []byte) (interface{}, []byte, error) {
 
 func (app *adapter) toInstance(bytes []byte, isPtr bool) (*reflect.Value, []byte, error) {
 	if len(bytes) < 1 {
-		str := fmt.Sprintf(bytesLengthTooSmallErr, 1, len(bytes))
-		return nil, nil, errors.New(str)
+		return nil, nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(bytesLengthTooSmallErr, 1, len(bytes)),
+		}
 	}
 
 	remaining := bytes[1:]
 	if bytes[0]&Bool != 0 {
 		if len(remaining) < 1 {
-			str := fmt.Sprintf(bytesLengthTooSmallErr, 1, len(remaining))
-			return nil, nil, errors.New(str)
+			return nil, nil, &failures.CorruptDataError{
+				Reason: fmt.Sprintf(bytesLengthTooSmallErr, 1, len(remaining)),
+			}
 		}
 
 		value := remaining[0:1][0]
@@ -95,14 +99,16 @@ func (app *adapter) toInstance(bytes []byte, isPtr bool) (*reflect.Value, []byte
 		return app.bytesToFloat(remaining)
 	}
 
-	str := fmt.Sprintf("the given data (%v) could not be converted to an instance", bytes)
-	return nil, nil, errors.New(str)
+	return nil, nil, &failures.CorruptDataError{
+		Reason: fmt.Sprintf("the given data (%v) could not be converted to an instance", bytes),
+	}
 }
 
 func (app *adapter) bytesToFloat(data []byte) (*reflect.Value, []byte, error) {
 	if len(data) < 1 {
-		str := fmt.Sprintf(bytesLengthTooSmallErr, 1, len(data))
-		return nil, nil, errors.New(str)
+		return nil, nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(bytesLengthTooSmallErr, 1, len(data)),
+		}
 	}
 
 	remaining := data[1:]
@@ -126,8 +132,9 @@ func (app *adapter) bytesToFloat(data []byte) (*reflect.Value, []byte, error) {
 		return &value, rem, nil
 	}
 
-	str := fmt.Sprintf("the data (%v) could not be converted to a float", data)
-	return nil, nil, errors.New(str)
+	return nil, nil, &failures.CorruptDataError{
+		Reason: fmt.Sprintf("the data (%v) could not be converted to a float", data),
+	}
 }
 
 func (app *adapter) bytesToFloat32(data []byte) (float32, []byte, error) {
@@ -152,8 +159,9 @@ func (app *adapter) bytesToFloat64(data []byte) (float64, []byte, error) {
 
 func (app *adapter) bytesToInt(data []byte) (*reflect.Value, []byte, error) {
 	if len(data) < 1 {
-		str := fmt.Sprintf(bytesLengthTooSmallErr, 1, len(data))
-		return nil, nil, errors.New(str)
+		return nil, nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(bytesLengthTooSmallErr, 1, len(data)),
+		}
 	}
 
 	remaining := data[1:]
@@ -207,8 +215,9 @@ func (app *adapter) bytesToInt(data []byte) (*reflect.Value, []byte, error) {
 		return &value, rem, nil
 	}
 
-	str := fmt.Sprintf("the data (%v) could not be converted to an int", data)
-	return nil, nil, errors.New(str)
+	return nil, nil, &failures.CorruptDataError{
+		Reason: fmt.Sprintf("the data (%v) could not be converted to an int", data),
+	}
 }
 
 func (app *adapter) bytesToInt8(data []byte) (int8, []byte, error) {
@@ -253,8 +262,9 @@ func (app *adapter) bytesToInt64(data []byte) (int64, []byte, error) {
 
 func (app *adapter) bytesToUint(data []byte) (*reflect.Value, []byte, error) {
 	if len(data) < 1 {
-		str := fmt.Sprintf(bytesLengthTooSmallErr, 1, len(data))
-		return nil, nil, errors.New(str)
+		return nil, nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(bytesLengthTooSmallErr, 1, len(data)),
+		}
 	}
 
 	remaining := data[1:]
@@ -308,8 +318,9 @@ func (app *adapter) bytesToUint(data []byte) (*reflect.Value, []byte, error) {
 		return &value, rem, nil
 	}
 
-	str := fmt.Sprintf("the data (%v) could not be converted to a uint", data)
-	return nil, nil, errors.New(str)
+	return nil, nil, &failures.CorruptDataError{
+		Reason: fmt.Sprintf("the data (%v) could not be converted to a uint", data),
+	}
 }
 
 func (app *adapter) bytesToUint8(data []byte) (uint8, []byte, error) {
@@ -354,8 +365,9 @@ func (app *adapter) bytesToUint64(data []byte) (uint64, []byte, error) {
 
 func (app *adapter) bytesToString(data []byte) (*reflect.Value, []byte, error) {
 	if len(data) < 8 {
-		str := fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data))
-		return nil, nil, errors.New(str)
+		return nil, nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data)),
+		}
 	}
 
 	var strLength uint64
@@ -367,8 +379,9 @@ func (app *adapter) bytesToString(data []byte) (*reflect.Value, []byte, error) {
 
 	castedLength := int(strLength)
 	if len(data) < castedLength {
-		str := fmt.Sprintf(bytesLengthTooSmallErr, castedLength, len(data))
-		return nil, nil, errors.New(str)
+		return nil, nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(bytesLengthTooSmallErr, castedLength, len(data)),
+		}
 	}
 
 	data = data[8:]
@@ -378,8 +391,9 @@ func (app *adapter) bytesToString(data []byte) (*reflect.Value, []byte, error) {
 
 func (app *adapter) bytesToArray(data []byte) (*reflect.Value, []byte, error) {
 	if len(data) < 8 {
-		str := fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data))
-		return nil, nil, errors.New(str)
+		return nil, nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data)),
+		}
 	}
 
 	var nameLength uint64
@@ -391,22 +405,25 @@ func (app *adapter) bytesToArray(data []byte) (*reflect.Value, []byte, error) {
 
 	castedNameLength := int(nameLength)
 	if len(data) < castedNameLength {
-		str := fmt.Sprintf(bytesLengthTooSmallErr, castedNameLength, len(data))
-		return nil, nil, errors.New(str)
+		return nil, nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(bytesLengthTooSmallErr, castedNameLength, len(data)),
+		}
 	}
 
 	data = data[8:]
 	name := string(data[:castedNameLength])
 	data = data[castedNameLength:]
 	if len(data) < 8 {
-		str := fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data))
-		return nil, nil, errors.New(str)
+		return nil, nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data)),
+		}
 	}
 
 	if ptr, ok := app.mapping[name]; ok {
 		if len(data) < 8 {
-			str := fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data))
-			return nil, nil, errors.New(str)
+			return nil, nil, &failures.CorruptDataError{
+				Reason: fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data)),
+			}
 		}
 
 		var length uint64
@@ -443,8 +460,9 @@ func (app *adapter) bytesToArray(data []byte) (*reflect.Value, []byte, error) {
 
 func (app *adapter) bytesToStruct(data []byte, isPtr bool) (*reflect.Value, []byte, error) {
 	if len(data) < 8 {
-		str := fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data))
-		return nil, nil, errors.New(str)
+		return nil, nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data)),
+		}
 	}
 
 	var nameLength uint64
@@ -457,16 +475,18 @@ func (app *adapter) bytesToStruct(data []byte, isPtr bool) (*reflect.Value, []by
 	data = data[8:]
 	castedNameLength := int(nameLength)
 	if len(data) < castedNameLength {
-		str := fmt.Sprintf(bytesLengthTooSmallErr, castedNameLength, len(data))
-		return nil, nil, errors.New(str)
+		return nil, nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(bytesLengthTooSmallErr, castedNameLength, len(data)),
+		}
 	}
 
 	name := string(data[:castedNameLength])
 	if ptr, ok := app.mapping[name]; ok {
 		data = data[castedNameLength:]
 		if len(data) < 8 {
-			str := fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data))
-			return nil, nil, errors.New(str)
+			return nil, nil, &failures.CorruptDataError{
+				Reason: fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data)),
+			}
 		}
 
 		var amount uint64
@@ -486,8 +506,9 @@ func (app *adapter) bytesToStruct(data []byte, isPtr bool) (*reflect.Value, []by
 			}
 
 			if len(data) < 8 {
-				str := fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data))
-				return nil, nil, errors.New(str)
+				return nil, nil, &failures.CorruptDataError{
+					Reason: fmt.Sprintf(bytesLengthTooSmallErr, 8, len(data)),
+				}
 			}
 
 			var fieldLength uint64
@@ -500,8 +521,9 @@ func (app *adapter) bytesToStruct(data []byte, isPtr bool) (*reflect.Value, []by
 			data = data[8:]
 			castedFieldLength := int(fieldLength)
 			if len(data) < castedFieldLength {
-				str := fmt.Sprintf(bytesLengthTooSmallErr, castedFieldLength, len(data))
-				return nil, nil, errors.New(str)
+				return nil, nil, &failures.CorruptDataError{
+					Reason: fmt.Sprintf(bytesLengthTooSmallErr, castedFieldLength, len(data)),
+				}
 			}
 
 			elementBytes := data[:castedFieldLength]
@@ -1,8 +1,11 @@
 package bytes
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+
+	"github.com/steve-care-software/database/domain/failures"
 )
 
 type testStruct struct {
@@ -102,3 +105,36 @@ func TestAdapter_Success(t *testing.T) {
 	}
 
 }
+
+func TestAdapter_withTruncatedData_returnsCorruptDataError(t *testing.T) {
+	adapter, err := NewAdapterBuilder().Create().WithMapping(map[string]interface{}{
+		"github.com/steve-care-software/database/domain/bytes/testSecondStruct": testSecondStruct{},
+	}).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	data, err := adapter.ToBytes(testSecondStruct{
+		First:  uint(43),
+		Second: uint32(242),
+	})
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	for length := 0; length < len(data); length++ {
+		_, _, err = adapter.ToInstance(data[:length])
+		if err == nil {
+			t.Errorf("the error was expected to be valid when the data is truncated to %d bytes, nil returned", length)
+			return
+		}
+
+		if !errors.Is(err, failures.ErrCorruptData) {
+			t.Errorf("the error was expected to be a corrupt data error when the data is truncated to %d bytes, %s returned", length, err.Error())
+			return
+		}
+	}
+}
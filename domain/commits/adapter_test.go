@@ -2,7 +2,9 @@ package commits
 
 import (
 	"testing"
+	"time"
 
+	"github.com/steve-care-software/cryptography/domain/hash"
 	"github.com/steve-care-software/database/domain/bytes"
 )
 
@@ -39,3 +41,172 @@ func TestAdapter_Success(t *testing.T) {
 		return
 	}
 }
+
+func TestAdapter_withBase_withReads_Success(t *testing.T) {
+	hashAdapter := hash.NewAdapter()
+	base, err := hashAdapter.FromBytes([]byte("this is the base state"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	resource, err := hashAdapter.FromBytes([]byte("this is a resource"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	read, err := hashAdapter.FromBytes([]byte("this is a read resource"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	commit, err := NewBuilder().Create().CreatedOn(time.Now().UTC()).WithBase(*base).WithReads(map[string][]hash.Hash{
+		"my_namespace": []hash.Hash{
+			*read,
+		},
+	}).WithValues(map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			resource.String(): []byte("this is some data"),
+		},
+	}).Now()
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	adapter, err := bytes.NewAdapterBuilder().Create().WithMapping(NewMapping()).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	data, err := adapter.ToBytes(commit)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	retCommit, _, err := adapter.ToInstance(data)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	casted := retCommit.(Commit)
+	if !commit.Hash().Compare(casted.Hash()) {
+		t.Errorf("the commit has was expected to be %s, %s returned", commit.Hash().String(), casted.Hash().String())
+		return
+	}
+
+	if !casted.HasBase() {
+		t.Errorf("the commit was expected to contain a base")
+		return
+	}
+
+	if !casted.Base().Compare(*base) {
+		t.Errorf("the base was expected to be %s, %s returned", base.String(), casted.Base().String())
+		return
+	}
+
+	reads := casted.Reads()
+	if len(reads) != 1 {
+		t.Errorf("%d reads were expected, %d returned", 1, len(reads))
+		return
+	}
+
+	if !reads[0].Resource().Compare(*read) {
+		t.Errorf("the read resource was expected to be %s, %s returned", read.String(), reads[0].Resource().String())
+		return
+	}
+}
+
+func TestAdapter_withMetadata_Success(t *testing.T) {
+	metadata, err := NewMetadataBuilder().Create().WithAuthor("roger").WithMessage("this is a message").WithLabels(map[string]string{
+		"ticket":  "123",
+		"cleanup": "true",
+	}).Now()
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	createdOn := time.Now().UTC()
+	values := map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{},
+	}
+
+	resource, _ := hash.NewAdapter().FromBytes([]byte("this is a resource"))
+	values["my_namespace"][resource.String()] = []byte("this is some data")
+	withoutMetadata, err := NewBuilder().Create().CreatedOn(createdOn).WithValues(values).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	commit, err := NewBuilder().Create().CreatedOn(createdOn).WithValues(values).WithMetadata(metadata).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if commit.Hash().Compare(withoutMetadata.Hash()) {
+		t.Errorf("the metadata was expected to be part of the commit hash")
+		return
+	}
+
+	adapter, err := bytes.NewAdapterBuilder().Create().WithMapping(NewMapping()).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	data, err := adapter.ToBytes(commit)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	retCommit, _, err := adapter.ToInstance(data)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	casted := retCommit.(Commit)
+	if !casted.HasMetadata() {
+		t.Errorf("the commit was expected to contain metadata")
+		return
+	}
+
+	retMetadata := casted.Metadata()
+	if !retMetadata.Hash().Compare(metadata.Hash()) {
+		t.Errorf("the metadata hash was expected to be %s, %s returned", metadata.Hash().String(), retMetadata.Hash().String())
+		return
+	}
+
+	if retMetadata.Author() != "roger" {
+		t.Errorf("the author was expected to be %s, %s returned", "roger", retMetadata.Author())
+		return
+	}
+
+	labels := retMetadata.Labels()
+	if len(labels) != 2 || labels[0].Name() != "cleanup" {
+		t.Errorf("the labels were expected to be sorted by name")
+		return
+	}
+
+	ticket, err := retMetadata.Label("ticket")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if ticket != "123" {
+		t.Errorf("the ticket label was expected to be %s, %s returned", "123", ticket)
+		return
+	}
+}
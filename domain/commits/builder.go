@@ -3,6 +3,7 @@ package commits
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/steve-care-software/cryptography/domain/hash"
@@ -14,6 +15,9 @@ type builder struct {
 	valuesBuilder ValuesBuilder
 	values        map[string]map[string][]byte
 	createdOn     *time.Time
+	base          *hash.Hash
+	reads         map[string][]hash.Hash
+	metadata      Metadata
 }
 
 func createBuilder(
@@ -27,6 +31,9 @@ func createBuilder(
 		valuesBuilder: valuesBuilder,
 		values:        nil,
 		createdOn:     nil,
+		base:          nil,
+		reads:         nil,
+		metadata:      nil,
 	}
 
 	return &out
@@ -41,7 +48,7 @@ func (app *builder) Create() Builder {
 	)
 }
 
-// WithValues add values to the builder
+// WithValues add values to the builder, a nil data flags its resource as deleted
 func (app *builder) WithValues(values map[string]map[string][]byte) Builder {
 	app.values = values
 	return app
@@ -53,6 +60,24 @@ func (app *builder) CreatedOn(createdOn time.Time) Builder {
 	return app
 }
 
+// WithBase adds a base state to the builder
+func (app *builder) WithBase(base hash.Hash) Builder {
+	app.base = &base
+	return app
+}
+
+// WithReads adds the read resources, by namespace, to the builder
+func (app *builder) WithReads(reads map[string][]hash.Hash) Builder {
+	app.reads = reads
+	return app
+}
+
+// WithMetadata adds metadata to the builder
+func (app *builder) WithMetadata(metadata Metadata) Builder {
+	app.metadata = metadata
+	return app
+}
+
 // Now builds a new Commit instance
 func (app *builder) Now() (Commit, error) {
 	if app.values == nil {
@@ -63,15 +88,35 @@ func (app *builder) Now() (Commit, error) {
 		return nil, errors.New("the creation time is mandatory in order to build a Commit instance")
 	}
 
+	// the values are hashed in the order of their namespaces then resources, so that the hash does not depend on the map order:
+	valueNamespaces := []string{}
+	for namespace := range app.values {
+		valueNamespaces = append(valueNamespaces, namespace)
+	}
+
+	sort.Strings(valueNamespaces)
 	list := []Value{}
-	for namespace, valueMap := range app.values {
-		for resStr, data := range valueMap {
+	for _, namespace := range valueNamespaces {
+		valueMap := app.values[namespace]
+		resStrs := []string{}
+		for resStr := range valueMap {
+			resStrs = append(resStrs, resStr)
+		}
+
+		sort.Strings(resStrs)
+		for _, resStr := range resStrs {
+			data := valueMap[resStr]
 			resource, err := app.hashAdapter.FromString(resStr)
 			if err != nil {
 				return nil, err
 			}
 
-			value, err := app.valueBuilder.Create().WithNamespace(namespace).WithResource(*resource).WithData(data).Now()
+			valueBuilder := app.valueBuilder.Create().WithNamespace(namespace).WithResource(*resource).WithData(data)
+			if data == nil {
+				valueBuilder.IsDeleted()
+			}
+
+			value, err := valueBuilder.Now()
 			if err != nil {
 				return nil, err
 			}
@@ -85,14 +130,55 @@ func (app *builder) Now() (Commit, error) {
 		return nil, err
 	}
 
-	hash, err := app.hashAdapter.FromMultiBytes([][]byte{
+	data := [][]byte{
 		values.Hash().Bytes(),
 		[]byte(fmt.Sprintf("%d", app.createdOn.UnixNano())),
-	})
+	}
 
+	if app.base != nil {
+		data = append(data, app.base.Bytes())
+	}
+
+	namespaces := []string{}
+	for namespace := range app.reads {
+		namespaces = append(namespaces, namespace)
+	}
+
+	sort.Strings(namespaces)
+	reads := []Read{}
+	for _, namespace := range namespaces {
+		// the reads are hashed in the order of their resources, whatever the order they were provided in:
+		resources := append([]hash.Hash{}, app.reads[namespace]...)
+		sort.Slice(resources, func(i int, j int) bool {
+			return resources[i].String() < resources[j].String()
+		})
+
+		for _, oneResource := range resources {
+			data = append(data, []byte(namespace), oneResource.Bytes())
+			reads = append(reads, createRead(namespace, oneResource))
+		}
+	}
+
+	if app.metadata != nil {
+		data = append(data, app.metadata.Hash().Bytes())
+	}
+
+	hash, err := app.hashAdapter.FromMultiBytes(data)
 	if err != nil {
 		return nil, err
 	}
 
-	return createCommit(*hash, values, app.createdOn.UnixNano()), nil
+	if app.base != nil && app.metadata != nil {
+		return createCommitWithBaseAndMetadata(*hash, values, app.createdOn.UnixNano(), *app.base, reads, app.metadata), nil
+	}
+
+	if app.base != nil {
+		return createCommitWithBase(*hash, values, app.createdOn.UnixNano(), *app.base, reads), nil
+	}
+
+	if app.metadata != nil {
+		return createCommitWithMetadata(*hash, values, app.createdOn.UnixNano(), reads, app.metadata), nil
+	}
+
+	return createCommit(*hash, values, app.createdOn.UnixNano(), reads), nil
 }
@@ -0,0 +1,53 @@
+package commits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+func TestBuilder_hashDoesNotDependOnOrder_Success(t *testing.T) {
+	hashAdapter := hash.NewAdapter()
+	createdOn := time.Now().UTC()
+	values := map[string]map[string][]byte{}
+	reads := []hash.Hash{}
+	for _, oneNamespace := range []string{"first", "second", "third"} {
+		values[oneNamespace] = map[string][]byte{}
+		for _, oneName := range []string{"a", "b", "c", "d", "e"} {
+			resource, _ := hashAdapter.FromBytes([]byte(oneNamespace + oneName))
+			values[oneNamespace][resource.String()] = []byte(oneName)
+			reads = append(reads, *resource)
+		}
+	}
+
+	reversed := []hash.Hash{}
+	for i := len(reads) - 1; i >= 0; i-- {
+		reversed = append(reversed, reads[i])
+	}
+
+	first, err := NewBuilder().Create().WithValues(values).WithReads(map[string][]hash.Hash{
+		"first": reads,
+	}).CreatedOn(createdOn).Now()
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	for i := 0; i < 10; i++ {
+		commit, err := NewBuilder().Create().WithValues(values).WithReads(map[string][]hash.Hash{
+			"first": reversed,
+		}).CreatedOn(createdOn).Now()
+
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+
+		if !commit.Hash().Compare(first.Hash()) {
+			t.Errorf("the commit hash was expected to be %s, %s returned", first.Hash().String(), commit.Hash().String())
+			return
+		}
+	}
+}
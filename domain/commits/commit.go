@@ -10,17 +10,66 @@ type commit struct {
 	Hsh  hash.Hash
 	Vals Values
 	CrOn int64
+	Bse  hash.Hash
+	Rds  []Read
+	Meta Metadata
 }
 
 func createCommit(
 	hash hash.Hash,
 	values Values,
 	createdOn int64,
+	reads []Read,
+) Commit {
+	return createCommitInternally(hash, values, createdOn, nil, reads, nil)
+}
+
+func createCommitWithBase(
+	hash hash.Hash,
+	values Values,
+	createdOn int64,
+	base hash.Hash,
+	reads []Read,
+) Commit {
+	return createCommitInternally(hash, values, createdOn, base, reads, nil)
+}
+
+func createCommitWithMetadata(
+	hash hash.Hash,
+	values Values,
+	createdOn int64,
+	reads []Read,
+	metadata Metadata,
+) Commit {
+	return createCommitInternally(hash, values, createdOn, nil, reads, metadata)
+}
+
+func createCommitWithBaseAndMetadata(
+	hash hash.Hash,
+	values Values,
+	createdOn int64,
+	base hash.Hash,
+	reads []Read,
+	metadata Metadata,
+) Commit {
+	return createCommitInternally(hash, values, createdOn, base, reads, metadata)
+}
+
+func createCommitInternally(
+	hash hash.Hash,
+	values Values,
+	createdOn int64,
+	base hash.Hash,
+	reads []Read,
+	metadata Metadata,
 ) Commit {
 	out := commit{
 		Hsh:  hash,
 		Vals: values,
 		CrOn: createdOn,
+		Bse:  base,
+		Rds:  reads,
+		Meta: metadata,
 	}
 
 	return &out
@@ -40,3 +89,28 @@ func (obj *commit) Values() Values {
 func (obj *commit) CreatedOn() time.Time {
 	return time.Unix(0, obj.CrOn)
 }
+
+// HasBase returns true if there is a base state, false otherwise
+func (obj *commit) HasBase() bool {
+	return len(obj.Bse) > 0
+}
+
+// Base returns the state the commit was based on, if any
+func (obj *commit) Base() hash.Hash {
+	return obj.Bse
+}
+
+// Reads returns the resources read by the commit
+func (obj *commit) Reads() []Read {
+	return obj.Rds
+}
+
+// HasMetadata returns true if there is metadata, false otherwise
+func (obj *commit) HasMetadata() bool {
+	return obj.Meta != nil
+}
+
+// Metadata returns the metadata, if any
+func (obj *commit) Metadata() Metadata {
+	return obj.Meta
+}
@@ -0,0 +1,28 @@
+package commits
+
+type label struct {
+	Nme string
+	Val string
+}
+
+func createLabel(
+	name string,
+	value string,
+) Label {
+	out := label{
+		Nme: name,
+		Val: value,
+	}
+
+	return &out
+}
+
+// Name returns the name
+func (obj *label) Name() string {
+	return obj.Nme
+}
+
+// Value returns the value
+func (obj *label) Value() string {
+	return obj.Val
+}
@@ -0,0 +1,63 @@
+package commits
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+type metadata struct {
+	Hsh   hash.Hash
+	Athor string
+	Msg   string
+	Lbls  []Label
+}
+
+func createMetadata(
+	hash hash.Hash,
+	author string,
+	message string,
+	labels []Label,
+) Metadata {
+	out := metadata{
+		Hsh:   hash,
+		Athor: author,
+		Msg:   message,
+		Lbls:  labels,
+	}
+
+	return &out
+}
+
+// Hash returns the hash
+func (obj *metadata) Hash() hash.Hash {
+	return obj.Hsh
+}
+
+// Author returns the author
+func (obj *metadata) Author() string {
+	return obj.Athor
+}
+
+// Message returns the message, if any
+func (obj *metadata) Message() string {
+	return obj.Msg
+}
+
+// Labels returns the labels, sorted by name
+func (obj *metadata) Labels() []Label {
+	return obj.Lbls
+}
+
+// Label returns the value of a label by name
+func (obj *metadata) Label(name string) (string, error) {
+	for _, oneLabel := range obj.Lbls {
+		if oneLabel.Name() == name {
+			return oneLabel.Value(), nil
+		}
+	}
+
+	str := fmt.Sprintf("the label (name: %s) does not exists", name)
+	return "", errors.New(str)
+}
@@ -0,0 +1,98 @@
+package commits
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+type metadataBuilder struct {
+	hashAdapter hash.Adapter
+	author      string
+	message     string
+	labels      map[string]string
+}
+
+func createMetadataBuilder(
+	hashAdapter hash.Adapter,
+) MetadataBuilder {
+	out := metadataBuilder{
+		hashAdapter: hashAdapter,
+		author:      "",
+		message:     "",
+		labels:      nil,
+	}
+
+	return &out
+}
+
+// Create initializes the builder
+func (app *metadataBuilder) Create() MetadataBuilder {
+	return createMetadataBuilder(app.hashAdapter)
+}
+
+// WithAuthor adds an author to the builder
+func (app *metadataBuilder) WithAuthor(author string) MetadataBuilder {
+	app.author = author
+	return app
+}
+
+// WithMessage adds a message to the builder
+func (app *metadataBuilder) WithMessage(message string) MetadataBuilder {
+	app.message = message
+	return app
+}
+
+// WithLabels adds labels to the builder
+func (app *metadataBuilder) WithLabels(labels map[string]string) MetadataBuilder {
+	app.labels = labels
+	return app
+}
+
+// Now builds a new Metadata instance
+func (app *metadataBuilder) Now() (Metadata, error) {
+	if app.author == "" {
+		return nil, errors.New("the author is mandatory in order to build a Metadata instance")
+	}
+
+	names := []string{}
+	for name := range app.labels {
+		if name == "" {
+			return nil, errors.New("the name of a label cannot be empty in order to build a Metadata instance")
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	fields := []string{
+		app.author,
+		app.message,
+	}
+
+	labels := []Label{}
+	for _, name := range names {
+		value := app.labels[name]
+		fields = append(fields, name, value)
+		labels = append(labels, createLabel(name, value))
+	}
+
+	// each field is hashed on its own, so that the bytes of a field cannot be moved to its neighbour:
+	data := [][]byte{}
+	for _, oneField := range fields {
+		fieldHash, err := app.hashAdapter.FromBytes([]byte(oneField))
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, fieldHash.Bytes())
+	}
+
+	hash, err := app.hashAdapter.FromMultiBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return createMetadata(*hash, app.author, app.message, labels), nil
+}
@@ -0,0 +1,44 @@
+package commits
+
+import (
+	"testing"
+)
+
+func TestMetadataBuilder_fieldsDoNotCollide_Success(t *testing.T) {
+	first, err := NewMetadataBuilder().Create().WithAuthor("ab").WithMessage("c").WithLabels(map[string]string{
+		"x": "yz",
+	}).Now()
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	list := []Metadata{}
+	for _, oneBuilder := range []MetadataBuilder{
+		NewMetadataBuilder().Create().WithAuthor("a").WithMessage("bc").WithLabels(map[string]string{
+			"x": "yz",
+		}),
+		NewMetadataBuilder().Create().WithAuthor("ab").WithMessage("c").WithLabels(map[string]string{
+			"xy": "z",
+		}),
+		NewMetadataBuilder().Create().WithAuthor("ab").WithMessage("cx").WithLabels(map[string]string{
+			"y": "z",
+		}),
+	} {
+		metadata, err := oneBuilder.Now()
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+
+		list = append(list, metadata)
+	}
+
+	for index, oneMetadata := range list {
+		if oneMetadata.Hash().Compare(first.Hash()) {
+			t.Errorf("the metadata at index %d was not expected to have the hash of the first metadata", index)
+			return
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package commits
+
+import "github.com/steve-care-software/cryptography/domain/hash"
+
+type read struct {
+	NmeSpace string
+	Res      hash.Hash
+}
+
+func createRead(
+	namespace string,
+	resource hash.Hash,
+) Read {
+	out := read{
+		NmeSpace: namespace,
+		Res:      resource,
+	}
+
+	return &out
+}
+
+// Namespace returns the namespace
+func (obj *read) Namespace() string {
+	return obj.NmeSpace
+}
+
+// Resource returns the resource hash
+func (obj *read) Resource() hash.Hash {
+	return obj.Res
+}
@@ -20,11 +20,29 @@ func NewMapping() map[string]interface{} {
 		"github.com/steve-care-software/database/domain/commits/commit": new(commit),
 		"github.com/steve-care-software/database/domain/commits/values": new(values),
 		"github.com/steve-care-software/database/domain/commits/value":  new(value),
+		"github.com/steve-care-software/database/domain/commits/read":   new(read),
 		"[]commits.Value": new(Value),
+		"[]commits.Read":  new(Read),
 		"[]uint8":         uint8(0),
 		"hash.Hash":       uint8(0),
 	}
 
+	for keyname, value := range NewMetadataMapping() {
+		mp[keyname] = value
+	}
+
+	return mp
+}
+
+// NewMetadataMapping returns the metadata conversion mapping
+func NewMetadataMapping() map[string]interface{} {
+	mp := map[string]interface{}{
+		"github.com/steve-care-software/database/domain/commits/metadata": new(metadata),
+		"github.com/steve-care-software/database/domain/commits/label":    new(label),
+		"[]commits.Label": new(Label),
+		"hash.Hash":       uint8(0),
+	}
+
 	return mp
 }
 
@@ -36,6 +54,12 @@ func NewBuilder() Builder {
 	return createBuilder(hashAdapter, valueBuilder, valuesBuilder)
 }
 
+// NewMetadataBuilder creates a new metadata builder
+func NewMetadataBuilder() MetadataBuilder {
+	hashAdapter := hash.NewAdapter()
+	return createMetadataBuilder(hashAdapter)
+}
+
 // NewValuesBuilder creates a new values builder
 func NewValuesBuilder() ValuesBuilder {
 	hashAdapter := hash.NewAdapter()
@@ -53,6 +77,9 @@ type Builder interface {
 	Create() Builder
 	WithValues(values map[string]map[string][]byte) Builder
 	CreatedOn(createdOn time.Time) Builder
+	WithBase(base hash.Hash) Builder
+	WithReads(reads map[string][]hash.Hash) Builder
+	WithMetadata(metadata Metadata) Builder
 	Now() (Commit, error)
 }
 
@@ -61,6 +88,41 @@ type Commit interface {
 	Hash() hash.Hash
 	Values() Values
 	CreatedOn() time.Time
+	HasBase() bool
+	Base() hash.Hash
+	Reads() []Read
+	HasMetadata() bool
+	Metadata() Metadata
+}
+
+// MetadataBuilder represents the metadata builder
+type MetadataBuilder interface {
+	Create() MetadataBuilder
+	WithAuthor(author string) MetadataBuilder
+	WithMessage(message string) MetadataBuilder
+	WithLabels(labels map[string]string) MetadataBuilder
+	Now() (Metadata, error)
+}
+
+// Metadata represents the author, message and labels of a commit
+type Metadata interface {
+	Hash() hash.Hash
+	Author() string
+	Message() string
+	Labels() []Label
+	Label(name string) (string, error)
+}
+
+// Label represents a commit label
+type Label interface {
+	Name() string
+	Value() string
+}
+
+// Read represents a resource read by a commit
+type Read interface {
+	Namespace() string
+	Resource() hash.Hash
 }
 
 // ValuesBuilder represents the values builder
@@ -83,6 +145,7 @@ type ValueBuilder interface {
 	WithNamespace(namespace string) ValueBuilder
 	WithResource(resource hash.Hash) ValueBuilder
 	WithData(data []byte) ValueBuilder
+	IsDeleted() ValueBuilder
 	Now() (Value, error)
 }
 
@@ -92,6 +155,7 @@ type Value interface {
 	Namespace() string
 	Resource() hash.Hash
 	Data() []byte
+	IsDeleted() bool
 }
 
 // Repository represents a context repository
@@ -7,6 +7,7 @@ type value struct {
 	NmeSpace string
 	Res      hash.Hash
 	Dat      []byte
+	IsDel    bool
 }
 
 func createValue(
@@ -14,12 +15,31 @@ func createValue(
 	namespace string,
 	resource hash.Hash,
 	data []byte,
+) Value {
+	return createValueInternally(hash, namespace, resource, data, false)
+}
+
+func createValueWithDeletion(
+	hash hash.Hash,
+	namespace string,
+	resource hash.Hash,
+) Value {
+	return createValueInternally(hash, namespace, resource, nil, true)
+}
+
+func createValueInternally(
+	hash hash.Hash,
+	namespace string,
+	resource hash.Hash,
+	data []byte,
+	isDeleted bool,
 ) Value {
 	out := value{
 		Hsh:      hash,
 		NmeSpace: namespace,
 		Res:      resource,
 		Dat:      data,
+		IsDel:    isDeleted,
 	}
 
 	return &out
@@ -44,3 +64,8 @@ func (obj *value) Resource() hash.Hash {
 func (obj *value) Data() []byte {
 	return obj.Dat
 }
+
+// IsDeleted returns true if the value is a tombstone, false otherwise
+func (obj *value) IsDeleted() bool {
+	return obj.IsDel
+}
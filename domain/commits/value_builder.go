@@ -2,15 +2,21 @@ package commits
 
 import (
 	"errors"
+	"unicode/utf8"
 
 	"github.com/steve-care-software/cryptography/domain/hash"
 )
 
+// deletionFlag prefixes the hashed data of a deletion.  A valid UTF-8 namespace never contains it, so no Value data
+// can produce the hash of a deletion
+const deletionFlag = 0xff
+
 type valueBuilder struct {
 	hashAdapter hash.Adapter
 	namespace   string
 	resource    *hash.Hash
 	data        []byte
+	isDeleted   bool
 }
 
 func createValueBuilder(
@@ -21,6 +27,7 @@ func createValueBuilder(
 		namespace:   "",
 		resource:    nil,
 		data:        nil,
+		isDeleted:   false,
 	}
 
 	return &out
@@ -49,24 +56,56 @@ func (app *valueBuilder) WithData(data []byte) ValueBuilder {
 	return app
 }
 
+// IsDeleted flags the builder as a deletion
+func (app *valueBuilder) IsDeleted() ValueBuilder {
+	app.isDeleted = true
+	return app
+}
+
 // Now builds a new Value instance
 func (app *valueBuilder) Now() (Value, error) {
-	if app.data == nil && len(app.data) <= 0 {
-		app.data = nil
-	}
-
-	if app.data == nil {
-		return nil, errors.New("the data is mandatory in order to build a Value instance")
-	}
-
 	if app.namespace == "" {
 		return nil, errors.New("the namespace is mandatory in order to build a Value instance")
 	}
 
+	if !utf8.ValidString(app.namespace) {
+		return nil, errors.New("the namespace must be a valid UTF-8 string in order to build a Value instance")
+	}
+
 	if app.resource == nil {
 		return nil, errors.New("the resource hash is mandatory in order to build a Value instance")
 	}
 
+	if app.isDeleted {
+		if app.data != nil {
+			return nil, errors.New("the data cannot be provided when the Value is a deletion")
+		}
+
+		hash, err := app.hashAdapter.FromMultiBytes([][]byte{
+			[]byte{deletionFlag},
+			[]byte(app.namespace),
+			app.resource.Bytes(),
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return createValueWithDeletion(
+			*hash,
+			app.namespace,
+			*app.resource,
+		), nil
+	}
+
+	if app.data == nil && len(app.data) <= 0 {
+		app.data = nil
+	}
+
+	if app.data == nil {
+		return nil, errors.New("the data is mandatory in order to build a Value instance")
+	}
+
 	hash, err := app.hashAdapter.FromMultiBytes([][]byte{
 		[]byte(app.namespace),
 		app.resource.Bytes(),
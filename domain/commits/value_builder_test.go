@@ -0,0 +1,41 @@
+package commits
+
+import (
+	"testing"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+func TestValueBuilder_deletion_doesNotCollideWithData_Success(t *testing.T) {
+	resource, _ := hash.NewAdapter().FromBytes([]byte("this is a resource"))
+	deletion, err := NewValueBuilder().Create().WithNamespace("my_namespace").WithResource(*resource).IsDeleted().Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	for _, oneData := range [][]byte{
+		[]byte("deleted"),
+		[]byte{deletionFlag},
+	} {
+		value, err := NewValueBuilder().Create().WithNamespace("my_namespace").WithResource(*resource).WithData(oneData).Now()
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+
+		if value.Hash().Compare(deletion.Hash()) {
+			t.Errorf("the value (data: %v) was not expected to have the hash of the deletion", oneData)
+			return
+		}
+	}
+}
+
+func TestValueBuilder_withInvalidNamespace_returnsError(t *testing.T) {
+	resource, _ := hash.NewAdapter().FromBytes([]byte("this is a resource"))
+	_, err := NewValueBuilder().Create().WithNamespace(string([]byte{deletionFlag})).WithResource(*resource).IsDeleted().Now()
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+}
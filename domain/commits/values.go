@@ -1,10 +1,8 @@
 package commits
 
 import (
-	"errors"
-	"fmt"
-
 	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/failures"
 )
 
 type values struct {
@@ -50,6 +48,7 @@ func (obj *values) FetchByResource(res hash.Hash) (Value, error) {
 		return ins, nil
 	}
 
-	str := fmt.Sprintf("there is no value assigned to the given resource (hash: %s)", keyname)
-	return nil, errors.New(str)
+	return nil, &failures.ResourceNotFoundError{
+		Resource: res,
+	}
 }
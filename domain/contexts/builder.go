@@ -0,0 +1,116 @@
+package contexts
+
+import (
+	"errors"
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+type builder struct {
+	hash       *hash.Hash
+	base       *hash.Hash
+	values     map[string]map[string][]byte
+	reads      map[string][]hash.Hash
+	commit     *hash.Hash
+	savepoints []Savepoint
+	createdOn  *time.Time
+}
+
+func createBuilder() Builder {
+	out := builder{
+		hash:       nil,
+		base:       nil,
+		values:     nil,
+		reads:      nil,
+		commit:     nil,
+		savepoints: nil,
+		createdOn:  nil,
+	}
+
+	return &out
+}
+
+// Create initializes the builder
+func (app *builder) Create() Builder {
+	return createBuilder()
+}
+
+// WithHash adds an hash to the builder
+func (app *builder) WithHash(hash hash.Hash) Builder {
+	app.hash = &hash
+	return app
+}
+
+// WithBase adds a base state to the builder
+func (app *builder) WithBase(base hash.Hash) Builder {
+	app.base = &base
+	return app
+}
+
+// WithValues add values to the builder
+func (app *builder) WithValues(values map[string]map[string][]byte) Builder {
+	app.values = values
+	return app
+}
+
+// WithReads adds the read resources, by namespace, to the builder
+func (app *builder) WithReads(reads map[string][]hash.Hash) Builder {
+	app.reads = reads
+	return app
+}
+
+// WithCommit adds a commit to the builder
+func (app *builder) WithCommit(commit hash.Hash) Builder {
+	app.commit = &commit
+	return app
+}
+
+// WithSavepoints adds the savepoints, from the oldest to the latest, to the builder
+func (app *builder) WithSavepoints(savepoints []Savepoint) Builder {
+	app.savepoints = savepoints
+	return app
+}
+
+// CreatedOn adds a creation time to the builder
+func (app *builder) CreatedOn(createdOn time.Time) Builder {
+	app.createdOn = &createdOn
+	return app
+}
+
+// Now builds a new Context instance
+func (app *builder) Now() (Context, error) {
+	if app.hash == nil {
+		return nil, errors.New("the hash is mandatory in order to build a Context instance")
+	}
+
+	if app.createdOn == nil {
+		return nil, errors.New("the creation time is mandatory in order to build a Context instance")
+	}
+
+	if app.values == nil {
+		app.values = map[string]map[string][]byte{}
+	}
+
+	if app.reads == nil {
+		app.reads = map[string][]hash.Hash{}
+	}
+
+	if app.savepoints == nil {
+		app.savepoints = []Savepoint{}
+	}
+
+	if app.base != nil && app.commit != nil {
+		return createContextWithBaseAndCommit(*app.hash, app.values, app.reads, *app.createdOn, *app.base, *app.commit, app.savepoints), nil
+	}
+
+	if app.base != nil {
+		return createContextWithBase(*app.hash, app.values, app.reads, *app.createdOn, *app.base, app.savepoints), nil
+	}
+
+	if app.commit != nil {
+		return createContextWithCommit(*app.hash, app.values, app.reads, *app.createdOn, *app.commit, app.savepoints), nil
+	}
+
+	return createContext(*app.hash, app.values, app.reads, *app.createdOn, app.savepoints), nil
+}
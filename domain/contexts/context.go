@@ -0,0 +1,128 @@
+package contexts
+
+import (
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+type context struct {
+	hash       hash.Hash
+	values     map[string]map[string][]byte
+	reads      map[string][]hash.Hash
+	createdOn  time.Time
+	base       hash.Hash
+	commit     hash.Hash
+	savepoints []Savepoint
+}
+
+func createContext(
+	hash hash.Hash,
+	values map[string]map[string][]byte,
+	reads map[string][]hash.Hash,
+	createdOn time.Time,
+	savepoints []Savepoint,
+) Context {
+	return createContextInternally(hash, values, reads, createdOn, nil, nil, savepoints)
+}
+
+func createContextWithBase(
+	hash hash.Hash,
+	values map[string]map[string][]byte,
+	reads map[string][]hash.Hash,
+	createdOn time.Time,
+	base hash.Hash,
+	savepoints []Savepoint,
+) Context {
+	return createContextInternally(hash, values, reads, createdOn, base, nil, savepoints)
+}
+
+func createContextWithCommit(
+	hash hash.Hash,
+	values map[string]map[string][]byte,
+	reads map[string][]hash.Hash,
+	createdOn time.Time,
+	commit hash.Hash,
+	savepoints []Savepoint,
+) Context {
+	return createContextInternally(hash, values, reads, createdOn, nil, commit, savepoints)
+}
+
+func createContextWithBaseAndCommit(
+	hash hash.Hash,
+	values map[string]map[string][]byte,
+	reads map[string][]hash.Hash,
+	createdOn time.Time,
+	base hash.Hash,
+	commit hash.Hash,
+	savepoints []Savepoint,
+) Context {
+	return createContextInternally(hash, values, reads, createdOn, base, commit, savepoints)
+}
+
+func createContextInternally(
+	hash hash.Hash,
+	values map[string]map[string][]byte,
+	reads map[string][]hash.Hash,
+	createdOn time.Time,
+	base hash.Hash,
+	commit hash.Hash,
+	savepoints []Savepoint,
+) Context {
+	out := context{
+		hash:       hash,
+		values:     values,
+		reads:      reads,
+		createdOn:  createdOn,
+		base:       base,
+		commit:     commit,
+		savepoints: savepoints,
+	}
+
+	return &out
+}
+
+// Hash returns the hash
+func (obj *context) Hash() hash.Hash {
+	return obj.hash
+}
+
+// Values returns the values
+func (obj *context) Values() map[string]map[string][]byte {
+	return obj.values
+}
+
+// Reads returns the read resources, by namespace
+func (obj *context) Reads() map[string][]hash.Hash {
+	return obj.reads
+}
+
+// CreatedOn returns the creation time
+func (obj *context) CreatedOn() time.Time {
+	return obj.createdOn
+}
+
+// HasBase returns true if there is a base state, false otherwise
+func (obj *context) HasBase() bool {
+	return obj.base != nil
+}
+
+// Base returns the base state, if any
+func (obj *context) Base() hash.Hash {
+	return obj.base
+}
+
+// HasCommit returns true if the context has been committed, false otherwise
+func (obj *context) HasCommit() bool {
+	return obj.commit != nil
+}
+
+// Commit returns the commit, if any
+func (obj *context) Commit() hash.Hash {
+	return obj.commit
+}
+
+// Savepoints returns the savepoints, from the oldest to the latest
+func (obj *context) Savepoints() []Savepoint {
+	return obj.savepoints
+}
@@ -0,0 +1,40 @@
+package contexts
+
+import (
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+type savepoint struct {
+	hash    hash.Hash
+	priors  map[string]map[string][]byte
+	absents map[string][]hash.Hash
+}
+
+func createSavepoint(
+	hash hash.Hash,
+	priors map[string]map[string][]byte,
+	absents map[string][]hash.Hash,
+) Savepoint {
+	out := savepoint{
+		hash:    hash,
+		priors:  priors,
+		absents: absents,
+	}
+
+	return &out
+}
+
+// Hash returns the hash
+func (obj *savepoint) Hash() hash.Hash {
+	return obj.hash
+}
+
+// Priors returns the queued values replaced since the savepoint, by namespace
+func (obj *savepoint) Priors() map[string]map[string][]byte {
+	return obj.priors
+}
+
+// Absents returns the resources that were not queued before their write since the savepoint, by namespace
+func (obj *savepoint) Absents() map[string][]hash.Hash {
+	return obj.absents
+}
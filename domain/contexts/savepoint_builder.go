@@ -0,0 +1,63 @@
+package contexts
+
+import (
+	"errors"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+type savepointBuilder struct {
+	hash    *hash.Hash
+	priors  map[string]map[string][]byte
+	absents map[string][]hash.Hash
+}
+
+func createSavepointBuilder() SavepointBuilder {
+	out := savepointBuilder{
+		hash:    nil,
+		priors:  nil,
+		absents: nil,
+	}
+
+	return &out
+}
+
+// Create initializes the builder
+func (app *savepointBuilder) Create() SavepointBuilder {
+	return createSavepointBuilder()
+}
+
+// WithHash adds an hash to the builder
+func (app *savepointBuilder) WithHash(hash hash.Hash) SavepointBuilder {
+	app.hash = &hash
+	return app
+}
+
+// WithPriors adds the replaced queued values, by namespace, to the builder
+func (app *savepointBuilder) WithPriors(priors map[string]map[string][]byte) SavepointBuilder {
+	app.priors = priors
+	return app
+}
+
+// WithAbsents adds the resources that were not queued, by namespace, to the builder
+func (app *savepointBuilder) WithAbsents(absents map[string][]hash.Hash) SavepointBuilder {
+	app.absents = absents
+	return app
+}
+
+// Now builds a new Savepoint instance
+func (app *savepointBuilder) Now() (Savepoint, error) {
+	if app.hash == nil {
+		return nil, errors.New("the hash is mandatory in order to build a Savepoint instance")
+	}
+
+	if app.priors == nil {
+		app.priors = map[string]map[string][]byte{}
+	}
+
+	if app.absents == nil {
+		app.absents = map[string][]hash.Hash{}
+	}
+
+	return createSavepoint(*app.hash, app.priors, app.absents), nil
+}
@@ -0,0 +1,79 @@
+package contexts
+
+import (
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+// NewBuilder creates a new builder instance
+func NewBuilder() Builder {
+	return createBuilder()
+}
+
+// NewSavepointBuilder creates a new savepoint builder instance
+func NewSavepointBuilder() SavepointBuilder {
+	return createSavepointBuilder()
+}
+
+// Builder represents a context builder
+type Builder interface {
+	Create() Builder
+	WithHash(hash hash.Hash) Builder
+	WithBase(base hash.Hash) Builder
+	WithValues(values map[string]map[string][]byte) Builder
+	WithReads(reads map[string][]hash.Hash) Builder
+	WithCommit(commit hash.Hash) Builder
+	WithSavepoints(savepoints []Savepoint) Builder
+	CreatedOn(createdOn time.Time) Builder
+	Now() (Context, error)
+}
+
+// Context represents a transaction context, a nil value represents a deleted resource
+type Context interface {
+	Hash() hash.Hash
+	Values() map[string]map[string][]byte
+	Reads() map[string][]hash.Hash
+	CreatedOn() time.Time
+	HasBase() bool
+	Base() hash.Hash
+	HasCommit() bool
+	Commit() hash.Hash
+	Savepoints() []Savepoint
+}
+
+// SavepointBuilder represents a savepoint builder
+type SavepointBuilder interface {
+	Create() SavepointBuilder
+	WithHash(hash hash.Hash) SavepointBuilder
+	WithPriors(priors map[string]map[string][]byte) SavepointBuilder
+	WithAbsents(absents map[string][]hash.Hash) SavepointBuilder
+	Now() (Savepoint, error)
+}
+
+// Savepoint represents a savepoint of a context, along with what its later writes replaced in the queue, a nil value
+// represents a deleted resource
+type Savepoint interface {
+	Hash() hash.Hash
+	Priors() map[string]map[string][]byte
+	Absents() map[string][]hash.Hash
+}
+
+// Repository represents a context repository
+type Repository interface {
+	List() ([]hash.Hash, error)
+	Retrieve(context hash.Hash) (Context, error)
+}
+
+// Service represents a context service
+type Service interface {
+	Insert(context Context) error
+	Write(context hash.Hash, namespace string, resource hash.Hash, value []byte) error
+	Remove(context hash.Hash, namespace string, resource hash.Hash) error
+	Read(context hash.Hash, namespace string, resource hash.Hash) error
+	Commit(context hash.Hash, commit hash.Hash) error
+	Savepoint(context hash.Hash, savepoint hash.Hash) error
+	RollbackTo(context hash.Hash, savepoint hash.Hash) error
+	Release(context hash.Hash, savepoint hash.Hash) error
+	Delete(context hash.Hash) error
+}
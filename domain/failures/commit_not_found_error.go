@@ -0,0 +1,27 @@
+package failures
+
+import (
+	"fmt"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+// CommitNotFoundError represents a commit that does not exists, either by its hash or by the context it was committed from
+type CommitNotFoundError struct {
+	Commit  hash.Hash
+	Context hash.Hash
+}
+
+// Error returns the error message
+func (obj *CommitNotFoundError) Error() string {
+	if len(obj.Context) > 0 {
+		return fmt.Sprintf("the context (hash: %s) does not point to a valid commit", obj.Context.String())
+	}
+
+	return fmt.Sprintf("there is no commit for the given hash: %s", obj.Commit.String())
+}
+
+// Is returns true if the target is ErrCommitNotFound
+func (obj *CommitNotFoundError) Is(target error) bool {
+	return target == ErrCommitNotFound
+}
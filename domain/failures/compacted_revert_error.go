@@ -0,0 +1,22 @@
+package failures
+
+import (
+	"fmt"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+// CompactedRevertError represents the revert of a compacted root, whose pointers cumulate the states dropped by a compaction
+type CompactedRevertError struct {
+	State hash.Hash
+}
+
+// Error returns the error message
+func (obj *CompactedRevertError) Error() string {
+	return fmt.Sprintf("the state (hash: %s) cannot be reverted, the states preceding it were dropped by a compaction", obj.State.String())
+}
+
+// Is returns true if the target is ErrCompactedRevert
+func (obj *CompactedRevertError) Is(target error) bool {
+	return target == ErrCompactedRevert
+}
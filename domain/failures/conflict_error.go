@@ -0,0 +1,25 @@
+package failures
+
+import (
+	"fmt"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+// ConflictError represents a commit that conflicts with a state pushed after its base state
+type ConflictError struct {
+	Commit    hash.Hash
+	State     hash.Hash
+	Namespace string
+	Resource  hash.Hash
+}
+
+// Error returns the error message
+func (obj *ConflictError) Error() string {
+	return fmt.Sprintf("the commit (hash: %s) conflicts with the state (hash: %s) on the resource (namespace: %s, hash: %s)", obj.Commit.String(), obj.State.String(), obj.Namespace, obj.Resource.String())
+}
+
+// Is returns true if the target is ErrConflict
+func (obj *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
@@ -0,0 +1,22 @@
+package failures
+
+import (
+	"fmt"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+// ContextNotFoundError represents a context that does not exists, or that is no longer open
+type ContextNotFoundError struct {
+	Context hash.Hash
+}
+
+// Error returns the error message
+func (obj *ContextNotFoundError) Error() string {
+	return fmt.Sprintf("the context (hash: %s) does not exists", obj.Context.String())
+}
+
+// Is returns true if the target is ErrContextNotFound
+func (obj *ContextNotFoundError) Is(target error) bool {
+	return target == ErrContextNotFound
+}
@@ -0,0 +1,16 @@
+package failures
+
+// CorruptDataError represents data that could not be decoded, along with the reason
+type CorruptDataError struct {
+	Reason string
+}
+
+// Error returns the error message
+func (obj *CorruptDataError) Error() string {
+	return obj.Reason
+}
+
+// Is returns true if the target is ErrCorruptData
+func (obj *CorruptDataError) Is(target error) bool {
+	return target == ErrCorruptData
+}
@@ -0,0 +1,31 @@
+package failures
+
+import (
+	"fmt"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+// HeadMismatchError represents a head state that differs from the one expected by a push, an empty hash represents an empty database
+type HeadMismatchError struct {
+	Expected hash.Hash
+	Current  hash.Hash
+}
+
+// Error returns the error message
+func (obj *HeadMismatchError) Error() string {
+	return fmt.Sprintf("the head state was expected to be %s, %s found", obj.name(obj.Expected), obj.name(obj.Current))
+}
+
+// Is returns true if the target is ErrHeadMismatch
+func (obj *HeadMismatchError) Is(target error) bool {
+	return target == ErrHeadMismatch
+}
+
+func (obj *HeadMismatchError) name(state hash.Hash) string {
+	if len(state) <= 0 {
+		return "(none)"
+	}
+
+	return state.String()
+}
@@ -0,0 +1,18 @@
+package failures
+
+import "fmt"
+
+// NamespaceNotFoundError represents a namespace that does not exists
+type NamespaceNotFoundError struct {
+	Namespace string
+}
+
+// Error returns the error message
+func (obj *NamespaceNotFoundError) Error() string {
+	return fmt.Sprintf("the namespace (name: %s) does not exists", obj.Namespace)
+}
+
+// Is returns true if the target is ErrNamespaceNotFound
+func (obj *NamespaceNotFoundError) Is(target error) bool {
+	return target == ErrNamespaceNotFound
+}
@@ -0,0 +1,28 @@
+package failures
+
+import (
+	"fmt"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+// ResourceNotFoundError represents a resource that does not exists, or that has been deleted
+type ResourceNotFoundError struct {
+	Namespace string
+	Resource  hash.Hash
+	IsDeleted bool
+}
+
+// Error returns the error message
+func (obj *ResourceNotFoundError) Error() string {
+	if obj.IsDeleted {
+		return fmt.Sprintf("the resource (namespace: %s, hash: %s) has been deleted", obj.Namespace, obj.Resource.String())
+	}
+
+	return fmt.Sprintf("the resource (namespace: %s, hash: %s) does not contain a matching pointer", obj.Namespace, obj.Resource.String())
+}
+
+// Is returns true if the target is ErrResourceNotFound
+func (obj *ResourceNotFoundError) Is(target error) bool {
+	return target == ErrResourceNotFound
+}
@@ -0,0 +1,36 @@
+package failures
+
+import "errors"
+
+// ErrContextNotFound represents a context that does not exists
+var ErrContextNotFound = errors.New("the context could not be found")
+
+// ErrCommitNotFound represents a commit that does not exists
+var ErrCommitNotFound = errors.New("the commit could not be found")
+
+// ErrStateNotFound represents a state that does not exists
+var ErrStateNotFound = errors.New("the state could not be found")
+
+// ErrResourceNotFound represents a resource that does not exists or has been deleted
+var ErrResourceNotFound = errors.New("the resource could not be found")
+
+// ErrNamespaceNotFound represents a namespace that does not exists
+var ErrNamespaceNotFound = errors.New("the namespace could not be found")
+
+// ErrCorruptData represents data that could not be decoded
+var ErrCorruptData = errors.New("the data is corrupt")
+
+// ErrHeadMismatch represents a head state that differs from the one expected by a push
+var ErrHeadMismatch = errors.New("the head state does not match the expected one")
+
+// ErrValidation represents a value that violates the schema of its namespace
+var ErrValidation = errors.New("the value violates the schema of its namespace")
+
+// ErrConflict represents a commit that conflicts with a state pushed after its base state
+var ErrConflict = errors.New("the commit conflicts with a pushed state")
+
+// ErrStaleState represents a state read before its database file was replaced
+var ErrStaleState = errors.New("the state was read before the database file was replaced")
+
+// ErrCompactedRevert represents the revert of a state whose previous states were dropped by a compaction
+var ErrCompactedRevert = errors.New("the state cannot be reverted, its previous states were dropped by a compaction")
@@ -0,0 +1,29 @@
+package failures
+
+import (
+	"fmt"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+// StaleStateError represents a state, or a pointer of a state, read before its database file was replaced by a compaction or an import
+type StaleStateError struct {
+	State     hash.Hash
+	Namespace string
+	Resource  hash.Hash
+	IsPointer bool
+}
+
+// Error returns the error message
+func (obj *StaleStateError) Error() string {
+	if obj.IsPointer {
+		return fmt.Sprintf("the pointer of the resource (namespace: %s, hash: %s) was read before the database file was replaced, the head state must be retrieved again", obj.Namespace, obj.Resource.String())
+	}
+
+	return fmt.Sprintf("the state (hash: %s) was read before the database file was replaced, the head state must be retrieved again", obj.State.String())
+}
+
+// Is returns true if the target is ErrStaleState
+func (obj *StaleStateError) Is(target error) bool {
+	return target == ErrStaleState
+}
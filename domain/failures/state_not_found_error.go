@@ -0,0 +1,31 @@
+package failures
+
+import (
+	"fmt"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+// StateNotFoundError represents a state that does not exists, or that was dropped by a compaction
+type StateNotFoundError struct {
+	State       hash.Hash
+	IsCompacted bool
+}
+
+// Error returns the error message
+func (obj *StateNotFoundError) Error() string {
+	if len(obj.State) <= 0 {
+		return "the database does not contain any state"
+	}
+
+	if obj.IsCompacted {
+		return fmt.Sprintf("the state (hash: %s) could not be found, the database was compacted, which drops the states preceding the snapshot of the last dropped state", obj.State.String())
+	}
+
+	return fmt.Sprintf("the state (hash: %s) could not be found", obj.State.String())
+}
+
+// Is returns true if the target is ErrStateNotFound
+func (obj *StateNotFoundError) Is(target error) bool {
+	return target == ErrStateNotFound
+}
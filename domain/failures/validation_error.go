@@ -0,0 +1,24 @@
+package failures
+
+import (
+	"fmt"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+// ValidationError represents a value that violates a rule of the schema of its namespace
+type ValidationError struct {
+	Namespace string
+	Resource  hash.Hash
+	Rule      string
+}
+
+// Error returns the error message
+func (obj *ValidationError) Error() string {
+	return fmt.Sprintf("the resource (namespace: %s, hash: %s) violates the rule (%s) of the schema of its namespace", obj.Namespace, obj.Resource.String(), obj.Rule)
+}
+
+// Is returns true if the target is ErrValidation
+func (obj *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
@@ -0,0 +1,81 @@
+package indexes
+
+import (
+	"errors"
+
+	"github.com/steve-care-software/database/domain/bytes"
+)
+
+type builder struct {
+	name      string
+	namespace string
+	path      []string
+	adapter   bytes.Adapter
+}
+
+func createBuilder() Builder {
+	out := builder{
+		name:      "",
+		namespace: "",
+		path:      nil,
+		adapter:   nil,
+	}
+
+	return &out
+}
+
+// Create initializes the builder
+func (app *builder) Create() Builder {
+	return createBuilder()
+}
+
+// WithName adds a name to the builder
+func (app *builder) WithName(name string) Builder {
+	app.name = name
+	return app
+}
+
+// WithNamespace adds a namespace to the builder
+func (app *builder) WithNamespace(namespace string) Builder {
+	app.namespace = namespace
+	return app
+}
+
+// WithPath adds a field path to the builder
+func (app *builder) WithPath(path []string) Builder {
+	app.path = path
+	return app
+}
+
+// WithAdapter adds an adapter to the builder
+func (app *builder) WithAdapter(adapter bytes.Adapter) Builder {
+	app.adapter = adapter
+	return app
+}
+
+// Now builds a new Index instance
+func (app *builder) Now() (Index, error) {
+	if app.name == "" {
+		return nil, errors.New("the name is mandatory in order to build an Index instance")
+	}
+
+	if app.namespace == "" {
+		return nil, errors.New("the namespace is mandatory in order to build an Index instance")
+	}
+
+	if len(app.path) <= 0 {
+		return nil, errors.New("the path is mandatory in order to build an Index instance")
+	}
+
+	for _, oneField := range app.path {
+		if oneField == "" {
+			return nil, errors.New("the path cannot contain an empty field in order to build an Index instance")
+		}
+	}
+
+	if app.adapter == nil {
+		return nil, errors.New("the adapter is mandatory in order to build an Index instance")
+	}
+
+	return createIndex(app.name, app.namespace, app.path, app.adapter), nil
+}
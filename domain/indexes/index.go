@@ -0,0 +1,122 @@
+package indexes
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/steve-care-software/database/domain/bytes"
+)
+
+type index struct {
+	name      string
+	namespace string
+	path      []string
+	adapter   bytes.Adapter
+}
+
+func createIndex(
+	name string,
+	namespace string,
+	path []string,
+	adapter bytes.Adapter,
+) Index {
+	out := index{
+		name:      name,
+		namespace: namespace,
+		path:      path,
+		adapter:   adapter,
+	}
+
+	return &out
+}
+
+// Name returns the name
+func (obj *index) Name() string {
+	return obj.name
+}
+
+// Namespace returns the namespace
+func (obj *index) Namespace() string {
+	return obj.namespace
+}
+
+// Path returns the field path
+func (obj *index) Path() []string {
+	return obj.path
+}
+
+// Adapter returns the adapter
+func (obj *index) Adapter() bytes.Adapter {
+	return obj.adapter
+}
+
+// Key decodes the value and returns the key of its indexed field, nil if the decoded value does not carry the path
+func (obj *index) Key(value []byte) (*string, error) {
+	ins, _, err := obj.adapter.ToInstance(value)
+	if err != nil {
+		return nil, err
+	}
+
+	current := reflect.ValueOf(ins)
+	for _, oneField := range obj.path {
+		current = obj.dereference(current)
+		if !current.IsValid() {
+			return nil, nil
+		}
+
+		current = obj.field(current, oneField)
+	}
+
+	current = obj.dereference(current)
+	if !current.IsValid() {
+		return nil, nil
+	}
+
+	out := key(current.Interface())
+	return &out, nil
+}
+
+// field returns the exported field, or the result of the parameterless method, of the passed name, an invalid value if there is none
+func (obj *index) field(current reflect.Value, name string) reflect.Value {
+	if current.Kind() == reflect.Struct {
+		if field, ok := current.Type().FieldByName(name); ok && field.PkgPath == "" {
+			return current.FieldByName(name)
+		}
+	}
+
+	method := current.MethodByName(name)
+	if !method.IsValid() && current.CanAddr() {
+		method = current.Addr().MethodByName(name)
+	}
+
+	if method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() > 0 {
+		return method.Call(nil)[0]
+	}
+
+	return reflect.Value{}
+}
+
+func (obj *index) dereference(current reflect.Value) reflect.Value {
+	for current.IsValid() && (current.Kind() == reflect.Ptr || current.Kind() == reflect.Interface) {
+		if current.IsNil() {
+			return reflect.Value{}
+		}
+
+		current = current.Elem()
+	}
+
+	return current
+}
+
+func key(field interface{}) string {
+	value := reflect.ValueOf(field)
+	for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("%s:%v", value.Type().String(), value.Interface())
+}
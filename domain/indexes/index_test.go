@@ -0,0 +1,108 @@
+package indexes
+
+import (
+	"testing"
+
+	"github.com/steve-care-software/database/domain/bytes"
+)
+
+type testStruct struct {
+	Name    string
+	Contact *testContactStruct
+}
+
+type testContactStruct struct {
+	Email string
+}
+
+// Domain returns the domain of the email
+func (obj testContactStruct) Domain() string {
+	for idx := len(obj.Email) - 1; idx >= 0; idx-- {
+		if obj.Email[idx] == '@' {
+			return obj.Email[idx+1:]
+		}
+	}
+
+	return ""
+}
+
+func newAdapterForTests() bytes.Adapter {
+	adapter, err := bytes.NewAdapterBuilder().Create().WithMapping(map[string]interface{}{
+		"github.com/steve-care-software/database/domain/indexes/testStruct":        testStruct{},
+		"github.com/steve-care-software/database/domain/indexes/testContactStruct": testContactStruct{},
+	}).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	return adapter
+}
+
+func TestIndex_Key_Success(t *testing.T) {
+	adapter := newAdapterForTests()
+	withContact, _ := adapter.ToBytes(testStruct{
+		Name: "roger",
+		Contact: &testContactStruct{
+			Email: "roger@example.com",
+		},
+	})
+
+	withoutContact, _ := adapter.ToBytes(testStruct{
+		Name: "anonymous",
+	})
+
+	byEmail, err := NewBuilder().Create().WithName("by_email").WithNamespace("users").WithPath([]string{"Contact", "Email"}).WithAdapter(adapter).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	key, err := byEmail.Key(withContact)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if key == nil || *key != NewKey("roger@example.com") {
+		t.Errorf("the key was expected to be the key of the email")
+		return
+	}
+
+	key, err = byEmail.Key(withoutContact)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if key != nil {
+		t.Errorf("the key was expected to be nil since the contact is nil")
+		return
+	}
+
+	byDomain, _ := NewBuilder().Create().WithName("by_domain").WithNamespace("users").WithPath([]string{"Contact", "Domain"}).WithAdapter(adapter).Now()
+	key, _ = byDomain.Key(withContact)
+	if key == nil || *key != NewKey("example.com") {
+		t.Errorf("the key was expected to be the key of the domain")
+		return
+	}
+
+	byInvalid, _ := NewBuilder().Create().WithName("by_invalid").WithNamespace("users").WithPath([]string{"Invalid"}).WithAdapter(adapter).Now()
+	key, _ = byInvalid.Key(withContact)
+	if key != nil {
+		t.Errorf("the key was expected to be nil since the field does not exist")
+		return
+	}
+
+	if NewKey("1") == NewKey(1) {
+		t.Errorf("the keys of values of different types were expected to differ")
+		return
+	}
+}
+
+func TestBuilder_withoutPath_returnsError(t *testing.T) {
+	_, err := NewBuilder().Create().WithName("by_email").WithNamespace("users").WithAdapter(newAdapterForTests()).Now()
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+}
@@ -0,0 +1,42 @@
+package indexes
+
+import (
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/bytes"
+)
+
+// NewBuilder creates a new index builder
+func NewBuilder() Builder {
+	return createBuilder()
+}
+
+// NewKey returns the key under which an exact field value is indexed
+func NewKey(field interface{}) string {
+	return key(field)
+}
+
+// Builder represents an index builder
+type Builder interface {
+	Create() Builder
+	WithName(name string) Builder
+	WithNamespace(namespace string) Builder
+	WithPath(path []string) Builder
+	WithAdapter(adapter bytes.Adapter) Builder
+	Now() (Index, error)
+}
+
+// Index represents a secondary index on a field of the decoded values of a namespace
+type Index interface {
+	Name() string
+	Namespace() string
+	Path() []string
+	Adapter() bytes.Adapter
+	Key(value []byte) (*string, error)
+}
+
+// Repository represents an index repository, the indexes reflecting the head state
+type Repository interface {
+	List() []Index
+	Retrieve(name string) (Index, error)
+	Find(name string, field interface{}) ([]hash.Hash, error)
+}
@@ -0,0 +1,32 @@
+package pointers
+
+import "github.com/steve-care-software/cryptography/domain/hash"
+
+// computeIdentity returns the hash of the pointers without the location of their resources, each pointer being
+// identified by its namespace, its resource and the digest of its data, so that the identity is kept when the resources
+// are relocated. A deletion has no location, and the pointers written before the digests existed cannot be identified
+// without their location, so both are identified by their hash.
+func computeIdentity(hashAdapter hash.Adapter, ptrs Pointers) (*hash.Hash, error) {
+	data := [][]byte{}
+	for _, onePointer := range ptrs.List() {
+		if onePointer.IsDeleted() || !onePointer.HasDigest() {
+			data = append(data, onePointer.Hash().Bytes())
+			continue
+		}
+
+		identity, err := hashAdapter.FromMultiBytes([][]byte{
+			onePointer.Resource().Bytes(),
+			[]byte(onePointer.Namespace()),
+			[]byte{digestFlag},
+			onePointer.Digest().Bytes(),
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, identity.Bytes())
+	}
+
+	return hashAdapter.FromMultiBytes(data)
+}
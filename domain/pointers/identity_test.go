@@ -0,0 +1,51 @@
+package pointers
+
+import (
+	"testing"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+func TestIdentity_isKeptWhenRelocated_Success(t *testing.T) {
+	resource, _ := hash.NewAdapter().FromBytes([]byte("this is a resource"))
+	digest, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	pointer, _ := NewPointerBuilder().Create().WithNamespace("my_namespace").WithResource(*resource).WithIndex(0).WithLength(7).WithDigest(*digest).Now()
+	relocated, _ := NewPointerBuilder().Create().WithNamespace("my_namespace").WithResource(*resource).WithIndex(45).WithLength(7).WithDigest(*digest).Now()
+	ptrs, _ := NewBuilder().Create().WithList([]Pointer{pointer}).Now()
+	relocatedPtrs, _ := NewBuilder().Create().WithList([]Pointer{relocated}).Now()
+	if ptrs.Hash().Compare(relocatedPtrs.Hash()) {
+		t.Errorf("the relocated pointers were not expected to have the hash of the pointers")
+		return
+	}
+
+	identity, err := NewIdentity(ptrs)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	relocatedIdentity, err := NewIdentity(relocatedPtrs)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !identity.Compare(*relocatedIdentity) {
+		t.Errorf("the relocated pointers were expected to keep the identity of the pointers")
+		return
+	}
+}
+
+func TestIdentity_withoutDigest_isHash_Success(t *testing.T) {
+	pointers, _ := NewPointersForTests()
+	identity, err := NewIdentity(pointers)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !identity.Compare(pointers.Hash()) {
+		t.Errorf("the identity was expected to be %s, %s returned", pointers.Hash().String(), identity.String())
+		return
+	}
+}
@@ -2,12 +2,16 @@ package pointers
 
 import "github.com/steve-care-software/cryptography/domain/hash"
 
+// the fields are encoded positionally, so the digest, added after the other fields, is appended, the pointers written
+// before it decoding without a digest:
 type pointer struct {
 	Hsh      hash.Hash
 	NmeSpace string
 	Res      hash.Hash
 	Idx      uint
 	Lgth     uint
+	IsDel    bool
+	Dgst     hash.Hash
 }
 
 func createPointer(
@@ -16,6 +20,37 @@ func createPointer(
 	resource hash.Hash,
 	index uint,
 	length uint,
+) Pointer {
+	return createPointerInternally(hash, namespace, resource, index, length, false, nil)
+}
+
+func createPointerWithDigest(
+	hash hash.Hash,
+	namespace string,
+	resource hash.Hash,
+	index uint,
+	length uint,
+	digest hash.Hash,
+) Pointer {
+	return createPointerInternally(hash, namespace, resource, index, length, false, digest)
+}
+
+func createPointerWithDeletion(
+	hash hash.Hash,
+	namespace string,
+	resource hash.Hash,
+) Pointer {
+	return createPointerInternally(hash, namespace, resource, 0, 0, true, nil)
+}
+
+func createPointerInternally(
+	hash hash.Hash,
+	namespace string,
+	resource hash.Hash,
+	index uint,
+	length uint,
+	isDeleted bool,
+	digest hash.Hash,
 ) Pointer {
 	out := pointer{
 		Hsh:      hash,
@@ -23,6 +58,8 @@ func createPointer(
 		Res:      resource,
 		Idx:      index,
 		Lgth:     length,
+		IsDel:    isDeleted,
+		Dgst:     digest,
 	}
 
 	return &out
@@ -52,3 +89,18 @@ func (obj *pointer) Index() uint {
 func (obj *pointer) Length() uint {
 	return obj.Lgth
 }
+
+// IsDeleted returns true if the pointer is a tombstone, false otherwise
+func (obj *pointer) IsDeleted() bool {
+	return obj.IsDel
+}
+
+// HasDigest returns true if there is a digest, false otherwise
+func (obj *pointer) HasDigest() bool {
+	return len(obj.Dgst) > 0
+}
+
+// Digest returns the digest of the resource data, if any
+func (obj *pointer) Digest() hash.Hash {
+	return obj.Dgst
+}
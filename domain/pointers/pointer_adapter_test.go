@@ -37,3 +37,62 @@ func TestPointerAdapter_Success(t *testing.T) {
 		return
 	}
 }
+
+func TestPointerAdapter_isDeleted_Success(t *testing.T) {
+	pointer := NewDeletedPointerForTests()
+	adapter, err := bytes.NewAdapterBuilder().Create().WithMapping(NewPointerMapping()).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	data, err := adapter.ToBytes(pointer)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	retPointer, _, err := adapter.ToInstance(data)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	casted := retPointer.(Pointer)
+	if !pointer.Hash().Compare(casted.Hash()) {
+		t.Errorf("the pointer hash was expected to be %s, %s returned", pointer.Hash().String(), casted.Hash().String())
+		return
+	}
+
+	if !casted.IsDeleted() {
+		t.Errorf("the pointer was expected to be deleted")
+		return
+	}
+}
+
+func TestPointerAdapter_withDigest_Success(t *testing.T) {
+	pointer := NewPointerWithDigestForTests()
+	adapter, err := bytes.NewAdapterBuilder().Create().WithMapping(NewPointerMapping()).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	data, err := adapter.ToBytes(pointer)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	retPointer, _, err := adapter.ToInstance(data)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	casted := retPointer.(Pointer)
+	if !casted.HasDigest() || !pointer.Digest().Compare(casted.Digest()) {
+		t.Errorf("the pointer digest was expected to be %s", pointer.Digest().String())
+		return
+	}
+}
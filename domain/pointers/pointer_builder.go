@@ -3,16 +3,27 @@ package pointers
 import (
 	"errors"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/steve-care-software/cryptography/domain/hash"
 )
 
+// deletionFlag ends the hashed data of a deletion.  Neither a valid UTF-8 namespace nor the digits of an index or
+// length contain it, so no Pointer can produce the hash of a deletion
+const deletionFlag = 0xff
+
+// digestFlag precedes the digest in the hashed data of a pointer.  Neither a valid UTF-8 namespace nor the digits of an
+// index or length contain it, so no Pointer without a digest or deletion can produce the hash of a Pointer with a digest
+const digestFlag = 0xfe
+
 type pointerBuilder struct {
 	hashAdapter hash.Adapter
 	namespace   string
 	resource    *hash.Hash
 	index       *uint
 	length      uint
+	digest      hash.Hash
+	isDeleted   bool
 }
 
 func createPointerBuilder(
@@ -24,6 +35,8 @@ func createPointerBuilder(
 		resource:    nil,
 		index:       nil,
 		length:      0,
+		digest:      nil,
+		isDeleted:   false,
 	}
 
 	return &out
@@ -60,16 +73,50 @@ func (app *pointerBuilder) WithLength(length uint) PointerBuilder {
 	return app
 }
 
+// WithDigest adds the digest of the resource data to the builder
+func (app *pointerBuilder) WithDigest(digest hash.Hash) PointerBuilder {
+	app.digest = digest
+	return app
+}
+
+// IsDeleted flags the builder as a deletion
+func (app *pointerBuilder) IsDeleted() PointerBuilder {
+	app.isDeleted = true
+	return app
+}
+
 // Now builds a new Pointer instance
 func (app *pointerBuilder) Now() (Pointer, error) {
 	if app.namespace == "" {
 		return nil, errors.New("the namespace is mandatory in order to build a Pointer instance")
 	}
 
+	if !utf8.ValidString(app.namespace) {
+		return nil, errors.New("the namespace must be a valid UTF-8 string in order to build a Pointer instance")
+	}
+
 	if app.resource == nil {
 		return nil, errors.New("the resource is mandatory in order to build a Pointer instance")
 	}
 
+	if app.isDeleted {
+		hash, err := app.hashAdapter.FromMultiBytes([][]byte{
+			app.resource.Bytes(),
+			[]byte(app.namespace),
+			[]byte{deletionFlag},
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return createPointerWithDeletion(
+			*hash,
+			app.namespace,
+			*app.resource,
+		), nil
+	}
+
 	if app.index == nil {
 		return nil, errors.New("the index is mandatory in order to build a Pointer instance")
 	}
@@ -78,17 +125,33 @@ func (app *pointerBuilder) Now() (Pointer, error) {
 		return nil, errors.New("the length must be greater than zero (0)in order to build a Pointer instance")
 	}
 
-	hash, err := app.hashAdapter.FromMultiBytes([][]byte{
+	data := [][]byte{
 		app.resource.Bytes(),
 		[]byte(app.namespace),
 		[]byte(strconv.Itoa(int(*app.index))),
 		[]byte(strconv.Itoa(int(app.length))),
-	})
+	}
 
+	if app.digest != nil {
+		data = append(data, []byte{digestFlag}, app.digest.Bytes())
+	}
+
+	hash, err := app.hashAdapter.FromMultiBytes(data)
 	if err != nil {
 		return nil, err
 	}
 
+	if app.digest != nil {
+		return createPointerWithDigest(
+			*hash,
+			app.namespace,
+			*app.resource,
+			*app.index,
+			app.length,
+			app.digest,
+		), nil
+	}
+
 	return createPointer(
 		*hash,
 		app.namespace,
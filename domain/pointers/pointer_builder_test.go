@@ -0,0 +1,54 @@
+package pointers
+
+import (
+	"testing"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+)
+
+func TestPointerBuilder_deletion_doesNotCollideWithPointers_Success(t *testing.T) {
+	resource, _ := hash.NewAdapter().FromBytes([]byte("this is a resource"))
+	deletion, err := NewPointerBuilder().Create().WithNamespace("my_namespace").WithResource(*resource).IsDeleted().Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	pointer, err := NewPointerBuilder().Create().WithNamespace("my_namespace").WithResource(*resource).WithIndex(0).WithLength(7).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if pointer.Hash().Compare(deletion.Hash()) {
+		t.Errorf("the pointer was not expected to have the hash of the deletion")
+		return
+	}
+
+	_, err = NewPointerBuilder().Create().WithNamespace(string([]byte{deletionFlag})).WithResource(*resource).IsDeleted().Now()
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+}
+
+func TestPointerBuilder_digest_isHashed_Success(t *testing.T) {
+	resource, _ := hash.NewAdapter().FromBytes([]byte("this is a resource"))
+	digest, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	pointer, _ := NewPointerBuilder().Create().WithNamespace("my_namespace").WithResource(*resource).WithIndex(0).WithLength(7).Now()
+	withDigest, err := NewPointerBuilder().Create().WithNamespace("my_namespace").WithResource(*resource).WithIndex(0).WithLength(7).WithDigest(*digest).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if pointer.HasDigest() || !withDigest.HasDigest() {
+		t.Errorf("only the pointer built with a digest was expected to contain one")
+		return
+	}
+
+	if pointer.Hash().Compare(withDigest.Hash()) {
+		t.Errorf("the digest was expected to be part of the pointer hash")
+		return
+	}
+}
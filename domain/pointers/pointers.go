@@ -1,10 +1,8 @@
 package pointers
 
 import (
-	"errors"
-	"fmt"
-
 	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/failures"
 )
 
 type pointers struct {
@@ -77,10 +75,13 @@ func (obj *pointers) Fetch(namespace string, resource hash.Hash) (Pointer, error
 			return ins, nil
 		}
 
-		str := fmt.Sprintf("the resource (namespace: %s, hash: %s) does not contain a matching pointer", namespace, keyname)
-		return nil, errors.New(str)
+		return nil, &failures.ResourceNotFoundError{
+			Namespace: namespace,
+			Resource:  resource,
+		}
 	}
 
-	str := fmt.Sprintf("the namespace (hash: %s) does not exists", namespace)
-	return nil, errors.New(str)
+	return nil, &failures.NamespaceNotFoundError{
+		Namespace: namespace,
+	}
 }
@@ -43,6 +43,23 @@ func NewPointerBuilder() PointerBuilder {
 	return createPointerBuilder(hashAdapter)
 }
 
+// NewEmpty returns pointers without any pointer, for a state whose resources were all deleted
+func NewEmpty() (Pointers, error) {
+	hashAdapter := hash.NewAdapter()
+	hash, err := hashAdapter.FromMultiBytes([][]byte{})
+	if err != nil {
+		return nil, err
+	}
+
+	return createPointers(*hash, []Pointer{}), nil
+}
+
+// NewIdentity returns the hash of the pointers without the location of their resources, which a compaction does not change
+func NewIdentity(ptrs Pointers) (*hash.Hash, error) {
+	hashAdapter := hash.NewAdapter()
+	return computeIdentity(hashAdapter, ptrs)
+}
+
 // Builder represents a pointers builder
 type Builder interface {
 	Create() Builder
@@ -65,6 +82,8 @@ type PointerBuilder interface {
 	WithResource(resource hash.Hash) PointerBuilder
 	WithIndex(index uint) PointerBuilder
 	WithLength(length uint) PointerBuilder
+	WithDigest(digest hash.Hash) PointerBuilder
+	IsDeleted() PointerBuilder
 	Now() (Pointer, error)
 }
 
@@ -75,4 +94,7 @@ type Pointer interface {
 	Resource() hash.Hash
 	Index() uint
 	Length() uint
+	IsDeleted() bool
+	HasDigest() bool
+	Digest() hash.Hash
 }
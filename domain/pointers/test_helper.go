@@ -46,3 +46,40 @@ func NewPointerForTests() Pointer {
 
 	return pointer
 }
+
+// NewDeletedPointerForTests creates a new deleted pointer for tests
+func NewDeletedPointerForTests() Pointer {
+	resource, err := hash.NewAdapter().FromBytes([]byte("this is some deleted resource"))
+	if err != nil {
+		panic(err)
+	}
+
+	namespace := "my_namespace"
+	pointer, err := NewPointerBuilder().Create().WithNamespace(namespace).WithResource(*resource).IsDeleted().Now()
+	if err != nil {
+		panic(err)
+	}
+
+	return pointer
+}
+
+// NewPointerWithDigestForTests creates a new pointer with a digest for tests
+func NewPointerWithDigestForTests() Pointer {
+	resource, err := hash.NewAdapter().FromBytes([]byte("this is some resource"))
+	if err != nil {
+		panic(err)
+	}
+
+	digest, err := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	if err != nil {
+		panic(err)
+	}
+
+	namespace := "my_namespace"
+	pointer, err := NewPointerBuilder().Create().WithNamespace(namespace).WithResource(*resource).WithIndex(0).WithLength(20).WithDigest(*digest).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	return pointer
+}
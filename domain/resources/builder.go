@@ -8,6 +8,7 @@ import (
 )
 
 type builder struct {
+	hashAdapter    hash.Adapter
 	pointerBuilder pointers.PointerBuilder
 	namespace      string
 	key            *hash.Hash
@@ -16,9 +17,11 @@ type builder struct {
 }
 
 func createBuilder(
+	hashAdapter hash.Adapter,
 	pointerBuilder pointers.PointerBuilder,
 ) Builder {
 	out := builder{
+		hashAdapter:    hashAdapter,
 		pointerBuilder: pointerBuilder,
 		namespace:      "",
 		key:            nil,
@@ -32,6 +35,7 @@ func createBuilder(
 // Create initializes the builder
 func (app *builder) Create() Builder {
 	return createBuilder(
+		app.hashAdapter,
 		app.pointerBuilder,
 	)
 }
@@ -78,8 +82,14 @@ func (app *builder) Now() (Resource, error) {
 		return nil, errors.New("the namespace is mandatory in order to build a Resource instance")
 	}
 
+	// the digest of the data makes the pointer, then the state, depend on the bytes of the resource:
+	digest, err := app.hashAdapter.FromBytes(app.data)
+	if err != nil {
+		return nil, err
+	}
+
 	length := uint(len(app.key.Bytes()) + len(app.data))
-	pointer, err := app.pointerBuilder.Create().WithNamespace(app.namespace).WithResource(*app.key).WithIndex(*app.index).WithLength(length).Now()
+	pointer, err := app.pointerBuilder.Create().WithNamespace(app.namespace).WithResource(*app.key).WithIndex(*app.index).WithLength(length).WithDigest(*digest).Now()
 	if err != nil {
 		return nil, err
 	}
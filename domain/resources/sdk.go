@@ -7,8 +7,9 @@ import (
 
 // NewBuilder creates a new resource builder
 func NewBuilder() Builder {
+	hashAdapter := hash.NewAdapter()
 	pointerBuilder := pointers.NewPointerBuilder()
-	return createBuilder(pointerBuilder)
+	return createBuilder(hashAdapter, pointerBuilder)
 }
 
 // Builder represents a resource builder
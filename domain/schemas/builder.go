@@ -0,0 +1,49 @@
+package schemas
+
+import (
+	"errors"
+)
+
+type builder struct {
+	namespace string
+	data      []byte
+}
+
+func createBuilder() Builder {
+	out := builder{
+		namespace: "",
+		data:      nil,
+	}
+
+	return &out
+}
+
+// Create initializes the builder
+func (app *builder) Create() Builder {
+	return createBuilder()
+}
+
+// WithNamespace adds a namespace to the builder
+func (app *builder) WithNamespace(namespace string) Builder {
+	app.namespace = namespace
+	return app
+}
+
+// WithData adds data to the builder
+func (app *builder) WithData(data []byte) Builder {
+	app.data = data
+	return app
+}
+
+// Now builds a new Schema instance
+func (app *builder) Now() (Schema, error) {
+	if app.namespace == "" {
+		return nil, errors.New("the namespace is mandatory in order to build a Schema instance")
+	}
+
+	if len(app.data) <= 0 {
+		return nil, errors.New("the data is mandatory in order to build a Schema instance")
+	}
+
+	return createSchema(app.namespace, app.data), nil
+}
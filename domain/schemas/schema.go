@@ -0,0 +1,28 @@
+package schemas
+
+type schema struct {
+	namespace string
+	data      []byte
+}
+
+func createSchema(
+	namespace string,
+	data []byte,
+) Schema {
+	out := schema{
+		namespace: namespace,
+		data:      data,
+	}
+
+	return &out
+}
+
+// Namespace returns the namespace
+func (obj *schema) Namespace() string {
+	return obj.namespace
+}
+
+// Data returns the data
+func (obj *schema) Data() []byte {
+	return obj.data
+}
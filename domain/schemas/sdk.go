@@ -0,0 +1,31 @@
+package schemas
+
+// NewBuilder creates a new schema builder
+func NewBuilder() Builder {
+	return createBuilder()
+}
+
+// Builder represents a schema builder
+type Builder interface {
+	Create() Builder
+	WithNamespace(namespace string) Builder
+	WithData(data []byte) Builder
+	Now() (Schema, error)
+}
+
+// Schema represents the schema the values of a namespace must respect
+type Schema interface {
+	Namespace() string
+	Data() []byte
+}
+
+// Repository represents a schema repository
+type Repository interface {
+	List() ([]Schema, error)
+}
+
+// Service represents a schema service
+type Service interface {
+	Insert(schema Schema) error
+	Delete(namespace string) error
+}
@@ -1,9 +1,16 @@
 package states
 
 import (
+	"errors"
+	"io/ioutil"
 	"testing"
+	"time"
 
+	"github.com/steve-care-software/cryptography/domain/hash"
 	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/pointers"
 )
 
 func TestAdapter_Success(t *testing.T) {
@@ -37,3 +44,217 @@ func TestAdapter_Success(t *testing.T) {
 		return
 	}
 }
+
+func TestAdapter_withCommits_Success(t *testing.T) {
+	metadata, err := commits.NewMetadataBuilder().Create().WithAuthor("roger").WithMessage("this is a message").Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	first := commits.NewCommitForTests(map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("this is the first element"),
+		},
+	})
+
+	second, err := commits.NewBuilder().Create().CreatedOn(time.Now().UTC()).WithMetadata(metadata).WithValues(map[string]map[string][]byte{}).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	ptrs, _ := pointers.NewPointersForTests()
+	state, err := NewBuilder().Create().CreatedOn(time.Now().UTC()).WithPointers(ptrs).WithCommits([]commits.Commit{
+		first,
+		second,
+	}).Now()
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	adapter, err := bytes.NewAdapterBuilder().Create().WithMapping(NewMapping()).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	data, err := adapter.ToBytes(state)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	retState, _, err := adapter.ToInstance(data)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	origins := retState.(State).Origins()
+	if len(origins) != 2 {
+		t.Errorf("%d origins were expected, %d returned", 2, len(origins))
+		return
+	}
+
+	if !origins[0].Commit().Compare(first.Hash()) || origins[0].HasMetadata() {
+		t.Errorf("the first origin was expected to be the first commit, without metadata")
+		return
+	}
+
+	if !origins[1].HasMetadata() || origins[1].Metadata().Author() != "roger" {
+		t.Errorf("the second origin was expected to contain the metadata of the second commit")
+		return
+	}
+}
+
+func TestAdapter_lazyPrevious_Success(t *testing.T) {
+	state := NewStateForTests(true)
+	adapter, err := bytes.NewAdapterBuilder().Create().WithMapping(NewMapping()).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// only the hash of the previous state is encoded:
+	data, _ := adapter.ToBytes(state)
+	expectedPrevious, _ := state.Previous()
+	previousData, _ := adapter.ToBytes(expectedPrevious)
+	retState, _, err := adapter.ToInstance(data)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	amount := 0
+	lazy, err := NewLazyState(retState.(State), func(previous hash.Hash) (State, error) {
+		amount++
+		ins, _, err := adapter.ToInstance(previousData)
+		if err != nil {
+			return nil, err
+		}
+
+		return ins.(State), nil
+	})
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if lazy.Height() != 2 || !lazy.HasPrevious() || amount != 0 {
+		t.Errorf("the previous state was not expected to be loaded")
+		return
+	}
+
+	root, err := lazy.Root()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !root.Hash().Compare(expectedPrevious.Hash()) {
+		t.Errorf("the root state was expected to be %s, %s returned", expectedPrevious.Hash().String(), root.Hash().String())
+		return
+	}
+
+	_, err = lazy.Fetch(expectedPrevious.Hash())
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if amount != 1 {
+		t.Errorf("the previous state was expected to be loaded once, %d loads", amount)
+		return
+	}
+}
+
+func TestAdapter_lazyPrevious_withoutPreviousFn_returnsError(t *testing.T) {
+	state := NewStateForTests(true)
+	adapter, _ := bytes.NewAdapterBuilder().Create().WithMapping(NewMapping()).Now()
+	data, _ := adapter.ToBytes(state)
+	retState, _, _ := adapter.ToInstance(data)
+	previous, _ := state.Previous()
+	_, err := retState.(State).Fetch(previous.Hash())
+	if !errors.Is(err, failures.ErrStateNotFound) {
+		t.Errorf("the error was expected to be a state not found error, %v returned", err)
+		return
+	}
+}
+
+func TestAdapter_lazyPrevious_withFailingPreviousFn_returnsError(t *testing.T) {
+	state := NewStateForTests(true)
+	adapter, _ := bytes.NewAdapterBuilder().Create().WithMapping(NewMapping()).Now()
+	data, _ := adapter.ToBytes(state)
+	retState, _, _ := adapter.ToInstance(data)
+	lazy, _ := NewLazyState(retState.(State), func(previous hash.Hash) (State, error) {
+		return nil, &failures.CorruptDataError{
+			Reason: "the previous state could not be read",
+		}
+	})
+
+	_, err := lazy.Previous()
+	if _, ok := err.(*failures.CorruptDataError); !ok {
+		t.Errorf("the error was expected to be a CorruptDataError, %v returned", err)
+		return
+	}
+
+	_, err = lazy.Root()
+	if _, ok := err.(*failures.CorruptDataError); !ok {
+		t.Errorf("the error was expected to be a CorruptDataError, %v returned", err)
+		return
+	}
+}
+
+func TestAdapter_withBaselineRecord_Success(t *testing.T) {
+	// the record was encoded by the baseline format, a state with two pointers preceding a state with one pointer:
+	data, err := ioutil.ReadFile("./testdata/baseline_state.bin")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	adapter, err := bytes.NewAdapterBuilder().Create().WithMapping(NewMapping()).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	ins, remaining, err := adapter.ToInstance(data)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(remaining) > 0 {
+		t.Errorf("the remaining []byte were expected to empty")
+		return
+	}
+
+	casted := ins.(State)
+	expected := "233013707c07519d6e920fdad177e210f05785b80c5f10022984b2575eb2dd9baf57b2befd5a2a9239e45646ad0db2501f9b7289b6799a31fe62e6b49d4652ed"
+	if casted.Hash().String() != expected {
+		t.Errorf("the state hash was expected to be %s, %s returned", expected, casted.Hash().String())
+		return
+	}
+
+	if casted.CreatedOn().Unix() != 1600000100 || casted.Height() != 2 || len(casted.Pointers().List()) != 1 || len(casted.Origins()) != 0 {
+		t.Errorf("the baseline state was expected to be decoded with its creation time, height and pointers")
+		return
+	}
+
+	previous, err := casted.Previous()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	expected = "cdbb1d926fe751cc2cb46bddf5990abc5964c2f6d5032d079f094cac07ced5146e8ad58bcccfe6c43ecd696351b0f4886ba0bfe362c151d1a073c0dfa8bae73c"
+	if previous == nil || previous.Hash().String() != expected || len(previous.Pointers().List()) != 2 || previous.HasPrevious() {
+		t.Errorf("the previous state of the baseline record was expected to be decoded")
+		return
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/steve-care-software/database/domain/commits"
 	"github.com/steve-care-software/database/domain/pointers"
 	"github.com/steve-care-software/cryptography/domain/hash"
 )
@@ -14,6 +15,7 @@ type builder struct {
 	ptrs        pointers.Pointers
 	createdOn   *time.Time
 	previous    State
+	commits     []commits.Commit
 }
 
 func createBuilder(
@@ -24,6 +26,7 @@ func createBuilder(
 		ptrs:        nil,
 		createdOn:   nil,
 		previous:    nil,
+		commits:     nil,
 	}
 
 	return &out
@@ -46,6 +49,12 @@ func (app *builder) WithPrevious(previous State) Builder {
 	return app
 }
 
+// WithCommits adds the commits the state originates from to the builder
+func (app *builder) WithCommits(commits []commits.Commit) Builder {
+	app.commits = commits
+	return app
+}
+
 // CreatedOn adds a creation time to the builder
 func (app *builder) CreatedOn(createdOn time.Time) Builder {
 	app.createdOn = &createdOn
@@ -62,23 +71,57 @@ func (app *builder) Now() (State, error) {
 		return nil, errors.New("the creation time is mandatory in order to build a State instance")
 	}
 
-	data := [][]byte{
-		app.ptrs.Hash().Bytes(),
-		[]byte(fmt.Sprintf("%d", app.createdOn.UnixNano())),
+	origins := []Origin{}
+	for _, oneCommit := range app.commits {
+		if oneCommit.HasMetadata() {
+			origins = append(origins, createOriginWithMetadata(oneCommit.Hash(), oneCommit.Metadata()))
+			continue
+		}
+
+		origins = append(origins, createOrigin(oneCommit.Hash()))
 	}
 
+	var previous hash.Hash
 	if app.previous != nil {
-		data = append(data, app.previous.Hash().Bytes())
+		previous = app.previous.Hash()
 	}
 
-	hash, err := app.hashAdapter.FromMultiBytes(data)
+	hash, err := computeHash(app.hashAdapter, app.ptrs, app.createdOn.UnixNano(), previous, origins)
 	if err != nil {
 		return nil, err
 	}
 
 	if app.previous != nil {
-		return createStateWithPrevious(*hash, app.ptrs, app.createdOn.UnixNano(), app.previous), nil
+		return createStateWithPrevious(*hash, app.ptrs, origins, app.createdOn.UnixNano(), app.previous), nil
+	}
+
+	return createState(*hash, app.ptrs, origins, app.createdOn.UnixNano()), nil
+}
+
+// computeHash computes the hash of a state from its pointers, its creation time, the hash of its previous state, nil for
+// a root, and its origins
+func computeHash(hashAdapter hash.Adapter, ptrs pointers.Pointers, createdOn int64, previous hash.Hash, origins []Origin) (*hash.Hash, error) {
+	// the identity of the pointers does not depend on the location of the resources, which a compaction changes:
+	identity, err := pointers.NewIdentity(ptrs)
+	if err != nil {
+		return nil, err
+	}
+
+	data := [][]byte{
+		identity.Bytes(),
+		[]byte(fmt.Sprintf("%d", createdOn)),
+	}
+
+	if previous != nil {
+		data = append(data, previous.Bytes())
+	}
+
+	for _, oneOrigin := range origins {
+		data = append(data, oneOrigin.Commit().Bytes())
+		if oneOrigin.HasMetadata() {
+			data = append(data, oneOrigin.Metadata().Hash().Bytes())
+		}
 	}
 
-	return createState(*hash, app.ptrs, app.createdOn.UnixNano()), nil
+	return hashAdapter.FromMultiBytes(data)
 }
@@ -0,0 +1,6 @@
+package states
+
+import "github.com/steve-care-software/database/domain/failures"
+
+// ConflictError represents a commit that conflicts with a state pushed after its base state
+type ConflictError = failures.ConflictError
@@ -0,0 +1,51 @@
+package states
+
+import (
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/commits"
+)
+
+type origin struct {
+	Cmt  hash.Hash
+	Meta commits.Metadata
+}
+
+func createOrigin(
+	commit hash.Hash,
+) Origin {
+	return createOriginInternally(commit, nil)
+}
+
+func createOriginWithMetadata(
+	commit hash.Hash,
+	metadata commits.Metadata,
+) Origin {
+	return createOriginInternally(commit, metadata)
+}
+
+func createOriginInternally(
+	commit hash.Hash,
+	metadata commits.Metadata,
+) Origin {
+	out := origin{
+		Cmt:  commit,
+		Meta: metadata,
+	}
+
+	return &out
+}
+
+// Commit returns the commit hash
+func (obj *origin) Commit() hash.Hash {
+	return obj.Cmt
+}
+
+// HasMetadata returns true if there is metadata, false otherwise
+func (obj *origin) HasMetadata() bool {
+	return obj.Meta != nil
+}
+
+// Metadata returns the metadata, if any
+func (obj *origin) Metadata() commits.Metadata {
+	return obj.Meta
+}
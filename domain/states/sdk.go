@@ -1,6 +1,7 @@
 package states
 
 import (
+	"errors"
 	"time"
 
 	"github.com/steve-care-software/database/domain/commits"
@@ -10,23 +11,100 @@ import (
 
 const dataLengthErrorPattern = "the remaining data length was expected to be bigger than %d bytes, %d provided"
 
-// SuccessCallBackFn represents a success func callback
-type SuccessCallBackFn func(ctx commits.Commit) error
+// SuccessCallBackFn represents a success func callback, with the state created from the commit
+type SuccessCallBackFn func(ctx commits.Commit, state State) error
 
 // FailCallBackFn represents a failed func callback
 type FailCallBackFn func(ctx commits.Commit, err error) error
 
+// SuccessAllCallBackFn represents a success func callback on multiple commits, with the state created from them
+type SuccessAllCallBackFn func(list []commits.Commit, state State) error
+
+// FailAllCallBackFn represents a failed func callback on multiple commits
+type FailAllCallBackFn func(list []commits.Commit, err error) error
+
+// PreviousFn represents a func that loads the previous state of a state, by hash
+type PreviousFn func(previous hash.Hash) (State, error)
+
+// NewLazyState makes a decoded state load its previous state on demand, using the passed func
+func NewLazyState(ins State, previousFn PreviousFn) (State, error) {
+	casted, ok := ins.(*state)
+	if !ok {
+		return nil, errors.New("the State must have been built or decoded by the states package in order to load its previous state on demand")
+	}
+
+	casted.previousFn = previousFn
+	return casted, nil
+}
+
+// PointerFn represents a func that resolves the pointer of a resource at a state without walking its chain, along with the
+// state that wrote it, nil if it is unknown, returning false when it cannot
+type PointerFn func(state State, namespace string, resource hash.Hash) (pointers.Pointer, State, bool, error)
+
+// NewIndexedState makes a decoded state resolve its pointers using the passed func before walking its chain
+func NewIndexedState(ins State, pointerFn PointerFn) (State, error) {
+	casted, ok := ins.(*state)
+	if !ok {
+		return nil, errors.New("the State must have been built or decoded by the states package in order to resolve its pointers using an index")
+	}
+
+	casted.pointerFn = pointerFn
+	return casted, nil
+}
+
+// NewRelocatedState returns a copy of a state whose resources were relocated by a compaction, its hash being computed
+// again from the relocated pointers, a nil previous state making it a root.  The hash does not depend on the location of
+// the resources, so it is only changed for the pointers written before the digests existed.  A snapshot keeps its hash.
+func NewRelocatedState(ins State, ptrs pointers.Pointers, previous State) (State, error) {
+	if ins.IsSnapshot() {
+		return createSnapshotState(ins, ptrs), nil
+	}
+
+	var previousHash hash.Hash
+	if previous != nil {
+		previousHash = previous.Hash()
+	}
+
+	hash, err := NewHash(ptrs, ins.CreatedOn(), previousHash, ins.Origins())
+	if err != nil {
+		return nil, err
+	}
+
+	return createRelocatedState(*hash, ins, ptrs, previous), nil
+}
+
+// NewSnapshotState returns the root written by a compaction in place of the last state it drops, holding the live pointers
+// at that state under its hash, so that the hash of its successor, which references it, is kept.  Since its pointers are
+// cumulated from the dropped states, its hash cannot be computed from them.
+func NewSnapshotState(ins State, ptrs pointers.Pointers) State {
+	return createSnapshotState(ins, ptrs)
+}
+
+// NewHash computes the hash of a state from its pointers, its creation time, the hash of its previous state, nil for a
+// root, and its origins
+func NewHash(ptrs pointers.Pointers, createdOn time.Time, previous hash.Hash, origins []Origin) (*hash.Hash, error) {
+	hashAdapter := hash.NewAdapter()
+	return computeHash(hashAdapter, ptrs, createdOn.UnixNano(), previous, origins)
+}
+
 // NewMapping returns the pointers conversion mapping
 func NewMapping() map[string]interface{} {
 	pointersMapping := pointers.NewMapping()
+	metadataMapping := commits.NewMetadataMapping()
 	mp := map[string]interface{}{
-		"github.com/steve-care-software/database/domain/states/state": new(state),
+		"github.com/steve-care-software/database/domain/states/state":  new(state),
+		"github.com/steve-care-software/database/domain/states/origin": new(origin),
+		"[]states.Origin": new(Origin),
 	}
 
 	for keyname, value := range pointersMapping {
 		mp[keyname] = value
 	}
 
+	for keyname, value := range metadataMapping {
+		mp[keyname] = value
+	}
+
 	return mp
 }
 
@@ -41,6 +119,7 @@ type Builder interface {
 	Create() Builder
 	WithPointers(ptrs pointers.Pointers) Builder
 	WithPrevious(previous State) Builder
+	WithCommits(commits []commits.Commit) Builder
 	CreatedOn(createdOn time.Time) Builder
 	Now() (State, error)
 }
@@ -49,13 +128,24 @@ type Builder interface {
 type State interface {
 	Hash() hash.Hash
 	Height() uint
-	Root() State
+	Root() (State, error)
 	Fetch(state hash.Hash) (State, error)
 	Pointer(namespace string, resource hash.Hash) (pointers.Pointer, error)
+	Writer(namespace string, resource hash.Hash) (State, error)
 	Pointers() pointers.Pointers
+	Origins() []Origin
 	CreatedOn() time.Time
+	IsCompacted() bool
+	IsSnapshot() bool
 	HasPrevious() bool
-	Previous() State
+	Previous() (State, error)
+}
+
+// Origin represents a commit a state originates from
+type Origin interface {
+	Commit() hash.Hash
+	HasMetadata() bool
+	Metadata() commits.Metadata
 }
 
 // Repository represents a state repository
@@ -66,4 +156,6 @@ type Repository interface {
 // Service represents a pointer service
 type Service interface {
 	Insert(commit commits.Commit, worked SuccessCallBackFn, failed FailCallBackFn) error
+	InsertOnto(commit commits.Commit, expectedHead hash.Hash, worked SuccessCallBackFn, failed FailCallBackFn) error
+	InsertAll(list []commits.Commit, worked SuccessAllCallBackFn, failed FailAllCallBackFn) error
 }
@@ -1,49 +1,114 @@
 package states
 
 import (
-	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/failures"
 	"github.com/steve-care-software/database/domain/pointers"
 )
 
+// the fields are encoded positionally, so the fields added after the baseline format (Hsh, Ptrs, CrOn, Prev) are
+// appended, the records written before them decoding with their zero value:
 type state struct {
 	Hsh  hash.Hash
 	Ptrs pointers.Pointers
 	CrOn int64
-	Prev State
+
+	// Prev is only decoded from the records that embedded their whole chain, the previous state is otherwise referenced by hash:
+	Prev       State
+	PrevHsh    hash.Hash
+	Hght       uint
+	Orgs       []Origin
+	Cmpct      bool
+	Snpsht     bool
+	mutex      sync.Mutex
+	previous   State
+	previousFn PreviousFn
+	pointerFn  PointerFn
 }
 
 func createState(
 	hash hash.Hash,
 	ptrs pointers.Pointers,
+	origins []Origin,
 	createdOn int64,
 ) State {
-	return createStateInternally(hash, ptrs, createdOn, nil)
+	return createStateInternally(hash, ptrs, origins, createdOn, nil)
 }
 
 func createStateWithPrevious(
 	hash hash.Hash,
 	ptrs pointers.Pointers,
+	origins []Origin,
 	createdOn int64,
 	previous State,
 ) State {
-	return createStateInternally(hash, ptrs, createdOn, previous)
+	return createStateInternally(hash, ptrs, origins, createdOn, previous)
 }
 
 func createStateInternally(
 	hash hash.Hash,
 	ptrs pointers.Pointers,
+	origins []Origin,
 	createdOn int64,
 	previous State,
 ) State {
 	out := state{
-		Hsh:  hash,
-		Ptrs: ptrs,
-		CrOn: createdOn,
-		Prev: previous,
+		Hsh:      hash,
+		Ptrs:     ptrs,
+		Orgs:     origins,
+		CrOn:     createdOn,
+		Hght:     1,
+		previous: previous,
+	}
+
+	if previous != nil {
+		out.PrevHsh = previous.Hash()
+		out.Hght = previous.Height() + 1
+	}
+
+	return &out
+}
+
+func createRelocatedState(
+	hash hash.Hash,
+	ins State,
+	ptrs pointers.Pointers,
+	previous State,
+) State {
+	return createRelocatedStateInternally(hash, ins, ptrs, previous, false)
+}
+
+func createSnapshotState(
+	ins State,
+	ptrs pointers.Pointers,
+) State {
+	return createRelocatedStateInternally(ins.Hash(), ins, ptrs, nil, true)
+}
+
+func createRelocatedStateInternally(
+	hash hash.Hash,
+	ins State,
+	ptrs pointers.Pointers,
+	previous State,
+	isSnapshot bool,
+) State {
+	out := state{
+		Hsh:      hash,
+		Ptrs:     ptrs,
+		Orgs:     ins.Origins(),
+		CrOn:     ins.CreatedOn().UnixNano(),
+		Hght:     ins.Height(),
+		Cmpct:    true,
+		Snpsht:   isSnapshot,
+		previous: previous,
+	}
+
+	if previous != nil {
+		out.PrevHsh = previous.Hash()
 	}
 
 	return &out
@@ -56,48 +121,119 @@ func (obj *state) Hash() hash.Hash {
 
 // Height returns the state height
 func (obj *state) Height() uint {
-	if obj.HasPrevious() {
-		return obj.Previous().Height() + 1
+	if obj.Hght > 0 {
+		return obj.Hght
+	}
+
+	// only the states that embed their whole chain are decoded without a height:
+	if obj.Prev != nil {
+		return obj.Prev.Height() + 1
 	}
 
 	return 1
 }
 
 // Root returns the root state
-func (obj *state) Root() State {
-	if !obj.HasPrevious() {
-		return obj
+func (obj *state) Root() (State, error) {
+	var current State = obj
+	for current.HasPrevious() {
+		previous, err := current.Previous()
+		if err != nil {
+			return nil, err
+		}
+
+		current = previous
 	}
 
-	return obj.Previous().Root()
+	return current, nil
 }
 
 // Fetch fetches a state by hash
 func (obj *state) Fetch(state hash.Hash) (State, error) {
-	if state.Compare(obj.Hash()) {
-		return obj, nil
-	}
+	var current State = obj
+	for {
+		if state.Compare(current.Hash()) {
+			return current, nil
+		}
+
+		if !current.HasPrevious() {
+			break
+		}
 
-	if obj.HasPrevious() {
-		return obj.Previous().Fetch(state)
+		previous, err := current.Previous()
+		if err != nil {
+			return nil, err
+		}
+
+		current = previous
 	}
 
-	str := fmt.Sprintf("the previous state (hash: %s) could not be found", state.String())
-	return nil, errors.New(str)
+	return nil, &failures.StateNotFoundError{
+		State:       state,
+		IsCompacted: current.IsCompacted(),
+	}
 }
 
-// Pointer fetches a pointer by hash
+// Pointer fetches a pointer by hash, using the pointer func when it can resolve it, walking the chain otherwise
 func (obj *state) Pointer(namespace string, resource hash.Hash) (pointers.Pointer, error) {
-	if obj.Ptrs.Exists(namespace, resource) {
-		return obj.Ptrs.Fetch(namespace, resource)
+	ptr, _, err := obj.lookup(namespace, resource, false)
+	return ptr, err
+}
+
+// Writer returns the state that wrote the pointer of a resource, using the pointer func when it can resolve it, walking the chain otherwise
+func (obj *state) Writer(namespace string, resource hash.Hash) (State, error) {
+	_, writer, err := obj.lookup(namespace, resource, true)
+	return writer, err
+}
+
+func (obj *state) lookup(namespace string, resource hash.Hash, withWriter bool) (pointers.Pointer, State, error) {
+	if obj.pointerFn != nil {
+		ptr, writer, isResolved, err := obj.pointerFn(obj, namespace, resource)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if isResolved && (!withWriter || writer != nil) {
+			return ptr, writer, nil
+		}
 	}
 
-	if obj.HasPrevious() {
-		return obj.Previous().Pointer(namespace, resource)
+	var current State = obj
+	for {
+		ptrs := current.Pointers()
+		if ptrs.Exists(namespace, resource) {
+			ptr, err := ptrs.Fetch(namespace, resource)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if ptr.IsDeleted() {
+				return nil, nil, &failures.ResourceNotFoundError{
+					Namespace: namespace,
+					Resource:  resource,
+					IsDeleted: true,
+				}
+			}
+
+			return ptr, current, nil
+		}
+
+		if !current.HasPrevious() {
+			break
+		}
+
+		previous, err := current.Previous()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		current = previous
 	}
 
-	str := fmt.Sprintf("the resource (namespace: %s, hash: %s) does not contain a matching pointer", namespace, resource.String())
-	return nil, errors.New(str)
+	return nil, nil, &failures.ResourceNotFoundError{
+		Namespace: namespace,
+		Resource:  resource,
+	}
 }
 
 // Pointers returns the pointers
@@ -105,17 +241,60 @@ func (obj *state) Pointers() pointers.Pointers {
 	return obj.Ptrs
 }
 
+// Origins returns the commits the state originates from
+func (obj *state) Origins() []Origin {
+	return obj.Orgs
+}
+
 // CreatedOn returns the creation time
 func (obj *state) CreatedOn() time.Time {
 	return time.Unix(0, obj.CrOn)
 }
 
+// IsCompacted returns true if the state was rewritten by a compaction, false otherwise
+func (obj *state) IsCompacted() bool {
+	return obj.Cmpct
+}
+
+// IsSnapshot returns true if the state is the root written by a compaction in place of the last dropped state, false otherwise
+func (obj *state) IsSnapshot() bool {
+	return obj.Snpsht
+}
+
 // HasPrevious returns true if there is a previous state, false otherwise
 func (obj *state) HasPrevious() bool {
-	return obj.Prev != nil
+	return obj.Prev != nil || obj.previous != nil || len(obj.PrevHsh) > 0
 }
 
-// Previous returns the previous state, if any
-func (obj *state) Previous() State {
-	return obj.Prev
+// Previous returns the previous state, loading it once using the previous func, nil if there is none
+func (obj *state) Previous() (State, error) {
+	if obj.Prev != nil {
+		return obj.Prev, nil
+	}
+
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+	if obj.previous != nil || len(obj.PrevHsh) <= 0 {
+		return obj.previous, nil
+	}
+
+	if obj.previousFn == nil {
+		return nil, &failures.StateNotFoundError{
+			State: obj.PrevHsh,
+		}
+	}
+
+	previous, err := obj.previousFn(obj.PrevHsh)
+	if err != nil {
+		return nil, err
+	}
+
+	if !previous.Hash().Compare(obj.PrevHsh) {
+		return nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the previous state (hash: %s) of the state (hash: %s) was expected, %s loaded", obj.PrevHsh.String(), obj.Hsh.String(), previous.Hash().String()),
+		}
+	}
+
+	obj.previous = previous
+	return previous, nil
 }
@@ -0,0 +1,104 @@
+package disks
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/steve-care-software/database/domain/failures"
+)
+
+// archiveMagic identifies an archive of an application database
+var archiveMagic = []byte("SCDBARCH")
+
+const (
+	// archiveVersion is the version of the archive format written by Export, the archives of the former versions remaining
+	// importable
+	archiveVersion = 2
+
+	// archiveHeaderSize is the size of the archive header: its magic, then its version
+	archiveHeaderSize = 8 + 8
+
+	// archiveSectionHeaderSize is the size of a section header: its kind, then the length of its data
+	archiveSectionHeaderSize = 1 + 8
+
+	// archiveRecordMaximumSize is the maximum size of a commit or a schema of an archive, since they are decoded in memory
+	archiveRecordMaximumSize = 256 * 1024 * 1024
+)
+
+const (
+	// archiveSectionEnd ends the sections, the checksum follows
+	archiveSectionEnd = iota
+
+	// archiveSectionDatabase contains the database file, from its first byte to the end of its valid data
+	archiveSectionDatabase
+
+	// archiveSectionCommit contains a pending commit file
+	archiveSectionCommit
+
+	// archiveSectionContext contains the hash of a context, then its journal file
+	archiveSectionContext
+
+	// archiveSectionSchema contains a schema file
+	archiveSectionSchema
+)
+
+// An archive is written as its header and the hash of the application, followed by the sections, then the checksum of
+// all the bytes that precede it:
+//
+//	[magic][version][application hash]
+//	[kind][length][data] ...
+//	[end kind][0]
+//	[checksum]
+func archiveHeader() []byte {
+	out := make([]byte, archiveHeaderSize)
+	copy(out, archiveMagic)
+	binary.LittleEndian.PutUint64(out[8:], archiveVersion)
+	return out
+}
+
+func archiveSectionHeader(kind uint8, length uint64) []byte {
+	out := make([]byte, archiveSectionHeaderSize)
+	out[0] = kind
+	binary.LittleEndian.PutUint64(out[1:], length)
+	return out
+}
+
+// readArchiveHeader reads and validates the archive header
+func readArchiveHeader(r io.Reader) error {
+	header := make([]byte, archiveHeaderSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the archive header could not be read: %s", err.Error()),
+		}
+	}
+
+	if string(header[:len(archiveMagic)]) != string(archiveMagic) {
+		return &failures.CorruptDataError{
+			Reason: "the data is not an archive of an application database",
+		}
+	}
+
+	version := binary.LittleEndian.Uint64(header[8:])
+	if version <= 0 || version > archiveVersion {
+		str := fmt.Sprintf("the archive version (%d) is not supported, the supported versions are 1 to %d", version, archiveVersion)
+		return errors.New(str)
+	}
+
+	return nil
+}
+
+// readArchiveSectionHeader reads a section header, returning its kind and the length of its data
+func readArchiveSectionHeader(r io.Reader) (uint8, uint64, error) {
+	header := make([]byte, archiveSectionHeaderSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return 0, 0, &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the archive section header could not be read: %s", err.Error()),
+		}
+	}
+
+	return header[0], binary.LittleEndian.Uint64(header[1:]), nil
+}
@@ -0,0 +1,787 @@
+package disks
+
+import (
+	std_bytes "bytes"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/contexts"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/pointers"
+	"github.com/steve-care-software/database/domain/schemas"
+	"github.com/steve-care-software/database/domain/states"
+)
+
+type archiver struct {
+	service          *stateService
+	commitRepository commits.Repository
+	commitAdapter    bytes.Adapter
+	contextAdapter   bytes.Adapter
+	schemaAdapter    bytes.Adapter
+	commitBuilder    commits.Builder
+	metadataBuilder  commits.MetadataBuilder
+	contextsBuilder  contexts.Builder
+	savepointBuilder contexts.SavepointBuilder
+	schemaBuilder    schemas.Builder
+	application      hash.Hash
+	commitDirPath    string
+	contextDirPath   string
+	schemaDirPath    string
+	databaseFilePath string
+	tmpExtension     string
+	staging          *staging
+}
+
+func createArchiver(
+	service *stateService,
+	commitRepository commits.Repository,
+	commitAdapter bytes.Adapter,
+	contextAdapter bytes.Adapter,
+	schemaAdapter bytes.Adapter,
+	commitBuilder commits.Builder,
+	metadataBuilder commits.MetadataBuilder,
+	contextsBuilder contexts.Builder,
+	savepointBuilder contexts.SavepointBuilder,
+	schemaBuilder schemas.Builder,
+	application hash.Hash,
+	commitDirPath string,
+	contextDirPath string,
+	schemaDirPath string,
+	databaseFilePath string,
+	tmpExtension string,
+	staging *staging,
+) Archiver {
+	out := archiver{
+		service:          service,
+		commitRepository: commitRepository,
+		commitAdapter:    commitAdapter,
+		contextAdapter:   contextAdapter,
+		schemaAdapter:    schemaAdapter,
+		commitBuilder:    commitBuilder,
+		metadataBuilder:  metadataBuilder,
+		contextsBuilder:  contextsBuilder,
+		savepointBuilder: savepointBuilder,
+		schemaBuilder:    schemaBuilder,
+		application:      application,
+		commitDirPath:    commitDirPath,
+		contextDirPath:   contextDirPath,
+		schemaDirPath:    schemaDirPath,
+		databaseFilePath: databaseFilePath,
+		tmpExtension:     tmpExtension,
+		staging:          staging,
+	}
+
+	return &out
+}
+
+// Export writes the archive of the application database: its state chain and resources, its pending commits, the
+// journals of its contexts, then its schemas
+func (app *archiver) Export(w io.Writer) error {
+	app.service.mutex.Lock()
+	defer app.service.mutex.Unlock()
+
+	checksum := sha512.New()
+	out := io.MultiWriter(w, checksum)
+	_, err := out.Write(append(archiveHeader(), app.application.Bytes()...))
+	if err != nil {
+		return err
+	}
+
+	// the database file is written up to the end of its valid data, so that an interrupted push is left out:
+	layout, err := readLayout(app.databaseFilePath)
+	if err != nil {
+		return err
+	}
+
+	if layout != nil {
+		file, err := os.Open(app.databaseFilePath)
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+		_, err = out.Write(archiveSectionHeader(archiveSectionDatabase, layout.end))
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(out, io.NewSectionReader(file, 0, int64(layout.end)))
+		if err != nil {
+			return err
+		}
+	}
+
+	list, err := app.commitRepository.List()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(list, func(i int, j int) bool {
+		return list[i].String() < list[j].String()
+	})
+
+	pending := map[string]bool{}
+	for _, oneCommit := range list {
+		data, err := ioutil.ReadFile(filepath.Join(app.commitDirPath, oneCommit.String()))
+		if err != nil {
+			return err
+		}
+
+		if len(data) > archiveRecordMaximumSize {
+			str := fmt.Sprintf("the commit (hash: %s, %d bytes) exceeds the maximum size (%d bytes) of the commits of an archive", oneCommit.String(), len(data), archiveRecordMaximumSize)
+			return errors.New(str)
+		}
+
+		_, err = out.Write(append(archiveSectionHeader(archiveSectionCommit, uint64(len(data))), data...))
+		if err != nil {
+			return err
+		}
+
+		pending[oneCommit.String()] = true
+	}
+
+	err = app.exportContexts(out, pending)
+	if err != nil {
+		return err
+	}
+
+	err = app.exportSchemas(out)
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(archiveSectionHeader(archiveSectionEnd, 0))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(checksum.Sum(nil))
+	return err
+}
+
+// exportContexts writes the journal of every context, sorted by hash, leaving out the journals whose commit is no longer
+// pending, since the push or the rollback that deleted the commit also deletes them
+func (app *archiver) exportContexts(out io.Writer, pending map[string]bool) error {
+	repository := createContextRepository(app.service.hashAdapter, app.contextAdapter, app.contextsBuilder, app.savepointBuilder, app.contextDirPath)
+	list, err := repository.List()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(list, func(i int, j int) bool {
+		return list[i].String() < list[j].String()
+	})
+
+	for _, oneContext := range list {
+		journal, err := repository.Retrieve(oneContext)
+		if err != nil {
+			return err
+		}
+
+		if journal.HasCommit() && !pending[journal.Commit().String()] {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(app.contextDirPath, oneContext.String()))
+		if err != nil {
+			return err
+		}
+
+		data = append(append([]byte{}, oneContext.Bytes()...), data...)
+		_, err = out.Write(append(archiveSectionHeader(archiveSectionContext, uint64(len(data))), data...))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportSchemas writes the file of every schema, sorted by name
+func (app *archiver) exportSchemas(out io.Writer) error {
+	files, err := ioutil.ReadDir(app.schemaDirPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		// a schema whose write was interrupted is left in its temporary file, and is ignored:
+		if file.IsDir() || filepath.Ext(file.Name()) == fmt.Sprintf(".%s", app.tmpExtension) {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(app.schemaDirPath, file.Name()))
+		if err != nil {
+			return err
+		}
+
+		_, err = out.Write(append(archiveSectionHeader(archiveSectionSchema, uint64(len(data))), data...))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Import replaces the application database, its pending commits, the journals of its contexts and its schemas by the
+// content of an archive.
+//
+// The archive is staged alongside the database, then its checksum, its state chain, its resources, its commits, its
+// journals and its schemas are verified.  The current data is only replaced once the whole archive is verified, an
+// activation interrupted by a crash being completed when the database is opened again.  The
+// applications built on the services load the journals and the schemas once, so they must be built again after an import.
+func (app *archiver) Import(r io.Reader) error {
+	checksum := sha512.New()
+	in := io.TeeReader(r, checksum)
+	err := readArchiveHeader(in)
+	if err != nil {
+		return err
+	}
+
+	application := make([]byte, hash.Size)
+	_, err = io.ReadFull(in, application)
+	if err != nil {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the application hash of the archive could not be read: %s", err.Error()),
+		}
+	}
+
+	if !hash.Hash(application).Compare(app.application) {
+		str := fmt.Sprintf("the archive of the application (hash: %s) cannot be imported in the application (hash: %s)", hash.Hash(application).String(), app.application.String())
+		return errors.New(str)
+	}
+
+	// an activation that failed is completed before another archive is staged:
+	err = app.complete()
+	if err != nil {
+		return err
+	}
+
+	defer app.staging.discard()
+	err = app.staging.prepare()
+	if err != nil {
+		return err
+	}
+
+	dbTmpPath := app.staging.path(stagedDatabaseName)
+	commitTmpPath := app.staging.path(stagedCommitDirName)
+	contextTmpPath := app.staging.path(stagedContextDirName)
+	hasDatabase, err := app.stage(in, dbTmpPath, commitTmpPath, contextTmpPath, app.staging.path(stagedSchemaDirName))
+	if err != nil {
+		return err
+	}
+
+	expected := checksum.Sum(nil)
+	stored := make([]byte, len(expected))
+	_, err = io.ReadFull(r, stored)
+	if err != nil {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the checksum of the archive could not be read: %s", err.Error()),
+		}
+	}
+
+	if !std_bytes.Equal(expected, stored) {
+		return &failures.CorruptDataError{
+			Reason: "the checksum of the archive does not match its content",
+		}
+	}
+
+	if hasDatabase {
+		err = app.verify(dbTmpPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	// an archive without a database replaces the database file by an empty one:
+	if !hasDatabase {
+		err = ioutil.WriteFile(dbTmpPath, []byte{}, 0777)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = app.verifyContexts(contextTmpPath, commitTmpPath)
+	if err != nil {
+		return err
+	}
+
+	return app.activate()
+}
+
+// stage writes the sections of the archive to the staged database file, commit, context and schema directories,
+// returning true if the archive contains a database
+func (app *archiver) stage(in io.Reader, dbTmpPath string, commitTmpPath string, contextTmpPath string, schemaTmpPath string) (bool, error) {
+	hasDatabase := false
+	for {
+		kind, length, err := readArchiveSectionHeader(in)
+		if err != nil {
+			return false, err
+		}
+
+		switch kind {
+		case archiveSectionEnd:
+			return hasDatabase, nil
+		case archiveSectionDatabase:
+			if hasDatabase {
+				return false, &failures.CorruptDataError{
+					Reason: "the archive contains more than one database",
+				}
+			}
+
+			err = app.stageFile(in, length, dbTmpPath, "database")
+			if err != nil {
+				return false, err
+			}
+
+			hasDatabase = true
+		case archiveSectionCommit:
+			err = app.stageCommit(in, length, commitTmpPath)
+			if err != nil {
+				return false, err
+			}
+		case archiveSectionContext:
+			err = app.stageContext(in, length, contextTmpPath)
+			if err != nil {
+				return false, err
+			}
+		case archiveSectionSchema:
+			err = app.stageSchema(in, length, schemaTmpPath)
+			if err != nil {
+				return false, err
+			}
+		default:
+			return false, &failures.CorruptDataError{
+				Reason: fmt.Sprintf("the archive section (kind: %d) is not supported", kind),
+			}
+		}
+	}
+}
+
+// stageFile streams the data of a section to the passed file
+func (app *archiver) stageFile(in io.Reader, length uint64, path string, name string) error {
+	file, err := os.OpenFile(path, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0777)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+	_, err = io.CopyN(file, in, int64(length))
+	if err != nil {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the %s of the archive (%d bytes) could not be read: %s", name, length, err.Error()),
+		}
+	}
+
+	return file.Sync()
+}
+
+// stageContext streams the journal of a context to the staged context directory, in a file named after its hash
+func (app *archiver) stageContext(in io.Reader, length uint64, contextTmpPath string) error {
+	if length <= hash.Size {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the context of the archive was expected to contain more than %d bytes, %d provided", hash.Size, length),
+		}
+	}
+
+	data := make([]byte, hash.Size)
+	_, err := io.ReadFull(in, data)
+	if err != nil {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the hash of the context of the archive could not be read: %s", err.Error()),
+		}
+	}
+
+	context, err := app.service.hashAdapter.FromBytes(data)
+	if err != nil {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the hash of the context of the archive is invalid: %s", err.Error()),
+		}
+	}
+
+	path := filepath.Join(contextTmpPath, context.String())
+	if _, err := os.Stat(path); err == nil {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the archive contains the context (hash: %s) more than once", context.String()),
+		}
+	}
+
+	return app.stageFile(in, length-hash.Size, path, "context")
+}
+
+// stageSchema decodes a schema and writes it to the staged schema directory, in a file named after the hash of its namespace
+func (app *archiver) stageSchema(in io.Reader, length uint64, schemaTmpPath string) error {
+	tmpPath := fmt.Sprintf("%s.%s", schemaTmpPath, app.tmpExtension)
+	data, err := app.stageRecord(in, length, tmpPath, "schema")
+	if err != nil {
+		return err
+	}
+
+	ins, remaining, err := app.schemaAdapter.ToInstance(data)
+	if err != nil || len(remaining) > 0 {
+		return &failures.CorruptDataError{
+			Reason: "the schema of the archive could not be decoded",
+		}
+	}
+
+	record, ok := ins.(schemaRecord)
+	if !ok {
+		return &failures.CorruptDataError{
+			Reason: "the schema of the archive could not be casted properly",
+		}
+	}
+
+	_, err = app.schemaBuilder.Create().WithNamespace(record.NmeSpace).WithData(record.Dat).Now()
+	if err != nil {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the schema of the namespace (%s) of the archive is invalid: %s", record.NmeSpace, err.Error()),
+		}
+	}
+
+	name, err := app.service.hashAdapter.FromBytes([]byte(record.NmeSpace))
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(schemaTmpPath, name.String())
+	if _, err := os.Stat(path); err == nil {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the archive contains the schema of the namespace (%s) more than once", record.NmeSpace),
+		}
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// stageCommit decodes and verifies a commit, then writes it to the staged commit directory, in a file named after its hash
+func (app *archiver) stageCommit(in io.Reader, length uint64, commitTmpPath string) error {
+	tmpPath := fmt.Sprintf("%s.%s", commitTmpPath, app.tmpExtension)
+	data, err := app.stageRecord(in, length, tmpPath, "commit")
+	if err != nil {
+		return err
+	}
+
+	ins, _, err := app.commitAdapter.ToInstance(data)
+	if err != nil {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the commit of the archive could not be decoded: %s", err.Error()),
+		}
+	}
+
+	commit, ok := ins.(commits.Commit)
+	if !ok {
+		return &failures.CorruptDataError{
+			Reason: "the commit of the archive could not be casted properly",
+		}
+	}
+
+	err = app.verifyCommit(commit)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, filepath.Join(commitTmpPath, commit.Hash().String()))
+}
+
+// stageRecord streams the data of a section to the passed temporary file, then reads it, so that the length read from
+// the archive is only allocated once the archive proved to contain that many bytes
+func (app *archiver) stageRecord(in io.Reader, length uint64, tmpPath string, name string) ([]byte, error) {
+	if length > archiveRecordMaximumSize {
+		return nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the %s of the archive (%d bytes) exceeds the maximum size (%d bytes)", name, length, archiveRecordMaximumSize),
+		}
+	}
+
+	err := app.stageFile(in, length, tmpPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadFile(tmpPath)
+}
+
+// verifyCommit rebuilds the commit from its content and makes sure it has the hash it was stored with
+func (app *archiver) verifyCommit(commit commits.Commit) error {
+	values := map[string]map[string][]byte{}
+	for _, oneValue := range commit.Values().List() {
+		namespace := oneValue.Namespace()
+		if _, ok := values[namespace]; !ok {
+			values[namespace] = map[string][]byte{}
+		}
+
+		var data []byte
+		if !oneValue.IsDeleted() {
+			data = oneValue.Data()
+		}
+
+		values[namespace][oneValue.Resource().String()] = data
+	}
+
+	reads := map[string][]hash.Hash{}
+	for _, oneRead := range commit.Reads() {
+		namespace := oneRead.Namespace()
+		reads[namespace] = append(reads[namespace], oneRead.Resource())
+	}
+
+	builder := app.commitBuilder.Create().WithValues(values).WithReads(reads).CreatedOn(commit.CreatedOn())
+	if commit.HasBase() {
+		builder.WithBase(commit.Base())
+	}
+
+	if commit.HasMetadata() {
+		rebuiltMetadata, err := app.rebuildMetadata(commit.Metadata())
+		if err != nil {
+			return err
+		}
+
+		builder.WithMetadata(rebuiltMetadata)
+	}
+
+	rebuilt, err := builder.Now()
+	if err != nil {
+		return err
+	}
+
+	if !rebuilt.Hash().Compare(commit.Hash()) {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the commit (hash: %s) of the archive does not match its content (hash: %s)", commit.Hash().String(), rebuilt.Hash().String()),
+		}
+	}
+
+	return nil
+}
+
+// rebuildMetadata builds the metadata again from its author, its message and its labels
+func (app *archiver) rebuildMetadata(metadata commits.Metadata) (commits.Metadata, error) {
+	labels := map[string]string{}
+	for _, oneLabel := range metadata.Labels() {
+		labels[oneLabel.Name()] = oneLabel.Value()
+	}
+
+	return app.metadataBuilder.Create().WithAuthor(metadata.Author()).WithMessage(metadata.Message()).WithLabels(labels).Now()
+}
+
+// verifyMetadata makes sure that the hash of the metadata of an origin matches its content
+func (app *archiver) verifyMetadata(metadata commits.Metadata) error {
+	rebuilt, err := app.rebuildMetadata(metadata)
+	if err != nil {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the metadata (hash: %s) of the archive is invalid: %s", metadata.Hash().String(), err.Error()),
+		}
+	}
+
+	if !rebuilt.Hash().Compare(metadata.Hash()) {
+		return &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the metadata (hash: %s) of the archive does not match its content (hash: %s)", metadata.Hash().String(), rebuilt.Hash().String()),
+		}
+	}
+
+	return nil
+}
+
+// verify walks the state chain of the staged database file, recomputing the hash of each state from its pointers, its
+// previous state and its origins, along with the hash of their metadata, except for the snapshot written by a compaction,
+// and reads every resource its pointers reference, the data of a resource being verified against its digest
+func (app *archiver) verify(dbTmpPath string) error {
+	layout, err := readLayout(dbTmpPath)
+	if err != nil {
+		return err
+	}
+
+	if layout == nil || !layout.hasState() {
+		return nil
+	}
+
+	// the pointer journal of the staged file is never written, since no pointer is resolved through it:
+	generation := createGeneration()
+	pointerStore := createPointerStore(app.service.pointerStore.journal.adapter, app.service.adapter, generation, dbTmpPath, fmt.Sprintf("%s.%s", dbTmpPath, pointerFileExtension), app.tmpExtension)
+	stateRepository := createStateRepository(app.service.adapter, pointerStore, generation, dbTmpPath)
+	resourceRepository := createResourceRepository(app.service.hashAdapter, app.service.resourceBuilder, generation, dbTmpPath)
+	head, _, err := stateRepository.Retrieve()
+	if err != nil {
+		return err
+	}
+
+	if head == nil {
+		return &failures.CorruptDataError{
+			Reason: "the head state of the archive could not be read",
+		}
+	}
+
+	for current := head; current != nil; {
+		ptrList := []pointers.Pointer{}
+		for _, onePointer := range current.Pointers().List() {
+			rebuilt, err := app.verifyPointer(onePointer)
+			if err != nil {
+				return err
+			}
+
+			if !onePointer.IsDeleted() {
+				_, err := resourceRepository.Retrieve(onePointer)
+				if err != nil {
+					return err
+				}
+			}
+
+			ptrList = append(ptrList, rebuilt)
+		}
+
+		// the pointers of a snapshot are cumulated from the dropped states, so its hash cannot be computed from them:
+		if current.IsSnapshot() {
+			if current.HasPrevious() {
+				return &failures.CorruptDataError{
+					Reason: fmt.Sprintf("the snapshot (hash: %s) of the archive was expected to be its root", current.Hash().String()),
+				}
+			}
+
+			break
+		}
+
+		ptrs, err := app.service.pointersBuilder.Create().WithList(ptrList).Now()
+		if err != nil {
+			return err
+		}
+
+		var previous states.State
+		var previousHash hash.Hash
+		if current.HasPrevious() {
+			previous, err = current.Previous()
+			if err != nil {
+				return err
+			}
+
+			previousHash = previous.Hash()
+		}
+
+		for _, oneOrigin := range current.Origins() {
+			if !oneOrigin.HasMetadata() {
+				continue
+			}
+
+			err := app.verifyMetadata(oneOrigin.Metadata())
+			if err != nil {
+				return err
+			}
+		}
+
+		computed, err := states.NewHash(ptrs, current.CreatedOn(), previousHash, current.Origins())
+		if err != nil {
+			return err
+		}
+
+		if !computed.Compare(current.Hash()) {
+			return &failures.CorruptDataError{
+				Reason: fmt.Sprintf("the state (hash: %s) of the archive does not match its content (hash: %s)", current.Hash().String(), computed.String()),
+			}
+		}
+
+		current = previous
+	}
+
+	return nil
+}
+
+// verifyPointer rebuilds a pointer of the staged database file, making sure that its hash matches its content
+func (app *archiver) verifyPointer(ptr pointers.Pointer) (pointers.Pointer, error) {
+	builder := app.service.pointerBuilder.Create().WithNamespace(ptr.Namespace()).WithResource(ptr.Resource())
+	if ptr.IsDeleted() {
+		builder.IsDeleted()
+	}
+
+	if !ptr.IsDeleted() {
+		builder.WithIndex(ptr.Index()).WithLength(ptr.Length())
+	}
+
+	if ptr.HasDigest() {
+		builder.WithDigest(ptr.Digest())
+	}
+
+	rebuilt, err := builder.Now()
+	if err != nil {
+		return nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the pointer (hash: %s) of the archive is invalid: %s", ptr.Hash().String(), err.Error()),
+		}
+	}
+
+	if !rebuilt.Hash().Compare(ptr.Hash()) {
+		return nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the pointer (hash: %s) of the archive does not match its content (hash: %s)", ptr.Hash().String(), rebuilt.Hash().String()),
+		}
+	}
+
+	return rebuilt, nil
+}
+
+// verifyContexts replays the staged journals, making sure that the commit of a journal is staged along with it
+func (app *archiver) verifyContexts(contextTmpPath string, commitTmpPath string) error {
+	repository := createContextRepository(app.service.hashAdapter, app.contextAdapter, app.contextsBuilder, app.savepointBuilder, contextTmpPath)
+	list, err := repository.List()
+	if err != nil {
+		return err
+	}
+
+	for _, oneContext := range list {
+		journal, err := repository.Retrieve(oneContext)
+		if err != nil {
+			if errors.Is(err, failures.ErrCorruptData) {
+				return err
+			}
+
+			return &failures.CorruptDataError{
+				Reason: fmt.Sprintf("the journal of the context (hash: %s) of the archive could not be replayed: %s", oneContext.String(), err.Error()),
+			}
+		}
+
+		if !journal.HasCommit() {
+			continue
+		}
+
+		_, err = os.Stat(filepath.Join(commitTmpPath, journal.Commit().String()))
+		if errors.Is(err, os.ErrNotExist) {
+			return &failures.CorruptDataError{
+				Reason: fmt.Sprintf("the context (hash: %s) of the archive references the commit (hash: %s), which the archive does not contain", oneContext.String(), journal.Commit().String()),
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// activate replaces the database file, the commit, context and schema directories by the staged ones
+func (app *archiver) activate() error {
+	app.service.mutex.Lock()
+	defer app.service.mutex.Unlock()
+
+	// the states read before the database file is replaced become stale:
+	return app.service.pointerStore.replace(app.staging.activate)
+}
+
+// complete completes an activation that failed, if any
+func (app *archiver) complete() error {
+	isPending, err := app.staging.isPending()
+	if err != nil || !isPending {
+		return err
+	}
+
+	app.service.mutex.Lock()
+	defer app.service.mutex.Unlock()
+	return app.service.pointerStore.replace(app.staging.complete)
+}
@@ -0,0 +1,479 @@
+package disks
+
+import (
+	std_bytes "bytes"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/contexts"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/schemas"
+)
+
+func TestArchiver_Success(t *testing.T) {
+	sourceDir := "./test_files/source"
+	targetDir := "./test_files/target"
+	defer func() {
+		os.RemoveAll("./test_files")
+	}()
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, err := NewBuilder(sourceDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	commitService := services.CommitService()
+	stateService := services.StateService()
+	archiver := services.Archiver()
+
+	insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+		"first":  []byte("first value"),
+		"second": []byte("second value"),
+	}))
+
+	insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+		"first": []byte("first value, updated"),
+	}))
+
+	metadata, _ := commits.NewMetadataBuilder().Create().WithAuthor("roger").WithMessage("pending").WithLabels(map[string]string{
+		"ticket": "42",
+	}).Now()
+
+	pending, _ := commits.NewBuilder().Create().WithValues(map[string]map[string][]byte{
+		"my_namespace": {
+			newCompactionResourceForTests("third").String(): []byte("third value"),
+		},
+	}).CreatedOn(time.Now().UTC()).WithMetadata(metadata).Now()
+
+	err = commitService.Insert(pending, func(ctx commits.Commit) error {
+		return nil
+	}, func(ctx commits.Commit, err error) error {
+		return err
+	})
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// the journal of a committed context, of an open context, and of a context whose commit is no longer pending:
+	contextService := services.ContextService()
+	committed, _ := hash.NewAdapter().FromBytes([]byte("this is a committed context"))
+	open, _ := hash.NewAdapter().FromBytes([]byte("this is an open context"))
+	dangling, _ := hash.NewAdapter().FromBytes([]byte("this is a dangling context"))
+	for _, oneContext := range []hash.Hash{*committed, *open, *dangling} {
+		journal, _ := contexts.NewBuilder().Create().WithHash(oneContext).CreatedOn(time.Now().UTC()).Now()
+		err = contextService.Insert(journal)
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+
+		contextService.Write(oneContext, "my_namespace", newCompactionResourceForTests("fourth"), []byte("fourth value"))
+	}
+
+	contextService.Commit(*committed, pending.Hash())
+	contextService.Commit(*dangling, newCompactionResourceForTests("pushed"))
+
+	schema, _ := schemas.NewBuilder().Create().WithNamespace("my_namespace").WithData([]byte("this is a schema")).Now()
+	err = services.SchemaService().Insert(schema)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	buffer := std_bytes.Buffer{}
+	err = archiver.Export(&buffer)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	archive := buffer.Bytes()
+	targetServices, _ := NewBuilder(targetDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	commitRepository := targetServices.CommitRepository()
+	resourceRepository := targetServices.ResourceRepository()
+	stateRepository := targetServices.StateRepository()
+	targetStateService := targetServices.StateService()
+	targetArchiver := targetServices.Archiver()
+	err = targetArchiver.Import(std_bytes.NewReader(archive))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	head, _, _ := stateRepository.Retrieve()
+	if head == nil || head.Height() != 2 {
+		t.Errorf("the imported head state was expected to be at the height %d", 2)
+		return
+	}
+
+	value, err := valueForTests(resourceRepository, head, "first")
+	if err != nil || value != "first value, updated" {
+		t.Errorf("the updated value was expected to be imported")
+		return
+	}
+
+	previous, _ := head.Previous()
+	value, err = valueForTests(resourceRepository, previous, "first")
+	if err != nil || value != "first value" {
+		t.Errorf("the history was expected to be imported")
+		return
+	}
+
+	retPending, err := commitRepository.Retrieve(pending.Hash())
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if author := retPending.Metadata().Author(); author != "roger" {
+		t.Errorf("the author (%s) of the pending commit was expected, %s returned", "roger", author)
+		return
+	}
+
+	contextRepository := targetServices.ContextRepository()
+	contextList, _ := contextRepository.List()
+	if len(contextList) != 2 {
+		t.Errorf("%d journals were expected to be imported, %d returned", 2, len(contextList))
+		return
+	}
+
+	retCommitted, err := contextRepository.Retrieve(*committed)
+	if err != nil || !retCommitted.HasCommit() || !retCommitted.Commit().Compare(pending.Hash()) {
+		t.Errorf("the journal of the committed context was expected to reference the pending commit")
+		return
+	}
+
+	retOpen, err := contextRepository.Retrieve(*open)
+	if err != nil || retOpen.HasCommit() || len(retOpen.Values()["my_namespace"]) != 1 {
+		t.Errorf("the journal of the open context was expected to be imported")
+		return
+	}
+
+	schemaList, _ := targetServices.SchemaRepository().List()
+	if len(schemaList) != 1 || schemaList[0].Namespace() != "my_namespace" || string(schemaList[0].Data()) != "this is a schema" {
+		t.Errorf("the schema was expected to be imported")
+		return
+	}
+
+	// the imported database remains usable:
+	insertCommitForTests(targetStateService, newCompactionCommitForTests(map[string][]byte{
+		"second": []byte("second value, updated"),
+	}))
+
+	newHead, _, _ := stateRepository.Retrieve()
+	value, err = valueForTests(resourceRepository, newHead, "first")
+	if err != nil || value != "first value, updated" || newHead.Height() != 3 {
+		t.Errorf("the imported database was expected to remain usable")
+		return
+	}
+
+	// a corrupted archive is rejected, and the current database is kept:
+	corrupted := append([]byte{}, archive...)
+	corrupted[len(corrupted)/2] ^= 0xff
+	err = targetArchiver.Import(std_bytes.NewReader(corrupted))
+	if !errors.Is(err, failures.ErrCorruptData) {
+		t.Errorf("the corrupted archive was expected to be rejected")
+		return
+	}
+
+	retHead, _, _ := stateRepository.Retrieve()
+	if !retHead.Hash().Compare(newHead.Hash()) {
+		t.Errorf("the current database was expected to be kept")
+		return
+	}
+
+	// a truncated archive is rejected:
+	err = targetArchiver.Import(std_bytes.NewReader(archive[:len(archive)-10]))
+	if !errors.Is(err, failures.ErrCorruptData) {
+		t.Errorf("the truncated archive was expected to be rejected")
+		return
+	}
+}
+
+func TestArchiver_anotherApplication_returnsError(t *testing.T) {
+	defer func() {
+		os.RemoveAll("./test_files")
+	}()
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, _ := NewBuilder("./test_files/source", "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	stateService := services.StateService()
+	archiver := services.Archiver()
+	insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+		"first": []byte("first value"),
+	}))
+
+	buffer := std_bytes.Buffer{}
+	err := archiver.Export(&buffer)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	another, _ := hash.NewAdapter().FromBytes([]byte("this is another application"))
+	targetServices, _ := NewBuilder("./test_files/target", "commits", "contexts", "database.db", "tmp").Create().WithApplication(*another).Now()
+	stateRepository := targetServices.StateRepository()
+	targetArchiver := targetServices.Archiver()
+	err = targetArchiver.Import(&buffer)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	head, _, _ := stateRepository.Retrieve()
+	if head != nil {
+		t.Errorf("the archive was not expected to be imported")
+		return
+	}
+}
+
+func TestArchiver_withCompactedDatabase_Success(t *testing.T) {
+	defer func() {
+		os.RemoveAll("./test_files")
+	}()
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, _ := NewBuilder("./test_files/source", "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	stateService := services.StateService()
+	for i := 1; i <= 3; i++ {
+		insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+			"overwritten": []byte(fmt.Sprintf("value %d", i)),
+		}))
+	}
+
+	_, err := services.Compactor().Compact(NewLastStatesRetention(1))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	buffer := std_bytes.Buffer{}
+	err = services.Archiver().Export(&buffer)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// the snapshot of the dropped states cannot be rehashed, so its pointers are verified against the resources only:
+	targetServices, _ := NewBuilder("./test_files/target", "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	err = targetServices.Archiver().Import(&buffer)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	head, _, _ := services.StateRepository().Retrieve()
+	retHead, _, _ := targetServices.StateRepository().Retrieve()
+	if !retHead.Hash().Compare(head.Hash()) {
+		t.Errorf("the head was expected to be %s, %s returned", head.Hash().String(), retHead.Hash().String())
+		return
+	}
+
+	root, _ := retHead.Root()
+	if !root.IsSnapshot() {
+		t.Errorf("the root was expected to be a snapshot")
+		return
+	}
+
+	value, err := valueForTests(targetServices.ResourceRepository(), root, "overwritten")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if value != "value 2" {
+		t.Errorf("the value was expected to be %s, %s returned", "value 2", value)
+		return
+	}
+}
+
+func TestArchiver_withInterruptedActivation_Success(t *testing.T) {
+	sourceDir := "./test_files/source"
+	targetDir := "./test_files/target"
+	defer func() {
+		os.RemoveAll("./test_files")
+	}()
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, _ := NewBuilder(sourceDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	insertCommitForTests(services.StateService(), newCompactionCommitForTests(map[string][]byte{
+		"first": []byte("first value"),
+	}))
+
+	pending := newCompactionCommitForTests(map[string][]byte{
+		"second": []byte("second value"),
+	})
+
+	services.CommitService().Insert(pending, func(ctx commits.Commit) error {
+		return nil
+	}, func(ctx commits.Commit, err error) error {
+		return err
+	})
+
+	sourceHead, _, _ := services.StateRepository().Retrieve()
+	targetServices, _ := NewBuilder(targetDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	insertCommitForTests(targetServices.StateService(), newCompactionCommitForTests(map[string][]byte{
+		"first": []byte("replaced value"),
+	}))
+
+	// stage the source database and commits, then simulate a crash once the commit directory was activated:
+	sourceAppDir := filepath.Join(sourceDir, application.String())
+	targetAppDir := filepath.Join(targetDir, application.String())
+	staging := createStaging(filepath.Join(targetAppDir, "database.db"), filepath.Join(targetAppDir, "commits"), filepath.Join(targetAppDir, "contexts"), filepath.Join(targetAppDir, schemaDirName), "tmp")
+	staging.prepare()
+	data, _ := ioutil.ReadFile(filepath.Join(sourceAppDir, "database.db"))
+	ioutil.WriteFile(staging.path(stagedDatabaseName), data, 0777)
+	data, _ = ioutil.ReadFile(filepath.Join(sourceAppDir, "commits", pending.Hash().String()))
+	ioutil.WriteFile(filepath.Join(staging.path(stagedCommitDirName), pending.Hash().String()), data, 0777)
+	ioutil.WriteFile(staging.markerPath, []byte{}, 0777)
+	os.RemoveAll(filepath.Join(targetAppDir, "commits"))
+	os.Rename(staging.path(stagedCommitDirName), filepath.Join(targetAppDir, "commits"))
+
+	// the activation is completed when the database is opened again:
+	recovered, err := NewBuilder(targetDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	head, _, err := recovered.StateRepository().Retrieve()
+	if err != nil || !head.Hash().Compare(sourceHead.Hash()) {
+		t.Errorf("the staged database was expected to be activated")
+		return
+	}
+
+	value, err := valueForTests(recovered.ResourceRepository(), head, "first")
+	if err != nil || value != "first value" {
+		t.Errorf("the value of the staged database was expected")
+		return
+	}
+
+	_, err = recovered.CommitRepository().Retrieve(pending.Hash())
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if _, err := os.Stat(staging.markerPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the marker was expected to be removed")
+		return
+	}
+
+	if _, err := os.Stat(staging.dirPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the staging directory was expected to be removed")
+		return
+	}
+}
+
+func TestArchiver_withOversizedCommit_returnsError(t *testing.T) {
+	defer func() {
+		os.RemoveAll("./test_files")
+	}()
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, _ := NewBuilder("./test_files", "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	archiver := services.Archiver()
+
+	// the length of a section is read from the archive, so it is never allocated before its bytes are read:
+	lengths := []uint64{
+		archiveRecordMaximumSize + 1,
+		archiveRecordMaximumSize,
+	}
+
+	for _, oneLength := range lengths {
+		archive := append(archiveHeader(), application.Bytes()...)
+		archive = append(archive, archiveSectionHeader(archiveSectionCommit, oneLength)...)
+		archive = append(archive, []byte("this is not a commit")...)
+		err := archiver.Import(std_bytes.NewReader(archive))
+		if !errors.Is(err, failures.ErrCorruptData) {
+			t.Errorf("the oversized commit (%d bytes) was expected to be rejected, %v returned", oneLength, err)
+			return
+		}
+	}
+}
+
+func TestArchiver_withTamperedPayload_returnsError(t *testing.T) {
+	sourceDir := "./test_files/source"
+	targetDir := "./test_files/target"
+	defer func() {
+		os.RemoveAll("./test_files")
+	}()
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, _ := NewBuilder(sourceDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	stateService := services.StateService()
+	insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+		"first": []byte("first value"),
+	}))
+
+	metadata, _ := commits.NewMetadataBuilder().Create().WithAuthor("roger").WithMessage("this is a message").Now()
+	updated, _ := commits.NewBuilder().Create().WithValues(map[string]map[string][]byte{
+		"my_namespace": {
+			newCompactionResourceForTests("first").String(): []byte("first value, updated"),
+		},
+	}).CreatedOn(time.Now().UTC()).WithMetadata(metadata).Now()
+
+	insertCommitForTests(stateService, updated)
+
+	buffer := std_bytes.Buffer{}
+	err := services.Archiver().Export(&buffer)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// the hashes of a state record are located by their encoding:
+	adapter, _ := bytes.NewAdapterBuilder().Create().WithMapping(newStateRecordMapping()).Now()
+	head, _, _ := services.StateRepository().Retrieve()
+	digest, _ := hash.NewAdapter().FromBytes([]byte("first value, updated"))
+	encodedDigest, _ := adapter.ToBytes(*digest)
+	encodedHash, _ := adapter.ToBytes(head.Hash())
+	payloads := map[string][]byte{
+		"resource data":   []byte("first value, updated"),
+		"resource digest": encodedDigest,
+		"state hash":      encodedHash,
+		"origin author":   []byte("roger"),
+	}
+
+	targetServices, _ := NewBuilder(targetDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	targetArchiver := targetServices.Archiver()
+	for name, onePayload := range payloads {
+		// the payload is tampered with, then the checksum of the archive is computed again:
+		archive := buffer.Bytes()
+		content := append([]byte{}, archive[:len(archive)-sha512.Size]...)
+		index := std_bytes.LastIndex(content, onePayload)
+		if index < 0 {
+			t.Errorf("the %s was expected in the archive", name)
+			return
+		}
+
+		// the last byte is flipped, so that the tampered record still decodes:
+		content[index+len(onePayload)-1] ^= 0xff
+		checksum := sha512.Sum512(content)
+		tampered := append(content, checksum[:]...)
+		err = targetArchiver.Import(std_bytes.NewReader(tampered))
+		if !errors.Is(err, failures.ErrCorruptData) {
+			t.Errorf("the archive whose %s was tampered with was expected to be rejected, %v returned", name, err)
+			return
+		}
+
+		retHead, _, _ := targetServices.StateRepository().Retrieve()
+		if retHead != nil {
+			t.Errorf("the archive whose %s was tampered with was not expected to be imported", name)
+			return
+		}
+	}
+}
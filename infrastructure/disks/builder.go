@@ -2,53 +2,91 @@ package disks
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 
 	"github.com/steve-care-software/database/domain/bytes"
 	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/contexts"
 	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/indexes"
 	"github.com/steve-care-software/database/domain/pointers"
 	"github.com/steve-care-software/database/domain/resources"
+	"github.com/steve-care-software/database/domain/schemas"
 	"github.com/steve-care-software/database/domain/states"
 )
 
 type builder struct {
-	hashAdapter     hash.Adapter
-	commitAdapter   bytes.Adapter
-	stateAdapter    bytes.Adapter
-	pointersBuilder pointers.Builder
-	resourceBuilder resources.Builder
-	statesBuilder   states.Builder
-	baseDir         string
-	commitDirPath   string
-	dbFileName      string
-	dbTmpExtension  string
-	application     *hash.Hash
+	hashAdapter      hash.Adapter
+	commitAdapter    bytes.Adapter
+	stateAdapter     bytes.Adapter
+	contextAdapter   bytes.Adapter
+	indexAdapter     bytes.Adapter
+	pointerAdapter   bytes.Adapter
+	schemaAdapter    bytes.Adapter
+	pointersBuilder  pointers.Builder
+	pointerBuilder   pointers.PointerBuilder
+	resourceBuilder  resources.Builder
+	statesBuilder    states.Builder
+	contextsBuilder  contexts.Builder
+	savepointBuilder contexts.SavepointBuilder
+	commitBuilder    commits.Builder
+	metadataBuilder  commits.MetadataBuilder
+	schemaBuilder    schemas.Builder
+	baseDir          string
+	commitDirPath    string
+	contextDirPath   string
+	dbFileName       string
+	dbTmpExtension   string
+	application      *hash.Hash
+	indexes          []indexes.Index
 }
 
 func createBuilder(
 	hashAdapter hash.Adapter,
 	commitAdapter bytes.Adapter,
 	stateAdapter bytes.Adapter,
+	contextAdapter bytes.Adapter,
+	indexAdapter bytes.Adapter,
+	pointerAdapter bytes.Adapter,
+	schemaAdapter bytes.Adapter,
 	pointersBuilder pointers.Builder,
+	pointerBuilder pointers.PointerBuilder,
 	resourceBuilder resources.Builder,
 	statesBuilder states.Builder,
+	contextsBuilder contexts.Builder,
+	savepointBuilder contexts.SavepointBuilder,
+	commitBuilder commits.Builder,
+	metadataBuilder commits.MetadataBuilder,
+	schemaBuilder schemas.Builder,
 	baseDir string,
 	commitDirPath string,
+	contextDirPath string,
 	dbFileName string,
 	dbTmpExtension string,
 ) Builder {
 	out := builder{
-		hashAdapter:     hashAdapter,
-		commitAdapter:   commitAdapter,
-		stateAdapter:    stateAdapter,
-		pointersBuilder: pointersBuilder,
-		resourceBuilder: resourceBuilder,
-		statesBuilder:   statesBuilder,
-		baseDir:         baseDir,
-		commitDirPath:   commitDirPath,
-		dbFileName:      dbFileName,
-		dbTmpExtension:  dbTmpExtension,
+		hashAdapter:      hashAdapter,
+		commitAdapter:    commitAdapter,
+		stateAdapter:     stateAdapter,
+		contextAdapter:   contextAdapter,
+		indexAdapter:     indexAdapter,
+		pointerAdapter:   pointerAdapter,
+		schemaAdapter:    schemaAdapter,
+		pointersBuilder:  pointersBuilder,
+		pointerBuilder:   pointerBuilder,
+		resourceBuilder:  resourceBuilder,
+		statesBuilder:    statesBuilder,
+		contextsBuilder:  contextsBuilder,
+		savepointBuilder: savepointBuilder,
+		commitBuilder:    commitBuilder,
+		metadataBuilder:  metadataBuilder,
+		schemaBuilder:    schemaBuilder,
+		baseDir:          baseDir,
+		commitDirPath:    commitDirPath,
+		contextDirPath:   contextDirPath,
+		dbFileName:       dbFileName,
+		dbTmpExtension:   dbTmpExtension,
 	}
 
 	return &out
@@ -60,11 +98,22 @@ func (app *builder) Create() Builder {
 		app.hashAdapter,
 		app.commitAdapter,
 		app.stateAdapter,
+		app.contextAdapter,
+		app.indexAdapter,
+		app.pointerAdapter,
+		app.schemaAdapter,
 		app.pointersBuilder,
+		app.pointerBuilder,
 		app.resourceBuilder,
 		app.statesBuilder,
+		app.contextsBuilder,
+		app.savepointBuilder,
+		app.commitBuilder,
+		app.metadataBuilder,
+		app.schemaBuilder,
 		app.baseDir,
 		app.commitDirPath,
+		app.contextDirPath,
 		app.dbFileName,
 		app.dbTmpExtension,
 	)
@@ -76,25 +125,63 @@ func (app *builder) WithApplication(application hash.Hash) Builder {
 	return app
 }
 
-// Now builds a new Application instance
-func (app *builder) Now() (commits.Repository, commits.Service, resources.Repository, states.Repository, states.Service, error) {
+// WithIndexes adds secondary indexes to the builder
+func (app *builder) WithIndexes(indexes []indexes.Index) Builder {
+	app.indexes = indexes
+	return app
+}
+
+// Now builds the repositories and services of an application database
+func (app *builder) Now() (Services, error) {
 	if app.application == nil {
-		return nil, nil, nil, nil, nil, errors.New("the application hash is mandatory in order to build an Application instance")
+		return nil, errors.New("the application hash is mandatory in order to build an Application instance")
 	}
 
 	applicationDir := app.application.String()
 	commitDirPath := filepath.Join(app.baseDir, applicationDir, app.commitDirPath)
+	contextDirPath := filepath.Join(app.baseDir, applicationDir, app.contextDirPath)
 	dbFilePath := filepath.Join(app.baseDir, applicationDir, app.dbFileName)
+	schemaDirPath := filepath.Join(app.baseDir, applicationDir, schemaDirName)
+
+	// an import whose activation was interrupted is completed before the database is read:
+	pointerFilePath := fmt.Sprintf("%s.%s", dbFilePath, pointerFileExtension)
+	staging := createStaging(dbFilePath, commitDirPath, contextDirPath, schemaDirPath, app.dbTmpExtension)
+	err := staging.recover([]string{
+		pointerFilePath,
+	})
+
+	if err != nil {
+		return nil, err
+	}
 
 	// disk repositories:
-	stateRepository := createStateRepository(app.stateAdapter, dbFilePath)
-	resourceRepository := createResourceRepository(app.hashAdapter, app.resourceBuilder, stateRepository, dbFilePath)
+	generation := createGeneration()
+	pointerStore := createPointerStore(app.pointerAdapter, app.stateAdapter, generation, dbFilePath, pointerFilePath, app.dbTmpExtension)
+	stateRepository := createStateRepository(app.stateAdapter, pointerStore, generation, dbFilePath)
+	resourceRepository := createResourceRepository(app.hashAdapter, app.resourceBuilder, generation, dbFilePath)
 	commitRepository := createCommitRepository(app.hashAdapter, app.commitAdapter, commitDirPath)
+	contextRepository := createContextRepository(app.hashAdapter, app.contextAdapter, app.contextsBuilder, app.savepointBuilder, contextDirPath)
+	schemaRepository := createSchemaRepository(app.schemaAdapter, app.schemaBuilder, schemaDirPath, app.dbTmpExtension)
+
+	// secondary indexes, journaled alongside the database file:
+	indexFilePath := fmt.Sprintf("%s.%s", dbFilePath, indexFileExtension)
+	indexStore, err := createIndexStore(app.indexAdapter, resourceRepository, stateRepository, app.indexes, indexFilePath, app.dbTmpExtension)
+	if err != nil {
+		return nil, err
+	}
+
+	indexRepository := createIndexRepository(indexStore)
 
 	// disk services:
 	commitService := createCommitService(app.commitAdapter, commitDirPath)
-	stateService := createStateService(app.hashAdapter, app.pointersBuilder, app.resourceBuilder, resourceRepository, app.statesBuilder, app.stateAdapter, stateRepository, dbFilePath, app.dbTmpExtension)
+	contextService := createContextService(app.hashAdapter, app.contextAdapter, contextDirPath)
+	schemaService := createSchemaService(app.hashAdapter, app.schemaAdapter, schemaDirPath, app.dbTmpExtension)
+	stateService := createStateService(app.hashAdapter, app.pointersBuilder, app.pointerBuilder, app.resourceBuilder, resourceRepository, indexStore, pointerStore, app.statesBuilder, app.stateAdapter, stateRepository, dbFilePath, app.dbTmpExtension)
+
+	// maintenance:
+	compactor := createCompactor(stateService)
+	archiver := createArchiver(stateService, commitRepository, app.commitAdapter, app.contextAdapter, app.schemaAdapter, app.commitBuilder, app.metadataBuilder, app.contextsBuilder, app.savepointBuilder, app.schemaBuilder, *app.application, commitDirPath, contextDirPath, schemaDirPath, dbFilePath, app.dbTmpExtension, staging)
 
 	// return the repositories and services:
-	return commitRepository, commitService, resourceRepository, stateRepository, stateService, nil
+	return createServices(commitRepository, commitService, contextRepository, contextService, resourceRepository, stateRepository, stateService, indexRepository, schemaRepository, schemaService, compactor, archiver), nil
 }
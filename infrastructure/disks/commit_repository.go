@@ -10,6 +10,7 @@ import (
 	"github.com/steve-care-software/database/domain/commits"
 	"github.com/steve-care-software/database/domain/bytes"
 	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/failures"
 )
 
 type commitRepository struct {
@@ -67,8 +68,9 @@ func (app *commitRepository) List() ([]hash.Hash, error) {
 func (app *commitRepository) Retrieve(hash hash.Hash) (commits.Commit, error) {
 	path := filepath.Join(app.baseDirPath, hash.String())
 	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
-		str := fmt.Sprintf("there is no commit for the given hash: %s", hash.String())
-		return nil, errors.New(str)
+		return nil, &failures.CommitNotFoundError{
+			Commit: hash,
+		}
 	}
 
 	bytes, err := ioutil.ReadFile(path)
@@ -85,6 +87,7 @@ func (app *commitRepository) Retrieve(hash hash.Hash) (commits.Commit, error) {
 		return casted, nil
 	}
 
-	str := fmt.Sprintf("the retrieved commit (hash: %s) could not be casted properly", hash.String())
-	return nil, errors.New(str)
+	return nil, &failures.CorruptDataError{
+		Reason: fmt.Sprintf("the retrieved commit (hash: %s) could not be casted properly", hash.String()),
+	}
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/steve-care-software/database/domain/bytes"
 	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/failures"
 )
 
 type commitService struct {
@@ -61,6 +62,12 @@ func (app *commitService) Insert(commit commits.Commit, worked commits.SuccessCa
 func (app *commitService) Delete(commit commits.Commit, worked commits.SuccessCallBackFn, failed commits.FailCallBackFn) error {
 	path := filepath.Join(app.baseDirPath, commit.Hash().String())
 	bytes, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return failed(commit, &failures.CommitNotFoundError{
+			Commit: commit.Hash(),
+		})
+	}
+
 	if err != nil {
 		return failed(commit, err)
 	}
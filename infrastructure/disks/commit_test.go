@@ -11,6 +11,7 @@ import (
 func TestCommit_Success(t *testing.T) {
 	baseDir := "./test_files"
 	commitDirPath := "commits"
+	contextDirPath := "contexts"
 	dbFileName := "database.db"
 	dbTmpExtension := ".tmp"
 	defer func() {
@@ -30,11 +31,14 @@ func TestCommit_Success(t *testing.T) {
 		},
 	})
 
-	commitRepository, commitService, _, _, _, err := NewBuilder(baseDir, commitDirPath, dbFileName, dbTmpExtension).Create().WithApplication(*application).Now()
+	services, err := NewBuilder(baseDir, commitDirPath, contextDirPath, dbFileName, dbTmpExtension).Create().WithApplication(*application).Now()
 	if err != nil {
 		panic(err)
 	}
 
+	commitRepository := services.CommitRepository()
+	commitService := services.CommitService()
+
 	err = commitService.Insert(
 		commit,
 		func(ctx commits.Commit) error {
@@ -0,0 +1,53 @@
+package disks
+
+type compaction struct {
+	retained uint
+	dropped  uint
+	before   uint
+	after    uint
+}
+
+func createCompaction(
+	retained uint,
+	dropped uint,
+	before uint,
+	after uint,
+) Compaction {
+	out := compaction{
+		retained: retained,
+		dropped:  dropped,
+		before:   before,
+		after:    after,
+	}
+
+	return &out
+}
+
+// Retained returns the amount of retained states
+func (obj *compaction) Retained() uint {
+	return obj.retained
+}
+
+// Dropped returns the amount of dropped states
+func (obj *compaction) Dropped() uint {
+	return obj.dropped
+}
+
+// Before returns the size of the database, in bytes, before the compaction
+func (obj *compaction) Before() uint {
+	return obj.before
+}
+
+// After returns the size of the database, in bytes, after the compaction
+func (obj *compaction) After() uint {
+	return obj.after
+}
+
+// Reclaimed returns the amount of reclaimed bytes
+func (obj *compaction) Reclaimed() uint {
+	if obj.after >= obj.before {
+		return 0
+	}
+
+	return obj.before - obj.after
+}
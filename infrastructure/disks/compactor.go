@@ -0,0 +1,299 @@
+package disks
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/pointers"
+	"github.com/steve-care-software/database/domain/states"
+)
+
+type compactor struct {
+	service *stateService
+}
+
+func createCompactor(
+	service *stateService,
+) Compactor {
+	out := compactor{
+		service: service,
+	}
+
+	return &out
+}
+
+// Compact rewrites the database file, keeping only the resources reachable from the states retained by the policy.
+//
+// The last dropped state is replaced by a snapshot, which becomes the root and holds the live pointers at that state,
+// under its hash.  The resources are relocated, so the pointers of the retained states are rebuilt along with the digest
+// of their data, and the hashes of the retained states are computed again from them.  A hash does not depend on the
+// location of the resources, so the retained states keep their hash, and the commits based on them, or on the last
+// dropped state, are still accepted.  Only the states written before the digests existed receive a new hash.
+func (app *compactor) Compact(retention RetentionFn) (Compaction, error) {
+	service := app.service
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	current, err := readLayout(service.databaseFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		return createCompaction(0, 0, 0, 0), nil
+	}
+
+	before := uint(current.end)
+	if current.isLegacy {
+		current, err = service.migrate(current)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	head, _, err := service.repository.Retrieve()
+	if err != nil {
+		return nil, err
+	}
+
+	if head == nil {
+		return createCompaction(0, 0, before, before), nil
+	}
+
+	// the head is always retained, then its predecessors while the policy retains them:
+	chain := []states.State{
+		head,
+	}
+
+	for depth := uint(1); chain[len(chain)-1].HasPrevious(); depth++ {
+		previous, err := chain[len(chain)-1].Previous()
+		if err != nil {
+			return nil, err
+		}
+
+		if !retention(previous, depth) {
+			break
+		}
+
+		chain = append(chain, previous)
+	}
+
+	// the last dropped state is replaced by a snapshot of the live pointers at its height:
+	retained := uint(len(chain))
+	dropped := uint(0)
+	hasSnapshot := false
+	snapshotList := []pointers.Pointer{}
+	if oldest := chain[len(chain)-1]; oldest.HasPrevious() {
+		last, err := oldest.Previous()
+		if err != nil {
+			return nil, err
+		}
+
+		list, amount, err := app.cumulate(last)
+		if err != nil {
+			return nil, err
+		}
+
+		chain = append(chain, last)
+		hasSnapshot = true
+		snapshotList = list
+		dropped = amount + 1
+	}
+
+	fin, err := os.Open(service.databaseFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fin.Close()
+
+	resTmpPath := fmt.Sprintf("%s.%s", service.databaseFilePath, service.tmpExtension)
+	fout, err := os.OpenFile(resTmpPath, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0777)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fout.Close()
+	defer func() {
+		os.Remove(resTmpPath)
+	}()
+
+	// the indexes of the compacted file start after those of the file it replaces:
+	compacted := newLayout(uint64(current.nextIndex()))
+	_, err = fout.Write(compacted.superblock())
+	if err != nil {
+		return nil, err
+	}
+
+	// rewrite the snapshot, then the retained states, oldest first, each followed by its record:
+	var previous states.State
+	for idx := len(chain) - 1; idx >= 0; idx-- {
+		isSnapshot := hasSnapshot && idx == len(chain)-1
+		list := chain[idx].Pointers().List()
+		if isSnapshot {
+			list = snapshotList
+		}
+
+		data := []byte{}
+		relocated := []pointers.Pointer{}
+		for _, onePointer := range list {
+			if onePointer.IsDeleted() {
+				relocated = append(relocated, onePointer)
+				continue
+			}
+
+			offset, isCurrent := current.offset(onePointer.Index())
+			if !isCurrent {
+				return nil, &failures.CorruptDataError{
+					Reason: fmt.Sprintf("the resource (hash: %s) of the state (hash: %s) is located at the index %d, before the first index (%d) of the database file", onePointer.Resource().String(), chain[idx].Hash().String(), onePointer.Index(), current.origin),
+				}
+			}
+
+			if onePointer.Length() <= hash.Size {
+				return nil, &failures.CorruptDataError{
+					Reason: fmt.Sprintf(dataLengthErrorPattern, hash.Size, onePointer.Length()),
+				}
+			}
+
+			resData := make([]byte, onePointer.Length())
+			_, err = fin.ReadAt(resData, int64(offset))
+			if err != nil {
+				return nil, err
+			}
+
+			// the pointers written before the digests existed receive the digest of their data:
+			digest, err := service.hashAdapter.FromBytes(resData[hash.Size:])
+			if err != nil {
+				return nil, err
+			}
+
+			if onePointer.HasDigest() && !onePointer.Digest().Compare(*digest) {
+				return nil, &failures.CorruptDataError{
+					Reason: fmt.Sprintf("the data of the resource (hash: %s) of the state (hash: %s) does not match its digest (%s)", onePointer.Resource().String(), chain[idx].Hash().String(), onePointer.Digest().String()),
+				}
+			}
+
+			index := compacted.nextIndex() + uint(len(data))
+			ptr, err := service.pointerBuilder.Create().WithNamespace(onePointer.Namespace()).WithResource(onePointer.Resource()).WithIndex(index).WithLength(onePointer.Length()).WithDigest(*digest).Now()
+			if err != nil {
+				return nil, err
+			}
+
+			data = append(data, resData...)
+			relocated = append(relocated, ptr)
+		}
+
+		var state states.State
+		if isSnapshot {
+			// the resources of the dropped states may all be deleted:
+			ptrs, err := pointers.NewEmpty()
+			if len(relocated) > 0 {
+				ptrs, err = service.pointersBuilder.Create().WithList(relocated).Now()
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			state = states.NewSnapshotState(chain[idx], ptrs)
+		}
+
+		if !isSnapshot {
+			ptrs, err := service.pointersBuilder.Create().WithList(relocated).Now()
+			if err != nil {
+				return nil, err
+			}
+
+			state, err = states.NewRelocatedState(chain[idx], ptrs, previous)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		record := stateRecord{
+			Stte: state,
+		}
+
+		if previous != nil {
+			record.PrevOff = compacted.stateOffset
+			record.PrevSze = compacted.stateSize
+		}
+
+		stateBytes, err := service.adapter.ToBytes(record)
+		if err != nil {
+			return nil, err
+		}
+
+		compacted.stateOffset = compacted.end + uint64(len(data))
+		compacted.stateSize = uint64(len(stateBytes))
+		data = append(data, stateBytes...)
+		_, err = fout.WriteAt(data, int64(compacted.end))
+		if err != nil {
+			return nil, err
+		}
+
+		compacted.end += uint64(len(data))
+		previous = state
+	}
+
+	_, err = fout.WriteAt(compacted.superblock(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fout.Sync()
+	if err != nil {
+		return nil, err
+	}
+
+	// rename and replace the tmp database file for the real database file, once no read of the database file is in
+	// progress, the states read before becoming stale:
+	err = service.pointerStore.replace(func() error {
+		return os.Rename(resTmpPath, service.databaseFilePath)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	after := uint(compacted.end)
+	return createCompaction(retained, dropped, before, after), nil
+}
+
+// cumulate returns the latest pointer of every live resource at the passed state, along with the amount of its predecessors
+func (app *compactor) cumulate(state states.State) ([]pointers.Pointer, uint, error) {
+	out := []pointers.Pointer{}
+	keys := map[string]bool{}
+	amount := uint(0)
+	for current := state; ; {
+		for _, onePointer := range current.Pointers().List() {
+			keyname := fmt.Sprintf("%s/%s", onePointer.Namespace(), onePointer.Resource().String())
+			if keys[keyname] {
+				continue
+			}
+
+			keys[keyname] = true
+			if onePointer.IsDeleted() {
+				continue
+			}
+
+			out = append(out, onePointer)
+		}
+
+		if !current.HasPrevious() {
+			break
+		}
+
+		previous, err := current.Previous()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		current = previous
+		amount++
+	}
+
+	return out, amount, nil
+}
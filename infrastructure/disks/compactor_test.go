@@ -0,0 +1,471 @@
+package disks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/resources"
+	"github.com/steve-care-software/database/domain/states"
+)
+
+func newCompactionResourceForTests(name string) hash.Hash {
+	resource, err := hash.NewAdapter().FromBytes([]byte(name))
+	if err != nil {
+		panic(err)
+	}
+
+	return *resource
+}
+
+func newCompactionCommitForTests(values map[string][]byte) commits.Commit {
+	mp := map[string][]byte{}
+	for name, value := range values {
+		mp[newCompactionResourceForTests(name).String()] = value
+	}
+
+	commit, err := commits.NewBuilder().Create().WithValues(map[string]map[string][]byte{
+		"my_namespace": mp,
+	}).CreatedOn(time.Now().UTC()).Now()
+
+	if err != nil {
+		panic(err)
+	}
+
+	return commit
+}
+
+func valueForTests(resourceRepository resources.Repository, state states.State, name string) (string, error) {
+	ptr, err := state.Pointer("my_namespace", newCompactionResourceForTests(name))
+	if err != nil {
+		return "", err
+	}
+
+	res, err := resourceRepository.Retrieve(ptr)
+	if err != nil {
+		return "", err
+	}
+
+	return string(res.Value()), nil
+}
+
+func TestCompactor_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, err := NewBuilder(baseDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	resourceRepository := services.ResourceRepository()
+	stateRepository := services.StateRepository()
+	stateService := services.StateService()
+	compactor := services.Compactor()
+
+	insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+		"overwritten": []byte(strings.Repeat("1", 100)),
+		"kept":        []byte("kept value"),
+		"deleted":     []byte("deleted value"),
+	}))
+
+	insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+		"overwritten": []byte(strings.Repeat("2", 100)),
+		"deleted":     nil,
+	}))
+
+	for i := 3; i <= 5; i++ {
+		insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+			"overwritten": []byte(strings.Repeat(fmt.Sprintf("%d", i), 100)),
+		}))
+	}
+
+	head, _, _ := stateRepository.Retrieve()
+	previous, _ := head.Previous()
+	dropped, _ := previous.Previous()
+	beforeDropped, _ := dropped.Previous()
+	compaction, err := compactor.Compact(NewLastStatesRetention(2))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if compaction.Retained() != 2 || compaction.Dropped() != 3 {
+		t.Errorf("%d states were expected to be retained and %d dropped, %d and %d returned", 2, 3, compaction.Retained(), compaction.Dropped())
+		return
+	}
+
+	if compaction.Reclaimed() < 200 || compaction.Before()-compaction.Reclaimed() != compaction.After() {
+		t.Errorf("at least %d bytes were expected to be reclaimed, %d returned", 200, compaction.Reclaimed())
+		return
+	}
+
+	// the hashes are computed again from the relocated pointers, which keeps them, along with the heights:
+	retHead, _, _ := stateRepository.Retrieve()
+	retRoot, err := retHead.Root()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !retHead.Hash().Compare(head.Hash()) || retHead.Height() != 5 || !retHead.IsCompacted() || retHead.IsSnapshot() {
+		t.Errorf("the retained states were expected to keep their hash and height")
+		return
+	}
+
+	// the last dropped state is replaced by a snapshot:
+	if !retRoot.Hash().Compare(dropped.Hash()) || retRoot.Height() != 3 || !retRoot.IsSnapshot() {
+		t.Errorf("the root was expected to be the snapshot of the last dropped state")
+		return
+	}
+
+	value, err := valueForTests(resourceRepository, retRoot, "overwritten")
+	if err != nil || value != strings.Repeat("3", 100) {
+		t.Errorf("the overwritten value was expected at the snapshot")
+		return
+	}
+
+	_, err = retHead.Fetch(beforeDropped.Hash())
+	if !errors.Is(err, failures.ErrStateNotFound) {
+		t.Errorf("the dropped state was not expected to be found")
+		return
+	}
+
+	value, err = valueForTests(resourceRepository, retHead, "overwritten")
+	if err != nil || value != strings.Repeat("5", 100) {
+		t.Errorf("the overwritten value was expected at the head")
+		return
+	}
+
+	retPrevious, _ := retHead.Previous()
+	value, err = valueForTests(resourceRepository, retPrevious, "overwritten")
+	if err != nil || value != strings.Repeat("4", 100) {
+		t.Errorf("the overwritten value was expected at the retained state")
+		return
+	}
+
+	// the live values of the dropped states are kept by the snapshot:
+	value, err = valueForTests(resourceRepository, retHead, "kept")
+	if err != nil || value != "kept value" {
+		t.Errorf("the kept value was expected at the head")
+		return
+	}
+
+	_, err = valueForTests(resourceRepository, retHead, "deleted")
+	if !errors.Is(err, failures.ErrResourceNotFound) {
+		t.Errorf("the deleted value was not expected to be found")
+		return
+	}
+
+	// the database remains usable:
+	insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+		"overwritten": []byte("6"),
+	}))
+
+	newHead, _, _ := stateRepository.Retrieve()
+	value, err = valueForTests(resourceRepository, newHead, "kept")
+	if err != nil || value != "kept value" || newHead.Height() != 6 {
+		t.Errorf("the database was expected to remain usable after the compaction")
+		return
+	}
+}
+
+func TestCompactor_offline_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	_, _, stateService, dbFilePath := newLayoutServicesForTests(baseDir)
+	for i := 1; i <= 3; i++ {
+		insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+			"overwritten": []byte(strings.Repeat(fmt.Sprintf("%d", i), 100)),
+		}))
+	}
+
+	compaction, err := NewOfflineCompactor(dbFilePath, "tmp").Compact(NewLastStatesRetention(1))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if compaction.Retained() != 1 || compaction.Reclaimed() <= 0 {
+		t.Errorf("the offline compaction was expected to reclaim bytes")
+		return
+	}
+
+	// the database is opened again once compacted:
+	resourceRepository, stateRepository, _, _ := newLayoutServicesForTests(baseDir)
+	head, _, _ := stateRepository.Retrieve()
+	value, err := valueForTests(resourceRepository, head, "overwritten")
+	if err != nil || value != strings.Repeat("3", 100) {
+		t.Errorf("the head was expected to be retained")
+		return
+	}
+
+	previous, err := head.Previous()
+	if err != nil || !previous.IsSnapshot() || previous.HasPrevious() {
+		t.Errorf("the head was expected to be the only remaining state, along with the snapshot")
+		return
+	}
+}
+
+func TestCompactor_withStaleStateAndDroppedBase_returnsError(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, err := NewBuilder(baseDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	stateRepository := services.StateRepository()
+	stateService := services.StateService()
+	for i := 1; i <= 4; i++ {
+		insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+			"overwritten": []byte(strings.Repeat(fmt.Sprintf("%d", i), 100)),
+		}))
+	}
+
+	// the head is read before the compaction, its previous state being loaded on demand, so the root is walked from another copy:
+	head, _, _ := stateRepository.Retrieve()
+	other, _, _ := stateRepository.Retrieve()
+	root, _ := other.Root()
+	ptr, _ := head.Pointer("my_namespace", newCompactionResourceForTests("overwritten"))
+	_, err = services.Compactor().Compact(NewLastStatesRetention(2))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	_, err = head.Previous()
+	if !errors.Is(err, failures.ErrStaleState) {
+		t.Errorf("the error was expected to be a stale state error, %v returned", err)
+		return
+	}
+
+	// the pointer read before the compaction indexes the replaced file:
+	_, err = services.ResourceRepository().Retrieve(ptr)
+	if !errors.Is(err, failures.ErrStaleState) {
+		t.Errorf("the error was expected to be a stale state error, %v returned", err)
+		return
+	}
+
+	// a commit whose base state was dropped, before the snapshot, is rejected, even without conflicting with the retained states:
+	commit, _ := commits.NewBuilder().Create().CreatedOn(time.Now().UTC()).WithBase(root.Hash()).WithValues(map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			newCompactionResourceForTests("other").String(): []byte("other value"),
+		},
+	}).Now()
+
+	err = stateService.Insert(commit, func(ctx commits.Commit, state states.State) error {
+		return nil
+	}, func(ctx commits.Commit, err error) error {
+		return err
+	})
+
+	casted, ok := err.(*failures.StateNotFoundError)
+	if !ok || !casted.IsCompacted {
+		t.Errorf("the error was expected to be a state not found error caused by the compaction, %v returned", err)
+		return
+	}
+
+	// the states read once compacted remain usable:
+	retHead, _, _ := stateRepository.Retrieve()
+	_, err = retHead.Fetch(root.Hash())
+	casted, ok = err.(*failures.StateNotFoundError)
+	if !ok || !casted.IsCompacted {
+		t.Errorf("the error was expected to be a state not found error caused by the compaction, %v returned", err)
+		return
+	}
+}
+
+func TestCompactor_withBaseBeforeCompaction_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, err := NewBuilder(baseDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	stateRepository := services.StateRepository()
+	stateService := services.StateService()
+	for i := 1; i <= 3; i++ {
+		insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+			"overwritten": []byte(strings.Repeat(fmt.Sprintf("%d", i), 100)),
+		}))
+	}
+
+	head, _, _ := stateRepository.Retrieve()
+	_, err = services.Compactor().Compact(NewLastStatesRetention(2))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	// the hashes of the states are kept through the compaction, so a commit based on the head read before it is pushed:
+	commit, _ := commits.NewBuilder().Create().CreatedOn(time.Now().UTC()).WithBase(head.Hash()).WithValues(map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			newCompactionResourceForTests("other").String(): []byte("other value"),
+		},
+	}).Now()
+
+	err = stateService.Insert(commit, func(ctx commits.Commit, state states.State) error {
+		return nil
+	}, func(ctx commits.Commit, err error) error {
+		return err
+	})
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	retHead, _, _ := stateRepository.Retrieve()
+	if retHead.Height() != 4 {
+		t.Errorf("the height was expected to be %d, %d returned", 4, retHead.Height())
+		return
+	}
+
+	previous, err := retHead.Previous()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !previous.Hash().Compare(head.Hash()) {
+		t.Errorf("the previous state was expected to be the head pushed before the compaction")
+		return
+	}
+
+	// a commit based on the snapshot of the last dropped state is pushed as long as it does not conflict:
+	root, _ := retHead.Root()
+	if !root.IsSnapshot() || root.Height() != 1 {
+		t.Errorf("the root was expected to be the snapshot of the first state")
+		return
+	}
+
+	commit, _ = commits.NewBuilder().Create().CreatedOn(time.Now().UTC()).WithBase(root.Hash()).WithValues(map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			newCompactionResourceForTests("another").String(): []byte("another value"),
+		},
+	}).Now()
+
+	err = stateService.Insert(commit, func(ctx commits.Commit, state states.State) error {
+		return nil
+	}, func(ctx commits.Commit, err error) error {
+		return err
+	})
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	value, err := valueForTests(services.ResourceRepository(), retHead, "overwritten")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if value != strings.Repeat("3", 100) {
+		t.Errorf("the value was expected to be written by the third state")
+		return
+	}
+}
+
+func TestCompactor_withConcurrentReaders_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, err := NewBuilder(baseDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	resourceRepository := services.ResourceRepository()
+	stateRepository := services.StateRepository()
+	stateService := services.StateService()
+	insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+		"kept": []byte("kept value"),
+	}))
+
+	// the readers either read the value, or learn that the state they hold was compacted:
+	done := make(chan bool)
+	failed := make(chan error, 4)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				head, _, err := stateRepository.Retrieve()
+				if err != nil {
+					failed <- err
+					return
+				}
+
+				value, err := valueForTests(resourceRepository, head, "kept")
+				if err != nil && !errors.Is(err, failures.ErrStaleState) {
+					failed <- err
+					return
+				}
+
+				if err == nil && value != "kept value" {
+					failed <- fmt.Errorf("the value (%s) was not expected", value)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		insertCommitForTests(stateService, newCompactionCommitForTests(map[string][]byte{
+			"overwritten": []byte(fmt.Sprintf("%d", i)),
+		}))
+
+		_, err := services.Compactor().Compact(NewLastStatesRetention(1))
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			break
+		}
+	}
+
+	close(done)
+	wg.Wait()
+	close(failed)
+	for err := range failed {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+}
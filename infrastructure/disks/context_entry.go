@@ -0,0 +1,51 @@
+package disks
+
+import "github.com/steve-care-software/cryptography/domain/hash"
+
+// contextEntryLengthSize is the size of the length that prefixes each entry of a context journal, so that an entry that was
+// only partially written can be told apart from a corrupt one
+const contextEntryLengthSize = 8
+
+const (
+	// contextEntryBegin represents the first entry of a context journal
+	contextEntryBegin uint8 = iota
+
+	// contextEntryWrite represents a written value in a context journal
+	contextEntryWrite
+
+	// contextEntryCommit represents the commit of a context journal
+	contextEntryCommit
+
+	// contextEntryRead represents a read resource in a context journal
+	contextEntryRead
+
+	// contextEntryRemove represents a value removed from the queue of a context journal
+	contextEntryRemove
+
+	// contextEntrySavepoint represents a savepoint created in a context journal
+	contextEntrySavepoint
+
+	// contextEntryRollback represents a rollback to a savepoint of a context journal, journaled after its restored values
+	contextEntryRollback
+
+	// contextEntryRelease represents a savepoint released from a context journal, along with the ones created after it
+	contextEntryRelease
+)
+
+type contextEntry struct {
+	Knd      uint8
+	Hsh      hash.Hash
+	NmeSpace string
+	Res      hash.Hash
+	Dat      []byte
+	IsDel    bool
+	CrOn     int64
+}
+
+func newContextEntryMapping() map[string]interface{} {
+	return map[string]interface{}{
+		"github.com/steve-care-software/database/infrastructure/disks/contextEntry": contextEntry{},
+		"hash.Hash": uint8(0),
+		"[]uint8":   uint8(0),
+	}
+}
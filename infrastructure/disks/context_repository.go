@@ -0,0 +1,215 @@
+package disks
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/contexts"
+	"github.com/steve-care-software/database/domain/failures"
+)
+
+type contextRepository struct {
+	hashAdapter      hash.Adapter
+	contextAdapter   bytes.Adapter
+	builder          contexts.Builder
+	savepointBuilder contexts.SavepointBuilder
+	baseDirPath      string
+}
+
+func createContextRepository(
+	hashAdapter hash.Adapter,
+	contextAdapter bytes.Adapter,
+	builder contexts.Builder,
+	savepointBuilder contexts.SavepointBuilder,
+	baseDirPath string,
+) contexts.Repository {
+	out := contextRepository{
+		hashAdapter:      hashAdapter,
+		contextAdapter:   contextAdapter,
+		builder:          builder,
+		savepointBuilder: savepointBuilder,
+		baseDirPath:      baseDirPath,
+	}
+
+	return &out
+}
+
+// List lists the journaled contexts
+func (app *contextRepository) List() ([]hash.Hash, error) {
+	// if the base dir is not created, return an empty list:
+	if _, err := os.Stat(app.baseDirPath); os.IsNotExist(err) {
+		return []hash.Hash{}, nil
+	}
+
+	// read the dir content:
+	files, err := ioutil.ReadDir(app.baseDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []hash.Hash{}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		hash, err := app.hashAdapter.FromString(file.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, *hash)
+	}
+
+	return list, nil
+}
+
+// Retrieve replays the journal of a context
+func (app *contextRepository) Retrieve(context hash.Hash) (contexts.Context, error) {
+	path := filepath.Join(app.baseDirPath, context.String())
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, &failures.ContextNotFoundError{
+			Context: context,
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	isBegun := false
+	builder := app.builder.Create().WithHash(context)
+	values := map[string]map[string][]byte{}
+	reads := map[string][]hash.Hash{}
+	savepoints := []*replayedSavepoint{}
+	for offset := 0; offset < len(data); {
+		// a journal entry that was only partially written can only be the last one, and is discarded:
+		if len(data)-offset < contextEntryLengthSize {
+			break
+		}
+
+		length := binary.LittleEndian.Uint64(data[offset : offset+contextEntryLengthSize])
+		if length > uint64(len(data)-offset-contextEntryLengthSize) {
+			break
+		}
+
+		start := offset + contextEntryLengthSize
+		ins, remaining, err := app.contextAdapter.ToInstance(data[start : start+int(length)])
+		if err != nil || len(remaining) > 0 {
+			return nil, &failures.CorruptDataError{
+				Reason: fmt.Sprintf("the journal entry (offset: %d) of the context (hash: %s) is corrupt", offset, context.String()),
+			}
+		}
+
+		offset = start + int(length)
+		entry, ok := ins.(contextEntry)
+		if !ok {
+			return nil, &failures.CorruptDataError{
+				Reason: fmt.Sprintf("the journal entry of the context (hash: %s) could not be casted properly", context.String()),
+			}
+		}
+
+		switch entry.Knd {
+		case contextEntryBegin:
+			isBegun = true
+			builder.CreatedOn(time.Unix(0, entry.CrOn))
+			if len(entry.Hsh) > 0 {
+				builder.WithBase(entry.Hsh)
+			}
+		case contextEntryWrite:
+			if _, ok := values[entry.NmeSpace]; !ok {
+				values[entry.NmeSpace] = map[string][]byte{}
+			}
+
+			var value []byte
+			if !entry.IsDel {
+				value = entry.Dat
+			}
+
+			// the first write of a resource since the latest savepoint keeps what it replaced in the queue:
+			if amount := len(savepoints); amount > 0 {
+				prev, exists := values[entry.NmeSpace][entry.Res.String()]
+				savepoints[amount-1].record(entry.NmeSpace, entry.Res, prev, exists)
+			}
+
+			values[entry.NmeSpace][entry.Res.String()] = value
+		case contextEntryRemove:
+			if resources, ok := values[entry.NmeSpace]; ok {
+				delete(resources, entry.Res.String())
+				if len(resources) <= 0 {
+					delete(values, entry.NmeSpace)
+				}
+			}
+		case contextEntryRead:
+			reads[entry.NmeSpace] = append(reads[entry.NmeSpace], entry.Res)
+		case contextEntryCommit:
+			builder.WithCommit(entry.Hsh)
+		case contextEntrySavepoint:
+			savepoints = append(savepoints, createReplayedSavepoint(entry.Hsh))
+		case contextEntryRollback:
+			// the restored values are journaled before the rollback, the savepoint itself remains:
+			index, err := app.savepointIndex(context, savepoints, entry.Hsh)
+			if err != nil {
+				return nil, err
+			}
+
+			savepoints = append(savepoints[:index], createReplayedSavepoint(entry.Hsh))
+		case contextEntryRelease:
+			index, err := app.savepointIndex(context, savepoints, entry.Hsh)
+			if err != nil {
+				return nil, err
+			}
+
+			if index > 0 {
+				for _, oneSavepoint := range savepoints[index:] {
+					savepoints[index-1].merge(oneSavepoint)
+				}
+			}
+
+			savepoints = savepoints[:index]
+		default:
+			return nil, &failures.CorruptDataError{
+				Reason: fmt.Sprintf("the journal entry (kind: %d) of the context (hash: %s) is not supported", entry.Knd, context.String()),
+			}
+		}
+	}
+
+	if !isBegun {
+		return nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the journal of the context (hash: %s) does not contain a begin entry", context.String()),
+		}
+	}
+
+	list := []contexts.Savepoint{}
+	for _, oneSavepoint := range savepoints {
+		priors, absents := oneSavepoint.split()
+		ins, err := app.savepointBuilder.Create().WithHash(oneSavepoint.hash).WithPriors(priors).WithAbsents(absents).Now()
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, ins)
+	}
+
+	return builder.WithValues(values).WithReads(reads).WithSavepoints(list).Now()
+}
+
+func (app *contextRepository) savepointIndex(context hash.Hash, savepoints []*replayedSavepoint, savepoint hash.Hash) (int, error) {
+	for index, oneSavepoint := range savepoints {
+		if oneSavepoint.hash.Compare(savepoint) {
+			return index, nil
+		}
+	}
+
+	return 0, &failures.CorruptDataError{
+		Reason: fmt.Sprintf("the journal of the context (hash: %s) references a savepoint (hash: %s) that it does not contain", context.String(), savepoint.String()),
+	}
+}
@@ -0,0 +1,75 @@
+package disks
+
+import "github.com/steve-care-software/cryptography/domain/hash"
+
+// replayedSavepoint is a savepoint of a context journal while its entries are replayed
+type replayedSavepoint struct {
+	hash     hash.Hash
+	priors   []replayedPrior
+	recorded map[string]map[string]bool
+}
+
+// replayedPrior is the value a resource had in the queue before its first write since a savepoint
+type replayedPrior struct {
+	namespace string
+	resource  hash.Hash
+	value     []byte
+	exists    bool
+}
+
+func createReplayedSavepoint(hash hash.Hash) *replayedSavepoint {
+	out := replayedSavepoint{
+		hash:     hash,
+		priors:   []replayedPrior{},
+		recorded: map[string]map[string]bool{},
+	}
+
+	return &out
+}
+
+// record keeps the value a resource had in the queue before its first write since the savepoint
+func (obj *replayedSavepoint) record(namespace string, resource hash.Hash, value []byte, exists bool) {
+	if _, ok := obj.recorded[namespace]; !ok {
+		obj.recorded[namespace] = map[string]bool{}
+	}
+
+	keyname := resource.String()
+	if obj.recorded[namespace][keyname] {
+		return
+	}
+
+	obj.recorded[namespace][keyname] = true
+	obj.priors = append(obj.priors, replayedPrior{
+		namespace: namespace,
+		resource:  resource,
+		value:     value,
+		exists:    exists,
+	})
+}
+
+// merge adds the values recorded by a later savepoint, keeping the older ones
+func (obj *replayedSavepoint) merge(later *replayedSavepoint) {
+	for _, onePrior := range later.priors {
+		obj.record(onePrior.namespace, onePrior.resource, onePrior.value, onePrior.exists)
+	}
+}
+
+// split returns the replaced values by namespace, then the resources that were not queued by namespace
+func (obj *replayedSavepoint) split() (map[string]map[string][]byte, map[string][]hash.Hash) {
+	priors := map[string]map[string][]byte{}
+	absents := map[string][]hash.Hash{}
+	for _, onePrior := range obj.priors {
+		if !onePrior.exists {
+			absents[onePrior.namespace] = append(absents[onePrior.namespace], onePrior.resource)
+			continue
+		}
+
+		if _, ok := priors[onePrior.namespace]; !ok {
+			priors[onePrior.namespace] = map[string][]byte{}
+		}
+
+		priors[onePrior.namespace][onePrior.resource.String()] = onePrior.value
+	}
+
+	return priors, absents
+}
@@ -0,0 +1,236 @@
+package disks
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/contexts"
+	"github.com/steve-care-software/database/domain/failures"
+)
+
+type contextService struct {
+	hashAdapter    hash.Adapter
+	contextAdapter bytes.Adapter
+	baseDirPath    string
+}
+
+func createContextService(
+	hashAdapter hash.Adapter,
+	contextAdapter bytes.Adapter,
+	baseDirPath string,
+) contexts.Service {
+	out := contextService{
+		hashAdapter:    hashAdapter,
+		contextAdapter: contextAdapter,
+		baseDirPath:    baseDirPath,
+	}
+
+	return &out
+}
+
+// Insert begins the journal of a context instance
+func (app *contextService) Insert(context contexts.Context) error {
+	// if the base dir is not created, create it:
+	if _, err := os.Stat(app.baseDirPath); os.IsNotExist(err) {
+		err := os.MkdirAll(app.baseDirPath, 0777)
+		if err != nil {
+			return err
+		}
+	}
+
+	path := filepath.Join(app.baseDirPath, context.Hash().String())
+	if _, err := os.Stat(path); err == nil {
+		str := fmt.Sprintf("the context (hash: %s) already exists", context.Hash().String())
+		return errors.New(str)
+	}
+
+	entries := []contextEntry{
+		{
+			Knd:  contextEntryBegin,
+			Hsh:  context.Base(),
+			CrOn: context.CreatedOn().UnixNano(),
+		},
+	}
+
+	for namespace, resources := range context.Values() {
+		for keyname, value := range resources {
+			resource, err := app.hashAdapter.FromString(keyname)
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, app.writeEntry(namespace, *resource, value))
+		}
+	}
+
+	for namespace, resources := range context.Reads() {
+		for _, oneResource := range resources {
+			entries = append(entries, app.readEntry(namespace, oneResource))
+		}
+	}
+
+	if context.HasCommit() {
+		entries = append(entries, contextEntry{
+			Knd: contextEntryCommit,
+			Hsh: context.Commit(),
+		})
+	}
+
+	return app.append(path, entries)
+}
+
+// Write journals a value written to a context, a nil value represents a deleted resource
+func (app *contextService) Write(context hash.Hash, namespace string, resource hash.Hash, value []byte) error {
+	path, err := app.path(context)
+	if err != nil {
+		return err
+	}
+
+	return app.append(path, []contextEntry{
+		app.writeEntry(namespace, resource, value),
+	})
+}
+
+// Remove journals a value removed from the queue of a context
+func (app *contextService) Remove(context hash.Hash, namespace string, resource hash.Hash) error {
+	path, err := app.path(context)
+	if err != nil {
+		return err
+	}
+
+	return app.append(path, []contextEntry{
+		{
+			Knd:      contextEntryRemove,
+			NmeSpace: namespace,
+			Res:      resource,
+		},
+	})
+}
+
+// Read journals a resource read by a context
+func (app *contextService) Read(context hash.Hash, namespace string, resource hash.Hash) error {
+	path, err := app.path(context)
+	if err != nil {
+		return err
+	}
+
+	return app.append(path, []contextEntry{
+		app.readEntry(namespace, resource),
+	})
+}
+
+// Commit journals the commit of a context
+func (app *contextService) Commit(context hash.Hash, commit hash.Hash) error {
+	path, err := app.path(context)
+	if err != nil {
+		return err
+	}
+
+	return app.append(path, []contextEntry{
+		{
+			Knd: contextEntryCommit,
+			Hsh: commit,
+		},
+	})
+}
+
+// Savepoint journals a savepoint created in a context
+func (app *contextService) Savepoint(context hash.Hash, savepoint hash.Hash) error {
+	return app.savepoint(context, contextEntrySavepoint, savepoint)
+}
+
+// RollbackTo journals the rollback of a context to a savepoint
+func (app *contextService) RollbackTo(context hash.Hash, savepoint hash.Hash) error {
+	return app.savepoint(context, contextEntryRollback, savepoint)
+}
+
+// Release journals the release of a savepoint of a context
+func (app *contextService) Release(context hash.Hash, savepoint hash.Hash) error {
+	return app.savepoint(context, contextEntryRelease, savepoint)
+}
+
+// Delete deletes the journal of a context
+func (app *contextService) Delete(context hash.Hash) error {
+	path, err := app.path(context)
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+func (app *contextService) savepoint(context hash.Hash, kind uint8, savepoint hash.Hash) error {
+	path, err := app.path(context)
+	if err != nil {
+		return err
+	}
+
+	return app.append(path, []contextEntry{
+		{
+			Knd: kind,
+			Hsh: savepoint,
+		},
+	})
+}
+
+func (app *contextService) path(context hash.Hash) (string, error) {
+	path := filepath.Join(app.baseDirPath, context.String())
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return "", &failures.ContextNotFoundError{
+			Context: context,
+		}
+	}
+
+	return path, nil
+}
+
+func (app *contextService) writeEntry(namespace string, resource hash.Hash, value []byte) contextEntry {
+	return contextEntry{
+		Knd:      contextEntryWrite,
+		NmeSpace: namespace,
+		Res:      resource,
+		Dat:      value,
+		IsDel:    value == nil,
+	}
+}
+
+func (app *contextService) readEntry(namespace string, resource hash.Hash) contextEntry {
+	return contextEntry{
+		Knd:      contextEntryRead,
+		NmeSpace: namespace,
+		Res:      resource,
+	}
+}
+
+func (app *contextService) append(path string, entries []contextEntry) error {
+	data := []byte{}
+	for _, oneEntry := range entries {
+		entryBytes, err := app.contextAdapter.ToBytes(oneEntry)
+		if err != nil {
+			return err
+		}
+
+		length := make([]byte, contextEntryLengthSize)
+		binary.LittleEndian.PutUint64(length, uint64(len(entryBytes)))
+		data = append(data, length...)
+		data = append(data, entryBytes...)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0777)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+	_, err = file.Write(data)
+	if err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
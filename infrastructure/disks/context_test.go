@@ -0,0 +1,232 @@
+package disks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/contexts"
+	"github.com/steve-care-software/database/domain/failures"
+)
+
+func TestContext_Success(t *testing.T) {
+	baseDir := "./test_files"
+	commitDirPath := "commits"
+	contextDirPath := "contexts"
+	dbFileName := "database.db"
+	dbTmpExtension := ".tmp"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	hashAdapter := hash.NewAdapter()
+	application, err := hashAdapter.FromBytes([]byte("this is some data"))
+	if err != nil {
+		panic(err)
+	}
+
+	ctx, _ := hashAdapter.FromBytes([]byte("this is a context"))
+	base, _ := hashAdapter.FromBytes([]byte("this is a base state"))
+	commit, _ := hashAdapter.FromBytes([]byte("this is a commit"))
+	first, _ := hashAdapter.FromBytes([]byte("this is the first resource"))
+	second, _ := hashAdapter.FromBytes([]byte("this is the second resource"))
+
+	services, err := NewBuilder(baseDir, commitDirPath, contextDirPath, dbFileName, dbTmpExtension).Create().WithApplication(*application).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	contextRepository := services.ContextRepository()
+	contextService := services.ContextService()
+
+	journal, err := contexts.NewBuilder().Create().WithHash(*ctx).WithBase(*base).CreatedOn(time.Now().UTC()).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = contextService.Insert(journal)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = contextService.Write(*ctx, "my_namespace", *first, []byte("first value"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = contextService.Write(*ctx, "my_namespace", *first, []byte("first value, updated"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = contextService.Write(*ctx, "my_namespace", *second, nil)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	third, _ := hashAdapter.FromBytes([]byte("this is the third resource"))
+	err = contextService.Write(*ctx, "my_namespace", *third, []byte("third value"))
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = contextService.Remove(*ctx, "my_namespace", *third)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = contextService.Read(*ctx, "my_namespace", *second)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	retJournal, err := contextRepository.Retrieve(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !retJournal.HasBase() || !retJournal.Base().Compare(*base) {
+		t.Errorf("the context was expected to contain the base state (hash: %s)", base.String())
+		return
+	}
+
+	if retJournal.HasCommit() {
+		t.Errorf("the context was NOT expected to contain a commit")
+		return
+	}
+
+	values := retJournal.Values()["my_namespace"]
+	if bytes.Compare(values[first.String()], []byte("first value, updated")) != 0 {
+		t.Errorf("the first resource was expected to contain its latest value")
+		return
+	}
+
+	if value, ok := values[second.String()]; !ok || value != nil {
+		t.Errorf("the second resource was expected to be deleted")
+		return
+	}
+
+	if _, ok := values[third.String()]; ok {
+		t.Errorf("the third resource was expected to be removed")
+		return
+	}
+
+	reads := retJournal.Reads()["my_namespace"]
+	if len(reads) != 1 || !reads[0].Compare(*second) {
+		t.Errorf("the context was expected to contain the read resource (hash: %s)", second.String())
+		return
+	}
+
+	err = contextService.Commit(*ctx, *commit)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	retJournal, err = contextRepository.Retrieve(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !retJournal.HasCommit() || !retJournal.Commit().Compare(*commit) {
+		t.Errorf("the context was expected to contain the commit (hash: %s)", commit.String())
+		return
+	}
+
+	list, err := contextRepository.List()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 1 {
+		t.Errorf("the list was expected to contain %d elements, %d returned", 1, len(list))
+		return
+	}
+
+	err = contextService.Delete(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	_, err = contextRepository.Retrieve(*ctx)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+}
+
+func TestContext_withCorruptEntry_returnsError(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	hashAdapter := hash.NewAdapter()
+	application, _ := hashAdapter.FromBytes([]byte("this is some data"))
+	ctx, _ := hashAdapter.FromBytes([]byte("this is a context"))
+	first, _ := hashAdapter.FromBytes([]byte("this is the first resource"))
+	second, _ := hashAdapter.FromBytes([]byte("this is the second resource"))
+	services, _ := NewBuilder(baseDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	contextRepository := services.ContextRepository()
+	contextService := services.ContextService()
+	journal, _ := contexts.NewBuilder().Create().WithHash(*ctx).CreatedOn(time.Now().UTC()).Now()
+	contextService.Insert(journal)
+	contextService.Write(*ctx, "my_namespace", *first, []byte("first value"))
+	contextService.Write(*ctx, "my_namespace", *second, []byte("second value"))
+
+	path := filepath.Join(baseDir, application.String(), "contexts", ctx.String())
+	data, _ := ioutil.ReadFile(path)
+
+	// an entry that was only partially written at the end of the journal is discarded:
+	err := ioutil.WriteFile(path, data[:len(data)-5], 0777)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	retJournal, err := contextRepository.Retrieve(*ctx)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	values := retJournal.Values()["my_namespace"]
+	if _, ok := values[second.String()]; ok || len(values) != 1 {
+		t.Errorf("the partially written entry was expected to be discarded")
+		return
+	}
+
+	// a corrupt entry followed by other entries is reported:
+	beginLength := binary.LittleEndian.Uint64(data[:contextEntryLengthSize])
+	start := contextEntryLengthSize + int(beginLength)
+	length := binary.LittleEndian.Uint64(data[start : start+contextEntryLengthSize])
+	corrupted := append([]byte{}, data...)
+	for i := start + contextEntryLengthSize; i < start+contextEntryLengthSize+int(length); i++ {
+		corrupted[i] = 0xff
+	}
+
+	ioutil.WriteFile(path, corrupted, 0777)
+	_, err = contextRepository.Retrieve(*ctx)
+	if !errors.Is(err, failures.ErrCorruptData) {
+		t.Errorf("the corrupt entry was expected to be reported")
+		return
+	}
+}
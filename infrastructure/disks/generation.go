@@ -0,0 +1,61 @@
+package disks
+
+import (
+	"sync"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/failures"
+)
+
+// generation counts the replacements of a database file by a compaction or an import, so that the offsets read from a
+// replaced file are never used to read the file replacing it
+type generation struct {
+	mutex sync.RWMutex
+	value uint64
+}
+
+func createGeneration() *generation {
+	out := generation{
+		value: 0,
+	}
+
+	return &out
+}
+
+// current returns the current generation
+func (app *generation) current() uint64 {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+	return app.value
+}
+
+// lock prevents the database file from being replaced until the returned func is called, returning the current generation
+func (app *generation) lock() (uint64, func()) {
+	app.mutex.RLock()
+	return app.value, app.mutex.RUnlock
+}
+
+// lockAt prevents the database file from being replaced until the returned func is called, as long as it was not
+// replaced since the passed generation, in which case the passed state is stale
+func (app *generation) lockAt(value uint64, state hash.Hash) (func(), error) {
+	app.mutex.RLock()
+	if app.value != value {
+		app.mutex.RUnlock()
+		return nil, &failures.StaleStateError{
+			State: state,
+		}
+	}
+
+	return app.mutex.RUnlock, nil
+}
+
+// replace executes the passed func once no read of the database file is in progress, then starts a new generation
+func (app *generation) replace(fn func() error) error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	defer func() {
+		app.value++
+	}()
+
+	return fn()
+}
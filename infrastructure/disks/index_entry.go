@@ -0,0 +1,32 @@
+package disks
+
+import "github.com/steve-care-software/cryptography/domain/hash"
+
+const indexFileExtension = "indexes"
+
+const (
+	// indexEntryHead represents the state an index journal is up to date with
+	indexEntryHead uint8 = iota
+
+	// indexEntryPut represents a resource indexed under a key in an index journal
+	indexEntryPut
+
+	// indexEntryRemove represents a resource removed from an index in an index journal
+	indexEntryRemove
+)
+
+type indexEntry struct {
+	Knd uint8
+	Hsh hash.Hash
+	Nme string
+	Ky  string
+	Res hash.Hash
+}
+
+func newIndexEntryMapping() map[string]interface{} {
+	return map[string]interface{}{
+		"github.com/steve-care-software/database/infrastructure/disks/indexEntry": indexEntry{},
+		"hash.Hash": uint8(0),
+		"[]uint8":   uint8(0),
+	}
+}
@@ -0,0 +1,43 @@
+package disks
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/indexes"
+)
+
+type indexRepository struct {
+	store *indexStore
+}
+
+func createIndexRepository(
+	store *indexStore,
+) indexes.Repository {
+	out := indexRepository{
+		store: store,
+	}
+
+	return &out
+}
+
+// List returns the declared indexes
+func (app *indexRepository) List() []indexes.Index {
+	return app.store.list
+}
+
+// Retrieve returns the index of the passed name
+func (app *indexRepository) Retrieve(name string) (indexes.Index, error) {
+	if ins, ok := app.store.byName[name]; ok {
+		return ins, nil
+	}
+
+	str := fmt.Sprintf("the index (name: %s) does not exist", name)
+	return nil, errors.New(str)
+}
+
+// Find returns the resources of the head state whose indexed field equals the passed field, sorted by resource hash
+func (app *indexRepository) Find(name string, field interface{}) ([]hash.Hash, error) {
+	return app.store.find(name, field)
+}
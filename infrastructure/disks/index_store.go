@@ -0,0 +1,448 @@
+package disks
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/indexes"
+	"github.com/steve-care-software/database/domain/resources"
+	"github.com/steve-care-software/database/domain/states"
+)
+
+// indexStore keeps the secondary indexes of the head state in memory, journaled alongside the database file
+type indexStore struct {
+	mutex              sync.Mutex
+	journal            *journal
+	resourceRepository resources.Repository
+	stateRepository    states.Repository
+	list               []indexes.Index
+	byName             map[string]indexes.Index
+	signature          string
+	isLoaded           bool
+	head               hash.Hash
+	keys               map[string]map[string]map[string]hash.Hash
+	reverse            map[string]map[string]string
+	amount             int
+}
+
+func createIndexStore(
+	adapter bytes.Adapter,
+	resourceRepository resources.Repository,
+	stateRepository states.Repository,
+	list []indexes.Index,
+	filePath string,
+	tmpExtension string,
+) (*indexStore, error) {
+	byName := map[string]indexes.Index{}
+	signatures := []string{}
+	for _, oneIndex := range list {
+		name := oneIndex.Name()
+		if _, ok := byName[name]; ok {
+			str := fmt.Sprintf("the index (name: %s) is declared more than once", name)
+			return nil, errors.New(str)
+		}
+
+		byName[name] = oneIndex
+		signatures = append(signatures, fmt.Sprintf("%s|%s|%s", name, oneIndex.Namespace(), strings.Join(oneIndex.Path(), ".")))
+	}
+
+	sort.Strings(signatures)
+	out := indexStore{
+		journal:            createJournal(adapter, filePath, tmpExtension),
+		resourceRepository: resourceRepository,
+		stateRepository:    stateRepository,
+		list:               list,
+		byName:             byName,
+		signature:          strings.Join(signatures, ";"),
+		isLoaded:           false,
+		head:               nil,
+		keys:               map[string]map[string]map[string]hash.Hash{},
+		reverse:            map[string]map[string]string{},
+		amount:             0,
+	}
+
+	return &out, nil
+}
+
+// find returns the resources indexed under the key of the field, sorted by resource hash
+func (app *indexStore) find(name string, field interface{}) ([]hash.Hash, error) {
+	if _, ok := app.byName[name]; !ok {
+		str := fmt.Sprintf("the index (name: %s) does not exist", name)
+		return nil, errors.New(str)
+	}
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	err := app.sync()
+	if err != nil {
+		return nil, err
+	}
+
+	out := []hash.Hash{}
+	for _, oneResource := range app.keys[name][indexes.NewKey(field)] {
+		out = append(out, oneResource)
+	}
+
+	sort.Slice(out, func(i int, j int) bool {
+		return out[i].String() < out[j].String()
+	})
+
+	return out, nil
+}
+
+// changes returns the journal entries that bring the indexes from the current head to the passed state
+func (app *indexStore) changes(state states.State, list []resources.Resource) ([]indexEntry, error) {
+	if len(app.list) <= 0 {
+		return nil, nil
+	}
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	err := app.sync()
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string][]byte{}
+	for _, oneResource := range list {
+		ptr := oneResource.Pointer()
+		values[fmt.Sprintf("%s/%s", ptr.Namespace(), ptr.Resource().String())] = oneResource.Value()
+	}
+
+	entries := []indexEntry{}
+	for _, onePointer := range state.Pointers().List() {
+		namespace := onePointer.Namespace()
+		resource := onePointer.Resource()
+		value := values[fmt.Sprintf("%s/%s", namespace, resource.String())]
+		for _, oneIndex := range app.list {
+			if oneIndex.Namespace() != namespace {
+				continue
+			}
+
+			entry, err := app.entry(oneIndex, resource, value, onePointer.IsDeleted())
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	return append(entries, app.headEntry(state.Hash())), nil
+}
+
+// apply journals the entries returned by changes once the state they lead to is the head
+func (app *indexStore) apply(entries []indexEntry) error {
+	if len(entries) <= 0 {
+		return nil
+	}
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	err := app.append(entries)
+	if err != nil {
+		// the journal is rebuilt from the states on the next access:
+		app.isLoaded = false
+		return err
+	}
+
+	for _, oneEntry := range entries {
+		app.replay(oneEntry)
+	}
+
+	// the overwritten entries remain in the journal, so it is rewritten once most of its entries are overwritten:
+	if !app.journal.isCheckpointDue(app.amount, app.live()) {
+		return nil
+	}
+
+	err = app.checkpoint()
+	if err != nil {
+		app.isLoaded = false
+		return err
+	}
+
+	return nil
+}
+
+// checkpoint rewrites the journal using the resources indexed under every key of every index
+func (app *indexStore) checkpoint() error {
+	entries := []indexEntry{}
+	for name, keys := range app.keys {
+		for keyname, list := range keys {
+			for _, oneResource := range list {
+				entries = append(entries, indexEntry{
+					Knd: indexEntryPut,
+					Nme: name,
+					Ky:  keyname,
+					Res: oneResource,
+				})
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i int, j int) bool {
+		if entries[i].Nme != entries[j].Nme {
+			return entries[i].Nme < entries[j].Nme
+		}
+
+		return entries[i].Res.String() < entries[j].Res.String()
+	})
+
+	amount := len(entries)
+	err := app.write(append(entries, app.headEntry(app.head)))
+	if err != nil {
+		return err
+	}
+
+	app.amount = amount
+	return nil
+}
+
+// live returns the amount of resources indexed by every index
+func (app *indexStore) live() int {
+	amount := 0
+	for _, oneReverse := range app.reverse {
+		amount += len(oneReverse)
+	}
+
+	return amount
+}
+
+// sync makes sure the indexes reflect the head state, replaying the journal or rebuilding it from the states
+func (app *indexStore) sync() error {
+	head, _, err := app.stateRepository.Retrieve()
+	if err != nil {
+		return err
+	}
+
+	var current hash.Hash
+	if head != nil {
+		current = head.Hash()
+	}
+
+	if app.isLoaded && app.head.Compare(current) {
+		return nil
+	}
+
+	app.reset()
+	isReplayed, err := app.load()
+	if err != nil {
+		return err
+	}
+
+	if isReplayed && app.head.Compare(current) {
+		app.isLoaded = true
+		return nil
+	}
+
+	return app.rebuild(head)
+}
+
+// load replays the journal, returning false if it is missing or was written for other indexes
+func (app *indexStore) load() (bool, error) {
+	list, err := app.journal.read()
+	if err != nil {
+		return false, err
+	}
+
+	if len(list) <= 0 {
+		return false, nil
+	}
+
+	// the entries written after the last head entry belong to an interrupted push, so they are discarded:
+	pending := []indexEntry{}
+	for _, ins := range list {
+		entry, ok := ins.(indexEntry)
+		if !ok {
+			return false, nil
+		}
+
+		pending = append(pending, entry)
+		if entry.Knd != indexEntryHead {
+			continue
+		}
+
+		if entry.Nme != app.signature {
+			return false, nil
+		}
+
+		for _, onePending := range pending {
+			app.replay(onePending)
+		}
+
+		pending = []indexEntry{}
+	}
+
+	return true, nil
+}
+
+// rebuild indexes every state from the root to the head, then rewrites the journal
+func (app *indexStore) rebuild(head states.State) error {
+	app.reset()
+	chain := []states.State{}
+	for current := head; current != nil; {
+		chain = append([]states.State{current}, chain...)
+		if !current.HasPrevious() {
+			break
+		}
+
+		previous, err := current.Previous()
+		if err != nil {
+			return err
+		}
+
+		current = previous
+	}
+
+	for _, oneState := range chain {
+		for _, onePointer := range oneState.Pointers().List() {
+			namespace := onePointer.Namespace()
+			for _, oneIndex := range app.list {
+				if oneIndex.Namespace() != namespace {
+					continue
+				}
+
+				var value []byte
+				if !onePointer.IsDeleted() {
+					res, err := app.resourceRepository.Retrieve(onePointer)
+					if err != nil {
+						return err
+					}
+
+					value = res.Value()
+				}
+
+				entry, err := app.entry(oneIndex, onePointer.Resource(), value, onePointer.IsDeleted())
+				if err != nil {
+					return err
+				}
+
+				app.replay(entry)
+			}
+		}
+	}
+
+	// an empty database has nothing to journal:
+	if head == nil {
+		app.isLoaded = true
+		return nil
+	}
+
+	app.replay(app.headEntry(head.Hash()))
+	err := app.checkpoint()
+	if err != nil {
+		return err
+	}
+
+	app.isLoaded = true
+	return nil
+}
+
+// entry returns the journal entry that indexes a resource, a value the index cannot decode is not indexed, so that it
+// never prevents a push or a rebuild
+func (app *indexStore) entry(index indexes.Index, resource hash.Hash, value []byte, isDeleted bool) (indexEntry, error) {
+	remove := indexEntry{
+		Knd: indexEntryRemove,
+		Nme: index.Name(),
+		Res: resource,
+	}
+
+	if isDeleted {
+		return remove, nil
+	}
+
+	key, err := index.Key(value)
+	if err != nil {
+		log.Printf("the resource (namespace: %s, hash: %s) could not be indexed by the index (name: %s): %s", index.Namespace(), resource.String(), index.Name(), err.Error())
+		return remove, nil
+	}
+
+	if key == nil {
+		return remove, nil
+	}
+
+	return indexEntry{
+		Knd: indexEntryPut,
+		Nme: index.Name(),
+		Ky:  *key,
+		Res: resource,
+	}, nil
+}
+
+func (app *indexStore) headEntry(head hash.Hash) indexEntry {
+	return indexEntry{
+		Knd: indexEntryHead,
+		Hsh: head,
+		Nme: app.signature,
+	}
+}
+
+func (app *indexStore) replay(entry indexEntry) {
+	switch entry.Knd {
+	case indexEntryHead:
+		app.head = entry.Hsh
+	case indexEntryPut:
+		app.unindex(entry.Nme, entry.Res)
+		if _, ok := app.keys[entry.Nme]; !ok {
+			app.keys[entry.Nme] = map[string]map[string]hash.Hash{}
+			app.reverse[entry.Nme] = map[string]string{}
+		}
+
+		if _, ok := app.keys[entry.Nme][entry.Ky]; !ok {
+			app.keys[entry.Nme][entry.Ky] = map[string]hash.Hash{}
+		}
+
+		keyname := entry.Res.String()
+		app.keys[entry.Nme][entry.Ky][keyname] = entry.Res
+		app.reverse[entry.Nme][keyname] = entry.Ky
+		app.amount++
+	case indexEntryRemove:
+		app.unindex(entry.Nme, entry.Res)
+		app.amount++
+	}
+}
+
+func (app *indexStore) unindex(name string, resource hash.Hash) {
+	keyname := resource.String()
+	key, ok := app.reverse[name][keyname]
+	if !ok {
+		return
+	}
+
+	delete(app.reverse[name], keyname)
+	delete(app.keys[name][key], keyname)
+	if len(app.keys[name][key]) <= 0 {
+		delete(app.keys[name], key)
+	}
+}
+
+func (app *indexStore) reset() {
+	app.isLoaded = false
+	app.head = nil
+	app.keys = map[string]map[string]map[string]hash.Hash{}
+	app.reverse = map[string]map[string]string{}
+	app.amount = 0
+}
+
+func (app *indexStore) append(entries []indexEntry) error {
+	return app.journal.append(app.toList(entries))
+}
+
+func (app *indexStore) write(entries []indexEntry) error {
+	return app.journal.write(app.toList(entries))
+}
+
+func (app *indexStore) toList(entries []indexEntry) []interface{} {
+	out := []interface{}{}
+	for _, oneEntry := range entries {
+		out = append(out, oneEntry)
+	}
+
+	return out
+}
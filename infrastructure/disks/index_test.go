@@ -0,0 +1,113 @@
+package disks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/indexes"
+)
+
+type indexedForTests struct {
+	Email string
+}
+
+func newIndexAdapterForTests() bytes.Adapter {
+	adapter, err := bytes.NewAdapterBuilder().Create().WithMapping(map[string]interface{}{
+		"github.com/steve-care-software/database/infrastructure/disks/indexedForTests": indexedForTests{},
+	}).Now()
+
+	if err != nil {
+		panic(err)
+	}
+
+	return adapter
+}
+
+func newIndexesForTests() []indexes.Index {
+	index, err := indexes.NewBuilder().Create().WithName("by_email").WithNamespace("my_namespace").WithPath([]string{"Email"}).WithAdapter(newIndexAdapterForTests()).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	return []indexes.Index{
+		index,
+	}
+}
+
+func newIndexedForTests(email string) []byte {
+	data, err := newIndexAdapterForTests().ToBytes(indexedForTests{
+		Email: email,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+func TestIndexStore_checkpoint_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	application, _ := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	services, err := NewBuilder(baseDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).WithIndexes(newIndexesForTests()).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	indexFilePath := fmt.Sprintf("%s.%s", filepath.Join(baseDir, application.String(), "database.db"), indexFileExtension)
+	sizes := []int64{}
+	for i := 0; i < journalCheckpointMinimum+journalCheckpointMinimum/2; i++ {
+		insertCommitForTests(services.StateService(), newCompactionCommitForTests(map[string][]byte{
+			"indexed": newIndexedForTests(fmt.Sprintf("%d@example.com", i)),
+		}))
+
+		info, err := os.Stat(indexFilePath)
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+
+		sizes = append(sizes, info.Size())
+	}
+
+	// the same resource is indexed again by every push, so the journal is rewritten once it reaches the minimum:
+	last := sizes[len(sizes)-1]
+	if last >= sizes[journalCheckpointMinimum-1] {
+		t.Errorf("the journal was expected to be rewritten as a checkpoint, %d bytes then %d bytes", sizes[journalCheckpointMinimum-1], last)
+		return
+	}
+
+	// the checkpoint is replayed by new services:
+	reopened, err := NewBuilder(baseDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).WithIndexes(newIndexesForTests()).Now()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	email := fmt.Sprintf("%d@example.com", journalCheckpointMinimum+journalCheckpointMinimum/2-1)
+	list, err := reopened.IndexRepository().Find("by_email", email)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if len(list) != 1 || !list[0].Compare(newCompactionResourceForTests("indexed")) {
+		t.Errorf("the resource was expected to be indexed under its latest email")
+		return
+	}
+
+	list, _ = reopened.IndexRepository().Find("by_email", "0@example.com")
+	if len(list) != 0 {
+		t.Errorf("the resource was not expected to be indexed under its first email")
+		return
+	}
+}
@@ -0,0 +1,131 @@
+package disks
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/steve-care-software/database/domain/bytes"
+)
+
+// journalCheckpointMinimum is the amount of entries a journal must contain before it can be rewritten as a checkpoint
+const journalCheckpointMinimum = 128
+
+// journalCheckpointRatio is the amount of entries a journal can contain per live entry before it is rewritten as a checkpoint
+const journalCheckpointRatio = 2
+
+// journal appends the entries of an in-memory store to a file alongside the database file, the file being rewritten as a
+// checkpoint once most of its entries are overwritten
+type journal struct {
+	adapter      bytes.Adapter
+	filePath     string
+	tmpExtension string
+}
+
+func createJournal(
+	adapter bytes.Adapter,
+	filePath string,
+	tmpExtension string,
+) *journal {
+	out := journal{
+		adapter:      adapter,
+		filePath:     filePath,
+		tmpExtension: tmpExtension,
+	}
+
+	return &out
+}
+
+// read decodes the entries of the journal, stopping at the first entry that cannot be decoded, which belongs to an
+// interrupted write, a missing journal containing no entry
+func (app *journal) read() ([]interface{}, error) {
+	data, err := ioutil.ReadFile(app.filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return []interface{}{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	out := []interface{}{}
+	for len(data) > 0 {
+		ins, remaining, err := app.adapter.ToInstance(data)
+		if err != nil {
+			break
+		}
+
+		data = remaining
+		out = append(out, ins)
+	}
+
+	return out, nil
+}
+
+// isCheckpointDue returns true if the passed amount of journaled entries is large enough, compared to the passed amount of
+// live entries, for the journal to be rewritten as a checkpoint
+func (app *journal) isCheckpointDue(amount int, live int) bool {
+	return amount > journalCheckpointMinimum && amount > journalCheckpointRatio*live
+}
+
+// append appends the entries to the journal
+func (app *journal) append(entries []interface{}) error {
+	data, err := app.toBytes(entries)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(app.filePath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0777)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+	_, err = file.Write(data)
+	if err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
+
+// write replaces the journal by the entries
+func (app *journal) write(entries []interface{}) error {
+	data, err := app.toBytes(entries)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := fmt.Sprintf("%s.%s", app.filePath, app.tmpExtension)
+	err = ioutil.WriteFile(tmpPath, data, 0777)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, app.filePath)
+}
+
+// remove removes the journal, if any
+func (app *journal) remove() error {
+	err := os.Remove(app.filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (app *journal) toBytes(entries []interface{}) ([]byte, error) {
+	data := []byte{}
+	for _, oneEntry := range entries {
+		entryBytes, err := app.adapter.ToBytes(oneEntry)
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, entryBytes...)
+	}
+
+	return data, nil
+}
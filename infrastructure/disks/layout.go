@@ -0,0 +1,179 @@
+package disks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/steve-care-software/database/domain/failures"
+)
+
+// superblockMagic identifies the append-only layout of a database file
+var superblockMagic = []byte("SCDBAPP1")
+
+// originSuperblockMagic identifies the append-only layout of a database file whose resource indexes start at an origin
+var originSuperblockMagic = []byte("SCDBAPP2")
+
+const (
+	// superblockSize is the size of the superblock: its magic, then the offset and size of the head state record and the end of the valid data
+	superblockSize = 8 + 8 + 8 + 8
+
+	// originSuperblockSize is the size of the superblock followed by the origin of the resource indexes
+	originSuperblockSize = superblockSize + 8
+
+	// legacyStateSizeLength is the size of the state length prefix of the legacy layout
+	legacyStateSizeLength = 8
+)
+
+// layout locates the head state record and the resources of a database file.
+//
+// The append-only layout starts with a superblock, followed by the resources and state records in the order they were pushed.
+// Each push appends its data after the end of the valid data, then rewrites the superblock, so that the bytes left by an
+// interrupted push are ignored and overwritten by the next push.
+//
+// The legacy layout starts with the length of the head state, then the head state, followed by the resources, and is rewritten on
+// every push.  It remains readable, and is migrated to the append-only layout on the next push.
+//
+// The resource indexes of a compacted file start at the next index of the file it replaced, so that an index read from the
+// replaced file never locates a resource of the compacted file.
+type layout struct {
+	isLegacy    bool
+	stateOffset uint64
+	stateSize   uint64
+	base        uint64
+	origin      uint64
+	end         uint64
+}
+
+// readLayout reads the layout of a database file, nil if the file does not exist or is empty
+func readLayout(path string) (*layout, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+	header := make([]byte, originSuperblockSize)
+	amount, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	// the superblock is written after the data it locates, so the size is read after it:
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Size() <= 0 {
+		return nil, nil
+	}
+
+	fileSize := uint64(info.Size())
+	isSuperblock := amount >= superblockSize && bytes.Equal(header[:len(superblockMagic)], superblockMagic)
+	isOriginSuperblock := amount >= originSuperblockSize && bytes.Equal(header[:len(originSuperblockMagic)], originSuperblockMagic)
+	if isSuperblock || isOriginSuperblock {
+		out := layout{
+			isLegacy:    false,
+			stateOffset: binary.LittleEndian.Uint64(header[8:16]),
+			stateSize:   binary.LittleEndian.Uint64(header[16:24]),
+			base:        superblockSize,
+			origin:      0,
+			end:         binary.LittleEndian.Uint64(header[24:32]),
+		}
+
+		if isOriginSuperblock {
+			out.base = originSuperblockSize
+			out.origin = binary.LittleEndian.Uint64(header[32:40])
+		}
+
+		if out.end < out.base || out.end > fileSize || out.stateOffset+out.stateSize > out.end {
+			return nil, &failures.CorruptDataError{
+				Reason: fmt.Sprintf("the superblock (state offset: %d, state size: %d, end: %d) does not fit the database file (%d bytes)", out.stateOffset, out.stateSize, out.end, fileSize),
+			}
+		}
+
+		return &out, nil
+	}
+
+	if amount < legacyStateSizeLength {
+		return nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(dataLengthErrorPattern, legacyStateSizeLength, amount),
+		}
+	}
+
+	stateSize := binary.LittleEndian.Uint64(header[:legacyStateSizeLength])
+	base := stateSize + legacyStateSizeLength
+	if base > fileSize {
+		return nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the file size (%d bytes) cannot be smaller than the stateSize (%d bytes)", fileSize, base),
+		}
+	}
+
+	return &layout{
+		isLegacy:    true,
+		stateOffset: legacyStateSizeLength,
+		stateSize:   stateSize,
+		base:        base,
+		origin:      0,
+		end:         fileSize,
+	}, nil
+}
+
+// hasState returns true if the layout contains a head state record
+func (obj *layout) hasState() bool {
+	return obj.stateSize > 0
+}
+
+// nextIndex returns the index at which the next resource is written
+func (obj *layout) nextIndex() uint {
+	return uint(obj.origin + obj.end - obj.base)
+}
+
+// offset returns the offset of the resource at the passed index, false if the index was read from a replaced file
+func (obj *layout) offset(index uint) (uint64, bool) {
+	if uint64(index) < obj.origin {
+		return 0, false
+	}
+
+	return obj.base + uint64(index) - obj.origin, true
+}
+
+// superblock returns the superblock bytes of the layout
+func (obj *layout) superblock() []byte {
+	if obj.base == superblockSize {
+		out := make([]byte, superblockSize)
+		copy(out, superblockMagic)
+		binary.LittleEndian.PutUint64(out[8:16], obj.stateOffset)
+		binary.LittleEndian.PutUint64(out[16:24], obj.stateSize)
+		binary.LittleEndian.PutUint64(out[24:32], obj.end)
+		return out
+	}
+
+	out := make([]byte, originSuperblockSize)
+	copy(out, originSuperblockMagic)
+	binary.LittleEndian.PutUint64(out[8:16], obj.stateOffset)
+	binary.LittleEndian.PutUint64(out[16:24], obj.stateSize)
+	binary.LittleEndian.PutUint64(out[24:32], obj.end)
+	binary.LittleEndian.PutUint64(out[32:40], obj.origin)
+	return out
+}
+
+// newLayout returns the layout of an empty append-only database file whose resource indexes start at the passed origin
+func newLayout(origin uint64) *layout {
+	return &layout{
+		isLegacy:    false,
+		stateOffset: 0,
+		stateSize:   0,
+		base:        originSuperblockSize,
+		origin:      origin,
+		end:         originSuperblockSize,
+	}
+}
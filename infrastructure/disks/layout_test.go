@@ -0,0 +1,385 @@
+package disks
+
+import (
+	std_bytes "bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/resources"
+	"github.com/steve-care-software/database/domain/states"
+)
+
+func newLayoutServicesForTests(baseDir string) (resources.Repository, states.Repository, states.Service, string) {
+	application, err := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	if err != nil {
+		panic(err)
+	}
+
+	services, err := NewBuilder(baseDir, "commits", "contexts", "database.db", "tmp").Create().WithApplication(*application).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	resourceRepository := services.ResourceRepository()
+	stateRepository := services.StateRepository()
+	stateService := services.StateService()
+
+	return resourceRepository, stateRepository, stateService, filepath.Join(baseDir, application.String(), "database.db")
+}
+
+func insertForTests(stateService states.Service, values map[string][][]byte) {
+	insertCommitForTests(stateService, commits.NewCommitForTests(values))
+}
+
+func insertCommitForTests(stateService states.Service, commit commits.Commit) {
+	err := stateService.Insert(
+		commit,
+		func(ctx commits.Commit, state states.State) error {
+			return nil
+		},
+		func(ctx commits.Commit, err error) error {
+			return err
+		},
+	)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+func verifyValuesForTests(t *testing.T, resourceRepository resources.Repository, stateRepository states.Repository, expected int) bool {
+	head, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return false
+	}
+
+	amount := 0
+	for current := head; current != nil; {
+		for _, onePointer := range current.Pointers().List() {
+			res, err := resourceRepository.Retrieve(onePointer)
+			if err != nil {
+				t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+				return false
+			}
+
+			if !std_bytes.HasPrefix(res.Value(), []byte("value")) {
+				t.Errorf("the value (%s) was not expected", res.Value())
+				return false
+			}
+
+			amount++
+		}
+
+		if !current.HasPrevious() {
+			break
+		}
+
+		previous, err := current.Previous()
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return false
+		}
+
+		current = previous
+	}
+
+	if amount != expected {
+		t.Errorf("%d values were expected, %d returned", expected, amount)
+		return false
+	}
+
+	return true
+}
+
+func TestLayout_appendOnly_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	resourceRepository, stateRepository, stateService, dbFilePath := newLayoutServicesForTests(baseDir)
+	insertForTests(stateService, map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("value 1"),
+			[]byte("value 2"),
+		},
+	})
+
+	first, _ := readLayout(dbFilePath)
+	if first.isLegacy {
+		t.Errorf("the layout was expected to be append-only")
+		return
+	}
+
+	// simulate the bytes left by an interrupted push:
+	file, _ := os.OpenFile(dbFilePath, os.O_APPEND|os.O_WRONLY, 0777)
+	file.Write([]byte("interrupted push"))
+	file.Close()
+
+	if !verifyValuesForTests(t, resourceRepository, stateRepository, 2) {
+		return
+	}
+
+	insertForTests(stateService, map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("value 3"),
+		},
+	})
+
+	second, _ := readLayout(dbFilePath)
+	if second.stateOffset <= first.stateOffset || second.end <= first.end {
+		t.Errorf("the second state record was expected to be appended after the first one")
+		return
+	}
+
+	// the bytes of the interrupted push were overwritten by the second push:
+	data, _ := ioutil.ReadFile(dbFilePath)
+	if len(data) != int(second.end) {
+		t.Errorf("the bytes of the interrupted push were expected to be overwritten")
+		return
+	}
+
+	verifyValuesForTests(t, resourceRepository, stateRepository, 3)
+}
+
+func TestLayout_withoutOrigin_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	// the superblock written before the resource indexes had an origin:
+	resourceRepository, stateRepository, stateService, dbFilePath := newLayoutServicesForTests(baseDir)
+	withoutOrigin := layout{
+		base: superblockSize,
+		end:  superblockSize,
+	}
+
+	err := os.MkdirAll(filepath.Dir(dbFilePath), 0777)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = ioutil.WriteFile(dbFilePath, withoutOrigin.superblock(), 0777)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	insertForTests(stateService, map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("value 1"),
+			[]byte("value 2"),
+		},
+	})
+
+	insertForTests(stateService, map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("value 3"),
+		},
+	})
+
+	current, _ := readLayout(dbFilePath)
+	if current.base != superblockSize || current.origin != 0 {
+		t.Errorf("the superblock without an origin was expected to be kept")
+		return
+	}
+
+	verifyValuesForTests(t, resourceRepository, stateRepository, 3)
+}
+
+func TestLayout_migrateLegacy_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	resourceRepository, stateRepository, stateService, dbFilePath := newLayoutServicesForTests(baseDir)
+
+	// the fixture was written by the legacy layout, pushing the values 1 and 2, then the value 3:
+	legacy, err := ioutil.ReadFile("./testdata/baseline_database.db")
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(dbFilePath), 0777)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = ioutil.WriteFile(dbFilePath, legacy, 0777)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	retLegacy, _ := readLayout(dbFilePath)
+	if !retLegacy.isLegacy {
+		t.Errorf("the layout was expected to be legacy")
+		return
+	}
+
+	// the legacy layout remains readable:
+	if !verifyValuesForTests(t, resourceRepository, stateRepository, 3) {
+		return
+	}
+
+	// the next push migrates the file:
+	insertForTests(stateService, map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("value 4"),
+		},
+	})
+
+	migrated, _ := readLayout(dbFilePath)
+	if migrated.isLegacy {
+		t.Errorf("the layout was expected to be migrated")
+		return
+	}
+
+	verifyValuesForTests(t, resourceRepository, stateRepository, 4)
+}
+
+func TestLayout_stateRecords_doNotEmbedTheirChain_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	resourceRepository, stateRepository, stateService, dbFilePath := newLayoutServicesForTests(baseDir)
+	sizes := []uint64{}
+	for i := 0; i < 10; i++ {
+		insertForTests(stateService, map[string][][]byte{
+			"my_namespace": [][]byte{
+				[]byte(fmt.Sprintf("value %d", i)),
+			},
+		})
+
+		current, _ := readLayout(dbFilePath)
+		sizes = append(sizes, current.stateSize)
+	}
+
+	if sizes[9] > sizes[1]+16 {
+		t.Errorf("the state records were expected to keep the same size, %d bytes then %d bytes", sizes[1], sizes[9])
+		return
+	}
+
+	head, _, _ := stateRepository.Retrieve()
+	root, err := head.Root()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if head.Height() != 10 || root.Height() != 1 {
+		t.Errorf("the chain of the head state is invalid")
+		return
+	}
+
+	verifyValuesForTests(t, resourceRepository, stateRepository, 10)
+}
+
+func TestLayout_withCorruptHead_returnsError(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	_, stateRepository, stateService, dbFilePath := newLayoutServicesForTests(baseDir)
+	insertForTests(stateService, map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("value 1"),
+		},
+	})
+
+	// overwrite the head state record:
+	current, _ := readLayout(dbFilePath)
+	file, _ := os.OpenFile(dbFilePath, os.O_WRONLY, 0777)
+	file.WriteAt(make([]byte, current.stateSize), int64(current.stateOffset))
+	file.Close()
+
+	_, _, err := stateRepository.Retrieve()
+	if _, ok := err.(*failures.CorruptDataError); !ok {
+		t.Errorf("the error was expected to be a CorruptDataError, %v returned", err)
+		return
+	}
+
+	err = stateService.Insert(
+		commits.NewCommitForTests(map[string][][]byte{
+			"my_namespace": [][]byte{
+				[]byte("value 2"),
+			},
+		}),
+		func(ctx commits.Commit, state states.State) error {
+			t.Errorf("the insert was expected to fail")
+			return nil
+		},
+		func(ctx commits.Commit, err error) error {
+			return err
+		},
+	)
+
+	if _, ok := err.(*failures.CorruptDataError); !ok {
+		t.Errorf("the error was expected to be a CorruptDataError, %v returned", err)
+		return
+	}
+}
+
+func TestLayout_withCorruptPrevious_returnsError(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	_, stateRepository, stateService, dbFilePath := newLayoutServicesForTests(baseDir)
+	insertForTests(stateService, map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("value 1"),
+		},
+	})
+
+	first, _ := readLayout(dbFilePath)
+	insertForTests(stateService, map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("value 2"),
+		},
+	})
+
+	// overwrite the record of the previous state:
+	file, _ := os.OpenFile(dbFilePath, os.O_WRONLY, 0777)
+	file.WriteAt(make([]byte, first.stateSize), int64(first.stateOffset))
+	file.Close()
+
+	head, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !head.HasPrevious() {
+		t.Errorf("the head was expected to have a previous state")
+		return
+	}
+
+	_, err = head.Previous()
+	if _, ok := err.(*failures.CorruptDataError); !ok {
+		t.Errorf("the error was expected to be a CorruptDataError, %v returned", err)
+		return
+	}
+
+	_, err = head.Root()
+	if _, ok := err.(*failures.CorruptDataError); !ok {
+		t.Errorf("the error was expected to be a CorruptDataError, %v returned", err)
+		return
+	}
+}
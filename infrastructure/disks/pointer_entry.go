@@ -0,0 +1,36 @@
+package disks
+
+import (
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/pointers"
+)
+
+const pointerFileExtension = "pointers"
+
+// pointerWindow is the amount of states below the head whose pointers are resolved using the cumulative pointer index
+const pointerWindow = 64
+
+const (
+	// pointerEntryHead represents the state a pointer journal is up to date with
+	pointerEntryHead uint8 = iota
+
+	// pointerEntryPut represents the latest pointer of a resource in a pointer journal
+	pointerEntryPut
+)
+
+// the fields are encoded positionally, so the location of the record of the state that wrote a pointer is appended,
+// the entries written before it decoding with an unknown location:
+type pointerEntry struct {
+	Knd uint8
+	Hsh hash.Hash
+	Ptr pointers.Pointer
+	Off uint64
+	Sze uint64
+}
+
+func newPointerEntryMapping() map[string]interface{} {
+	mp := pointers.NewPointerMapping()
+	mp["github.com/steve-care-software/database/infrastructure/disks/pointerEntry"] = pointerEntry{}
+	mp["[]uint8"] = uint8(0)
+	return mp
+}
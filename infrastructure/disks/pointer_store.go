@@ -0,0 +1,372 @@
+package disks
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/states"
+)
+
+// pointerStore keeps the latest pointer of every resource at the head state in memory, along with the location of the
+// record of the state that wrote it, journaled alongside the database file
+type pointerStore struct {
+	mutex            sync.Mutex
+	journal          *journal
+	stateAdapter     bytes.Adapter
+	generation       *generation
+	databaseFilePath string
+	isLoaded         bool
+	head             states.State
+	latest           map[string]pointerEntry
+	amount           int
+}
+
+func createPointerStore(
+	adapter bytes.Adapter,
+	stateAdapter bytes.Adapter,
+	generation *generation,
+	databaseFilePath string,
+	filePath string,
+	tmpExtension string,
+) *pointerStore {
+	out := pointerStore{
+		journal:          createJournal(adapter, filePath, tmpExtension),
+		stateAdapter:     stateAdapter,
+		generation:       generation,
+		databaseFilePath: databaseFilePath,
+		isLoaded:         false,
+		head:             nil,
+		latest:           map[string]pointerEntry{},
+		amount:           0,
+	}
+
+	return &out
+}
+
+// resolve resolves the journal entry of a resource at a state of the window below the head, located by the passed layout
+// of the passed generation of the database file, returning false if the state is not in the window or if the resource was
+// written after it
+func (app *pointerStore) resolve(value uint64, current *layout, head states.State, state states.State, namespace string, resource hash.Hash) (pointerEntry, bool, error) {
+	if head == nil {
+		return pointerEntry{}, false, nil
+	}
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	err := app.sync(value, current, head)
+	if err != nil {
+		return pointerEntry{}, false, err
+	}
+
+	walked := app.head
+	for i := 0; i <= pointerWindow; i++ {
+		if walked.Hash().Compare(state.Hash()) {
+			entry, ok := app.latest[app.keyname(namespace, resource)]
+			if !ok {
+				return pointerEntry{}, true, &failures.ResourceNotFoundError{
+					Namespace: namespace,
+					Resource:  resource,
+				}
+			}
+
+			if entry.Ptr.IsDeleted() {
+				return pointerEntry{}, true, &failures.ResourceNotFoundError{
+					Namespace: namespace,
+					Resource:  resource,
+					IsDeleted: true,
+				}
+			}
+
+			return entry, true, nil
+		}
+
+		if walked.Pointers().Exists(namespace, resource) || !walked.HasPrevious() {
+			break
+		}
+
+		previous, err := walked.Previous()
+		if err != nil {
+			return pointerEntry{}, false, err
+		}
+
+		walked = previous
+	}
+
+	return pointerEntry{}, false, nil
+}
+
+// changes returns the journal entries that bring the index from the current head to the passed state, whose record is
+// written at the passed offset
+func (app *pointerStore) changes(current *layout, head states.State, state states.State, offset uint64, size uint64) ([]pointerEntry, error) {
+	// the database file cannot be replaced during a push:
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	err := app.sync(app.generation.current(), current, head)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []pointerEntry{}
+	for _, onePointer := range state.Pointers().List() {
+		entries = append(entries, pointerEntry{
+			Knd: pointerEntryPut,
+			Ptr: onePointer,
+			Off: offset,
+			Sze: size,
+		})
+	}
+
+	return append(entries, pointerEntry{
+		Knd: pointerEntryHead,
+		Hsh: state.Hash(),
+	}), nil
+}
+
+// apply journals the entries returned by changes once the state they lead to is the head
+func (app *pointerStore) apply(state states.State, entries []pointerEntry) error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	// a root state starts a new journal, so that nothing remains from a previous database:
+	write := app.append
+	if !state.HasPrevious() {
+		write = app.write
+	}
+
+	err := write(entries)
+	if err != nil {
+		// the journal is rebuilt from the states on the next access:
+		app.isLoaded = false
+		return err
+	}
+
+	if !state.HasPrevious() {
+		app.latest = map[string]pointerEntry{}
+		app.amount = 0
+	}
+
+	for _, oneEntry := range entries {
+		app.replay(oneEntry)
+	}
+
+	app.head = state
+
+	// the overwritten pointers remain in the journal, so it is rewritten once most of its pointers are overwritten:
+	if !app.journal.isCheckpointDue(app.amount, len(app.latest)) {
+		return nil
+	}
+
+	err = app.checkpoint()
+	if err != nil {
+		app.isLoaded = false
+		return err
+	}
+
+	return nil
+}
+
+// checkpoint rewrites the journal using the latest pointer of every resource
+func (app *pointerStore) checkpoint() error {
+	keynames := []string{}
+	for keyname := range app.latest {
+		keynames = append(keynames, keyname)
+	}
+
+	sort.Strings(keynames)
+	entries := []pointerEntry{}
+	for _, keyname := range keynames {
+		entries = append(entries, app.latest[keyname])
+	}
+
+	entries = append(entries, pointerEntry{
+		Knd: pointerEntryHead,
+		Hsh: app.head.Hash(),
+	})
+
+	err := app.write(entries)
+	if err != nil {
+		return err
+	}
+
+	app.amount = len(keynames)
+	return nil
+}
+
+// sync makes sure the index reflects the head state, located by the passed layout, replaying the journal or rebuilding it from the state records
+func (app *pointerStore) sync(value uint64, current *layout, head states.State) error {
+	if head == nil {
+		app.reset()
+		app.isLoaded = true
+		return nil
+	}
+
+	if app.isLoaded && app.head != nil && app.head.Hash().Compare(head.Hash()) {
+		return nil
+	}
+
+	app.reset()
+	journalHead, err := app.load()
+	if err != nil {
+		return err
+	}
+
+	if journalHead != nil && journalHead.Compare(head.Hash()) {
+		app.head = head
+		app.isLoaded = true
+		return nil
+	}
+
+	return app.rebuild(value, current, head)
+}
+
+// load replays the journal, returning the state it is up to date with, nil if there is none
+func (app *pointerStore) load() (hash.Hash, error) {
+	list, err := app.journal.read()
+	if err != nil {
+		return nil, err
+	}
+
+	// the entries written after the last head entry belong to an interrupted push, so they are discarded:
+	var head hash.Hash
+	pending := []pointerEntry{}
+	for _, ins := range list {
+		entry, ok := ins.(pointerEntry)
+		if !ok {
+			return nil, nil
+		}
+
+		if entry.Knd != pointerEntryHead {
+			pending = append(pending, entry)
+			continue
+		}
+
+		for _, onePending := range pending {
+			app.replay(onePending)
+		}
+
+		head = entry.Hsh
+		pending = []pointerEntry{}
+	}
+
+	return head, nil
+}
+
+// rebuild walks the state records from the head, the latest pointer of each resource winning, then rewrites the journal
+func (app *pointerStore) rebuild(value uint64, current *layout, head states.State) error {
+	unlock, err := app.generation.lockAt(value, head.Hash())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+	app.reset()
+	add := func(state states.State, offset uint64, size uint64) {
+		for _, onePointer := range state.Pointers().List() {
+			keyname := app.keyname(onePointer.Namespace(), onePointer.Resource())
+			if _, ok := app.latest[keyname]; ok {
+				continue
+			}
+
+			app.latest[keyname] = pointerEntry{
+				Knd: pointerEntryPut,
+				Ptr: onePointer,
+				Off: offset,
+				Sze: size,
+			}
+		}
+	}
+
+	offset, size := current.stateOffset, current.stateSize
+	for size > 0 {
+		record, isRecord, err := readStateRecord(app.stateAdapter, app.databaseFilePath, offset, size)
+		if err != nil {
+			return err
+		}
+
+		// the states written before the records existed embed their whole chain, so their records cannot be located:
+		if !isRecord {
+			for walked := record.Stte; ; {
+				add(walked, 0, 0)
+				if !walked.HasPrevious() {
+					break
+				}
+
+				previous, err := walked.Previous()
+				if err != nil {
+					return err
+				}
+
+				walked = previous
+			}
+
+			break
+		}
+
+		add(record.Stte, offset, size)
+		offset, size = record.PrevOff, record.PrevSze
+	}
+
+	app.head = head
+	err = app.checkpoint()
+	if err != nil {
+		return err
+	}
+
+	app.isLoaded = true
+	return nil
+}
+
+// replace executes the passed func replacing the database file once no read of the file is in progress, then removes the
+// journal, which references the former file, so that it is rebuilt from the states on the next access
+func (app *pointerStore) replace(fn func() error) error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	err := app.generation.replace(fn)
+	app.reset()
+	if err != nil {
+		return err
+	}
+
+	return app.journal.remove()
+}
+
+func (app *pointerStore) replay(entry pointerEntry) {
+	if entry.Knd != pointerEntryPut || entry.Ptr == nil {
+		return
+	}
+
+	app.latest[app.keyname(entry.Ptr.Namespace(), entry.Ptr.Resource())] = entry
+	app.amount++
+}
+
+func (app *pointerStore) keyname(namespace string, resource hash.Hash) string {
+	return fmt.Sprintf("%s/%s", namespace, resource.String())
+}
+
+func (app *pointerStore) reset() {
+	app.isLoaded = false
+	app.head = nil
+	app.latest = map[string]pointerEntry{}
+	app.amount = 0
+}
+
+func (app *pointerStore) append(entries []pointerEntry) error {
+	return app.journal.append(app.toList(entries))
+}
+
+func (app *pointerStore) write(entries []pointerEntry) error {
+	return app.journal.write(app.toList(entries))
+}
+
+func (app *pointerStore) toList(entries []pointerEntry) []interface{} {
+	out := []interface{}{}
+	for _, oneEntry := range entries {
+		out = append(out, oneEntry)
+	}
+
+	return out
+}
@@ -0,0 +1,222 @@
+package disks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/states"
+)
+
+// countedStateForTests counts the previous states loaded while walking its chain
+type countedStateForTests struct {
+	states.State
+	amount *int
+}
+
+// Previous returns the previous state, counting the load
+func (obj *countedStateForTests) Previous() (states.State, error) {
+	*obj.amount++
+	previous, err := obj.State.Previous()
+	if err != nil {
+		return nil, err
+	}
+
+	return &countedStateForTests{
+		State:  previous,
+		amount: obj.amount,
+	}, nil
+}
+
+func TestPointerStore_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	_, stateRepository, stateService, dbFilePath := newLayoutServicesForTests(baseDir)
+	first := commits.NewCommitForTests(map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("value first"),
+		},
+	})
+
+	var firstState states.State
+	err := stateService.Insert(first, func(ctx commits.Commit, state states.State) error {
+		firstState = state
+		return nil
+	}, func(ctx commits.Commit, err error) error {
+		return err
+	})
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	for i := 0; i < 100; i++ {
+		insertForTests(stateService, map[string][][]byte{
+			"my_namespace": [][]byte{
+				[]byte(fmt.Sprintf("value %d", i)),
+			},
+		})
+	}
+
+	firstValue := first.Values().List()[0]
+	head, _, _ := stateRepository.Retrieve()
+	amount := 0
+	counted := &countedStateForTests{
+		State:  head,
+		amount: &amount,
+	}
+
+	current, _ := readLayout(dbFilePath)
+	store := createPointerStore(newPointerAdapterForTests(), newStateAdapterForTests(), createGeneration(), dbFilePath, fmt.Sprintf("%s.%s", dbFilePath, pointerFileExtension), "tmp")
+	entry, isResolved, err := store.resolve(0, current, counted, counted, "my_namespace", firstValue.Resource())
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !isResolved || !entry.Ptr.Resource().Compare(firstValue.Resource()) || amount != 0 {
+		t.Errorf("the pointer written at height 1 was expected to be resolved at the head without walking the chain")
+		return
+	}
+
+	// a recent state only walks the states above it:
+	checkpoint := head
+	for i := 0; i < 3; i++ {
+		checkpoint, _ = checkpoint.Previous()
+	}
+
+	_, isResolved, err = store.resolve(0, current, counted, checkpoint, "my_namespace", firstValue.Resource())
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !isResolved || amount != 3 {
+		t.Errorf("the pointer was expected to be resolved at the checkpoint by loading %d states, %d loaded", 3, amount)
+		return
+	}
+
+	// a resource written after the state cannot be resolved using the index:
+	written := head.Pointers().List()[0]
+	_, isResolved, _ = store.resolve(0, current, head, checkpoint, "my_namespace", written.Resource())
+	if isResolved {
+		t.Errorf("the pointer written after the checkpoint was not expected to be resolved using the index")
+		return
+	}
+
+	// the state falls back on walking its chain:
+	_, err = checkpoint.Pointer("my_namespace", written.Resource())
+	if !errors.Is(err, failures.ErrResourceNotFound) {
+		t.Errorf("the error was expected to be a resource not found error, %v returned", err)
+		return
+	}
+
+	_, err = head.Pointer("my_namespace", written.Resource())
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	_, err = head.Pointer("other_namespace", written.Resource())
+	if !errors.Is(err, failures.ErrResourceNotFound) {
+		t.Errorf("the error was expected to be a resource not found error, %v returned", err)
+		return
+	}
+
+	// the journal is rebuilt from the states when it is missing:
+	os.Remove(fmt.Sprintf("%s.%s", dbFilePath, pointerFileExtension))
+	rebuilt := createPointerStore(newPointerAdapterForTests(), newStateAdapterForTests(), createGeneration(), dbFilePath, fmt.Sprintf("%s.%s", dbFilePath, pointerFileExtension), "tmp")
+	entry, isResolved, err = rebuilt.resolve(0, current, head, head, "my_namespace", firstValue.Resource())
+	if err != nil || !isResolved || !entry.Ptr.Resource().Compare(firstValue.Resource()) || entry.Sze <= 0 {
+		t.Errorf("the pointer was expected to be resolved, along with the location of its writer, once the journal is rebuilt")
+		return
+	}
+
+	// the state that wrote a pointer is located without walking the chain, so the records between them are never read:
+	headRecord, _, _ := readStateRecord(newStateAdapterForTests(), dbFilePath, current.stateOffset, current.stateSize)
+	file, _ := os.OpenFile(dbFilePath, os.O_WRONLY, 0777)
+	file.WriteAt(make([]byte, headRecord.PrevSze), int64(headRecord.PrevOff))
+	file.Close()
+
+	retHead, _, _ := stateRepository.Retrieve()
+	writer, err := retHead.Writer("my_namespace", firstValue.Resource())
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !writer.Hash().Compare(firstState.Hash()) {
+		t.Errorf("the writer was expected to be the first state")
+		return
+	}
+}
+
+func TestPointerStore_checkpoint_Success(t *testing.T) {
+	baseDir := "./test_files"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	_, stateRepository, stateService, dbFilePath := newLayoutServicesForTests(baseDir)
+	pointerFilePath := fmt.Sprintf("%s.%s", dbFilePath, pointerFileExtension)
+	sizes := []int64{}
+	for i := 0; i < journalCheckpointMinimum+journalCheckpointMinimum/2; i++ {
+		insertForTests(stateService, map[string][][]byte{
+			"my_namespace": [][]byte{
+				[]byte("value"),
+			},
+		})
+
+		info, err := os.Stat(pointerFilePath)
+		if err != nil {
+			t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+			return
+		}
+
+		sizes = append(sizes, info.Size())
+	}
+
+	// the same resource is overwritten by every push, so the journal is rewritten once it reaches the minimum:
+	last := sizes[len(sizes)-1]
+	if last >= sizes[journalCheckpointMinimum-1] {
+		t.Errorf("the journal was expected to be rewritten as a checkpoint, %d bytes then %d bytes", sizes[journalCheckpointMinimum-1], last)
+		return
+	}
+
+	// the checkpoint is replayed by a new store:
+	head, _, _ := stateRepository.Retrieve()
+	current, _ := readLayout(dbFilePath)
+	store := createPointerStore(newPointerAdapterForTests(), newStateAdapterForTests(), createGeneration(), dbFilePath, pointerFilePath, "tmp")
+	written := head.Pointers().List()[0]
+	entry, isResolved, err := store.resolve(0, current, head, head, "my_namespace", written.Resource())
+	if err != nil || !isResolved || entry.Off != current.stateOffset {
+		t.Errorf("the pointer was expected to be resolved from the checkpoint, along with the location of its writer")
+		return
+	}
+}
+
+func newStateAdapterForTests() bytes.Adapter {
+	adapter, err := bytes.NewAdapterBuilder().Create().WithMapping(newStateRecordMapping()).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	return adapter
+}
+
+func newPointerAdapterForTests() bytes.Adapter {
+	adapter, err := bytes.NewAdapterBuilder().Create().WithMapping(newPointerEntryMapping()).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	return adapter
+}
@@ -1,33 +1,32 @@
 package disks
 
 import (
-	"errors"
 	"fmt"
 	"os"
 
 	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/failures"
 	"github.com/steve-care-software/database/domain/pointers"
 	"github.com/steve-care-software/database/domain/resources"
-	"github.com/steve-care-software/database/domain/states"
 )
 
 type resourceRepository struct {
 	hashAdapter      hash.Adapter
 	resourceBuilder  resources.Builder
-	stateRepository  states.Repository
+	generation       *generation
 	databaseFilePath string
 }
 
 func createResourceRepository(
 	hashAdapter hash.Adapter,
 	resourceBuilder resources.Builder,
-	stateRepository states.Repository,
+	generation *generation,
 	databaseFilePath string,
 ) resources.Repository {
 	out := resourceRepository{
 		hashAdapter:      hashAdapter,
 		resourceBuilder:  resourceBuilder,
-		stateRepository:  stateRepository,
+		generation:       generation,
 		databaseFilePath: databaseFilePath,
 	}
 
@@ -36,46 +35,45 @@ func createResourceRepository(
 
 // NextIndex returns the pointer next index
 func (app *resourceRepository) NextIndex() (uint, error) {
-	// if the database file does not exists, return 0:
-	if _, err := os.Stat(app.databaseFilePath); errors.Is(err, os.ErrNotExist) {
-		return 0, nil
-	}
-
-	// if we can't retrieve the state, it means the file is empty, so the nextIndex is 0:
-	_, stateSize, err := app.stateRepository.Retrieve()
-	if err != nil {
-		return 0, nil
-	}
-
-	// open the file:
-	filePtr, err := os.Open(app.databaseFilePath)
-	if err != nil {
-		return 0, err
-	}
-
-	defer filePtr.Close()
-
-	file, err := filePtr.Stat()
+	// if the database file does not exists or is empty, the nextIndex is 0:
+	layout, err := readLayout(app.databaseFilePath)
 	if err != nil {
 		return 0, err
 	}
 
-	fileSize := uint(file.Size())
-	if fileSize < stateSize {
-		str := fmt.Sprintf("the file size (%d bytes) cannot be smaller than the stateSize (%d bytes)", fileSize, stateSize)
-		return 0, errors.New(str)
+	if layout == nil {
+		return 0, nil
 	}
 
-	return fileSize - stateSize, nil
+	return layout.nextIndex(), nil
 }
 
 // Retrieve retrieves a resource from a pointer
 func (app *resourceRepository) Retrieve(ptr pointers.Pointer) (resources.Resource, error) {
-	_, stateSize, err := app.stateRepository.Retrieve()
+	if ptr.IsDeleted() {
+		return nil, &failures.ResourceNotFoundError{
+			Namespace: ptr.Namespace(),
+			Resource:  ptr.Resource(),
+			IsDeleted: true,
+		}
+	}
+
+	// the database file cannot be replaced while the resource is read:
+	_, unlock := app.generation.lock()
+	defer unlock()
+
+	layout, err := readLayout(app.databaseFilePath)
 	if err != nil {
 		return nil, err
 	}
 
+	if layout == nil {
+		return nil, &failures.ResourceNotFoundError{
+			Namespace: ptr.Namespace(),
+			Resource:  ptr.Resource(),
+		}
+	}
+
 	// open the file:
 	filePtr, err := os.Open(app.databaseFilePath)
 	if err != nil {
@@ -84,7 +82,16 @@ func (app *resourceRepository) Retrieve(ptr pointers.Pointer) (resources.Resourc
 
 	defer filePtr.Close()
 
-	offset := stateSize + ptr.Index()
+	// a pointer held since before a compaction indexes the replaced file:
+	offset, isCurrent := layout.offset(ptr.Index())
+	if !isCurrent {
+		return nil, &failures.StaleStateError{
+			Namespace: ptr.Namespace(),
+			Resource:  ptr.Resource(),
+			IsPointer: true,
+		}
+	}
+
 	length := ptr.Length()
 	resData := make([]byte, length, length)
 	_, err = filePtr.ReadAt(resData, int64(offset))
@@ -93,8 +100,9 @@ func (app *resourceRepository) Retrieve(ptr pointers.Pointer) (resources.Resourc
 	}
 
 	if len(resData) <= hash.Size {
-		str := fmt.Sprintf(dataLengthErrorPattern, hash.Size, len(resData))
-		return nil, errors.New(str)
+		return nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf(dataLengthErrorPattern, hash.Size, len(resData)),
+		}
 	}
 
 	keyBytes := resData[:hash.Size]
@@ -103,7 +111,26 @@ func (app *resourceRepository) Retrieve(ptr pointers.Pointer) (resources.Resourc
 		return nil, err
 	}
 
+	// a pointer held since before an import could point to unrelated bytes:
+	if !key.Compare(ptr.Resource()) {
+		return nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the resource (hash: %s) was expected at the index %d, the resource (hash: %s) was found", ptr.Resource().String(), ptr.Index(), key.String()),
+		}
+	}
+
 	ptrIndex := ptr.Index()
 	namespace := ptr.Namespace()
-	return app.resourceBuilder.Create().WithNamespace(namespace).WithKey(*key).WithData(resData[hash.Size:]).WithIndex(ptrIndex).Now()
+	res, err := app.resourceBuilder.Create().WithNamespace(namespace).WithKey(*key).WithData(resData[hash.Size:]).WithIndex(ptrIndex).Now()
+	if err != nil {
+		return nil, err
+	}
+
+	// the pointers written before the digests existed cannot verify the bytes of their resource:
+	if ptr.HasDigest() && !res.Pointer().Digest().Compare(ptr.Digest()) {
+		return nil, &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the data of the resource (hash: %s) at the index %d does not match its digest (%s)", ptr.Resource().String(), ptr.Index(), ptr.Digest().String()),
+		}
+	}
+
+	return res, nil
 }
@@ -0,0 +1,17 @@
+package disks
+
+// schemaDirName is the name of the directory, in the application directory, where the schemas are persisted
+const schemaDirName = "schemas"
+
+// schemaRecord represents the schema of a namespace, persisted in a file named after the hash of its namespace
+type schemaRecord struct {
+	NmeSpace string
+	Dat      []byte
+}
+
+func newSchemaRecordMapping() map[string]interface{} {
+	return map[string]interface{}{
+		"github.com/steve-care-software/database/infrastructure/disks/schemaRecord": schemaRecord{},
+		"[]uint8": uint8(0),
+	}
+}
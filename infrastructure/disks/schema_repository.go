@@ -0,0 +1,91 @@
+package disks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/schemas"
+)
+
+type schemaRepository struct {
+	schemaAdapter bytes.Adapter
+	builder       schemas.Builder
+	baseDirPath   string
+	tmpExtension  string
+}
+
+func createSchemaRepository(
+	schemaAdapter bytes.Adapter,
+	builder schemas.Builder,
+	baseDirPath string,
+	tmpExtension string,
+) schemas.Repository {
+	out := schemaRepository{
+		schemaAdapter: schemaAdapter,
+		builder:       builder,
+		baseDirPath:   baseDirPath,
+		tmpExtension:  tmpExtension,
+	}
+
+	return &out
+}
+
+// List lists the schemas, sorted by namespace
+func (app *schemaRepository) List() ([]schemas.Schema, error) {
+	// if the base dir is not created, return an empty list:
+	if _, err := os.Stat(app.baseDirPath); os.IsNotExist(err) {
+		return []schemas.Schema{}, nil
+	}
+
+	files, err := ioutil.ReadDir(app.baseDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []schemas.Schema{}
+	for _, file := range files {
+		// a schema whose write was interrupted is left in its temporary file, and is ignored:
+		if file.IsDir() || filepath.Ext(file.Name()) == fmt.Sprintf(".%s", app.tmpExtension) {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(app.baseDirPath, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		ins, remaining, err := app.schemaAdapter.ToInstance(data)
+		if err != nil || len(remaining) > 0 {
+			return nil, &failures.CorruptDataError{
+				Reason: fmt.Sprintf("the schema file (name: %s) is corrupt", file.Name()),
+			}
+		}
+
+		record, ok := ins.(schemaRecord)
+		if !ok {
+			return nil, &failures.CorruptDataError{
+				Reason: fmt.Sprintf("the schema file (name: %s) could not be casted properly", file.Name()),
+			}
+		}
+
+		schema, err := app.builder.Create().WithNamespace(record.NmeSpace).WithData(record.Dat).Now()
+		if err != nil {
+			return nil, &failures.CorruptDataError{
+				Reason: fmt.Sprintf("the schema file (name: %s) is invalid: %s", file.Name(), err.Error()),
+			}
+		}
+
+		list = append(list, schema)
+	}
+
+	sort.Slice(list, func(i int, j int) bool {
+		return list[i].Namespace() < list[j].Namespace()
+	})
+
+	return list, nil
+}
@@ -0,0 +1,111 @@
+package disks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/schemas"
+)
+
+type schemaService struct {
+	hashAdapter   hash.Adapter
+	schemaAdapter bytes.Adapter
+	baseDirPath   string
+	tmpExtension  string
+}
+
+func createSchemaService(
+	hashAdapter hash.Adapter,
+	schemaAdapter bytes.Adapter,
+	baseDirPath string,
+	tmpExtension string,
+) schemas.Service {
+	out := schemaService{
+		hashAdapter:   hashAdapter,
+		schemaAdapter: schemaAdapter,
+		baseDirPath:   baseDirPath,
+		tmpExtension:  tmpExtension,
+	}
+
+	return &out
+}
+
+// Insert persists the schema of a namespace, replacing its previous schema, if any
+func (app *schemaService) Insert(schema schemas.Schema) error {
+	// if the base dir is not created, create it:
+	if _, err := os.Stat(app.baseDirPath); os.IsNotExist(err) {
+		err := os.MkdirAll(app.baseDirPath, 0777)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := app.schemaAdapter.ToBytes(schemaRecord{
+		NmeSpace: schema.Namespace(),
+		Dat:      schema.Data(),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	path, err := app.path(schema.Namespace())
+	if err != nil {
+		return err
+	}
+
+	// the schema is written to a temporary file, then renamed, so that a previous schema is never partially replaced:
+	tmpPath := fmt.Sprintf("%s.%s", path, app.tmpExtension)
+	file, err := os.OpenFile(tmpPath, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0777)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// Delete deletes the schema of a namespace
+func (app *schemaService) Delete(namespace string) error {
+	path, err := app.path(namespace)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &failures.NamespaceNotFoundError{
+			Namespace: namespace,
+		}
+	}
+
+	return err
+}
+
+func (app *schemaService) path(namespace string) (string, error) {
+	name, err := app.hashAdapter.FromBytes([]byte(namespace))
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(app.baseDirPath, name.String()), nil
+}
@@ -1,9 +1,15 @@
 package disks
 
 import (
+	"fmt"
+	"io"
+
 	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/contexts"
+	"github.com/steve-care-software/database/domain/indexes"
 	"github.com/steve-care-software/database/domain/pointers"
 	"github.com/steve-care-software/database/domain/resources"
+	"github.com/steve-care-software/database/domain/schemas"
 	"github.com/steve-care-software/database/domain/states"
 	"github.com/steve-care-software/database/domain/bytes"
 	"github.com/steve-care-software/cryptography/domain/hash"
@@ -15,19 +21,46 @@ const dataLengthErrorPattern = "the remaining data length was expected to be big
 func NewBuilder(
 	baseDirPath string,
 	commitDirPath string,
+	contextDirPath string,
 	dbFileName string,
 	dbTmpExtension string,
 ) Builder {
 	hashAdapter := hash.NewAdapter()
 	pointersBuilder := pointers.NewBuilder()
+	pointerBuilder := pointers.NewPointerBuilder()
 	resourceBuilder := resources.NewBuilder()
 	statesBuilder := states.NewBuilder()
+	contextsBuilder := contexts.NewBuilder()
+	savepointBuilder := contexts.NewSavepointBuilder()
+	commitBuilder := commits.NewBuilder()
+	metadataBuilder := commits.NewMetadataBuilder()
+	schemaBuilder := schemas.NewBuilder()
 	commitAdapter, err := bytes.NewAdapterBuilder().Create().WithMapping(commits.NewMapping()).Now()
 	if err != nil {
 		panic(err)
 	}
 
-	stateAdapter, err := bytes.NewAdapterBuilder().Create().WithMapping(states.NewMapping()).Now()
+	stateAdapter, err := bytes.NewAdapterBuilder().Create().WithMapping(newStateRecordMapping()).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	contextAdapter, err := bytes.NewAdapterBuilder().Create().WithMapping(newContextEntryMapping()).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	indexAdapter, err := bytes.NewAdapterBuilder().Create().WithMapping(newIndexEntryMapping()).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	pointerAdapter, err := bytes.NewAdapterBuilder().Create().WithMapping(newPointerEntryMapping()).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	schemaAdapter, err := bytes.NewAdapterBuilder().Create().WithMapping(newSchemaRecordMapping()).Now()
 	if err != nil {
 		panic(err)
 	}
@@ -36,19 +69,109 @@ func NewBuilder(
 		hashAdapter,
 		commitAdapter,
 		stateAdapter,
+		contextAdapter,
+		indexAdapter,
+		pointerAdapter,
+		schemaAdapter,
 		pointersBuilder,
+		pointerBuilder,
 		resourceBuilder,
 		statesBuilder,
+		contextsBuilder,
+		savepointBuilder,
+		commitBuilder,
+		metadataBuilder,
+		schemaBuilder,
 		baseDirPath,
 		commitDirPath,
+		contextDirPath,
 		dbFileName,
 		dbTmpExtension,
 	)
 }
 
+// NewLastStatesRetention returns a retention policy that keeps the full history of the last states, the head included
+func NewLastStatesRetention(amount uint) RetentionFn {
+	return func(state states.State, depth uint) bool {
+		return depth < amount
+	}
+}
+
+// NewOfflineCompactor creates a compactor of a database file that is not in use
+func NewOfflineCompactor(dbFilePath string, dbTmpExtension string) Compactor {
+	hashAdapter := hash.NewAdapter()
+	pointersBuilder := pointers.NewBuilder()
+	pointerBuilder := pointers.NewPointerBuilder()
+	resourceBuilder := resources.NewBuilder()
+	statesBuilder := states.NewBuilder()
+	stateAdapter, err := bytes.NewAdapterBuilder().Create().WithMapping(newStateRecordMapping()).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	pointerAdapter, err := bytes.NewAdapterBuilder().Create().WithMapping(newPointerEntryMapping()).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	generation := createGeneration()
+	pointerStore := createPointerStore(pointerAdapter, stateAdapter, generation, dbFilePath, fmt.Sprintf("%s.%s", dbFilePath, pointerFileExtension), dbTmpExtension)
+	stateRepository := createStateRepository(stateAdapter, pointerStore, generation, dbFilePath)
+	resourceRepository := createResourceRepository(hashAdapter, resourceBuilder, generation, dbFilePath)
+
+	// the secondary indexes only reference the resources of the head, which a compaction keeps:
+	indexStore, err := createIndexStore(nil, resourceRepository, stateRepository, []indexes.Index{}, "", dbTmpExtension)
+	if err != nil {
+		panic(err)
+	}
+
+	stateService := createStateService(hashAdapter, pointersBuilder, pointerBuilder, resourceBuilder, resourceRepository, indexStore, pointerStore, statesBuilder, stateAdapter, stateRepository, dbFilePath, dbTmpExtension)
+	return createCompactor(stateService)
+}
+
+// RetentionFn represents a retention policy, returning true if the full history of a state, by its depth below the head, must be kept
+type RetentionFn func(state states.State, depth uint) bool
+
+// Compactor represents a database compactor
+type Compactor interface {
+	Compact(retention RetentionFn) (Compaction, error)
+}
+
+// Compaction represents the outcome of a compaction
+type Compaction interface {
+	Retained() uint
+	Dropped() uint
+	Before() uint
+	After() uint
+	Reclaimed() uint
+}
+
+// Archiver represents the archiver of an application database
+type Archiver interface {
+	Export(w io.Writer) error
+	Import(r io.Reader) error
+}
+
 // Builder represents the disk builder
 type Builder interface {
 	Create() Builder
 	WithApplication(application hash.Hash) Builder
-	Now() (commits.Repository, commits.Service, resources.Repository, states.Repository, states.Service, error)
+	WithIndexes(indexes []indexes.Index) Builder
+	Now() (Services, error)
+}
+
+// Services represents the repositories and services of an application database
+type Services interface {
+	CommitRepository() commits.Repository
+	CommitService() commits.Service
+	ContextRepository() contexts.Repository
+	ContextService() contexts.Service
+	ResourceRepository() resources.Repository
+	StateRepository() states.Repository
+	StateService() states.Service
+	IndexRepository() indexes.Repository
+	SchemaRepository() schemas.Repository
+	SchemaService() schemas.Service
+	Compactor() Compactor
+	Archiver() Archiver
 }
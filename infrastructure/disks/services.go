@@ -0,0 +1,117 @@
+package disks
+
+import (
+	"github.com/steve-care-software/database/domain/commits"
+	"github.com/steve-care-software/database/domain/contexts"
+	"github.com/steve-care-software/database/domain/indexes"
+	"github.com/steve-care-software/database/domain/resources"
+	"github.com/steve-care-software/database/domain/schemas"
+	"github.com/steve-care-software/database/domain/states"
+)
+
+type services struct {
+	commitRepository   commits.Repository
+	commitService      commits.Service
+	contextRepository  contexts.Repository
+	contextService     contexts.Service
+	resourceRepository resources.Repository
+	stateRepository    states.Repository
+	stateService       states.Service
+	indexRepository    indexes.Repository
+	schemaRepository   schemas.Repository
+	schemaService      schemas.Service
+	compactor          Compactor
+	archiver           Archiver
+}
+
+func createServices(
+	commitRepository commits.Repository,
+	commitService commits.Service,
+	contextRepository contexts.Repository,
+	contextService contexts.Service,
+	resourceRepository resources.Repository,
+	stateRepository states.Repository,
+	stateService states.Service,
+	indexRepository indexes.Repository,
+	schemaRepository schemas.Repository,
+	schemaService schemas.Service,
+	compactor Compactor,
+	archiver Archiver,
+) Services {
+	out := services{
+		commitRepository:   commitRepository,
+		commitService:      commitService,
+		contextRepository:  contextRepository,
+		contextService:     contextService,
+		resourceRepository: resourceRepository,
+		stateRepository:    stateRepository,
+		stateService:       stateService,
+		indexRepository:    indexRepository,
+		schemaRepository:   schemaRepository,
+		schemaService:      schemaService,
+		compactor:          compactor,
+		archiver:           archiver,
+	}
+
+	return &out
+}
+
+// CommitRepository returns the commit repository
+func (obj *services) CommitRepository() commits.Repository {
+	return obj.commitRepository
+}
+
+// CommitService returns the commit service
+func (obj *services) CommitService() commits.Service {
+	return obj.commitService
+}
+
+// ContextRepository returns the context repository
+func (obj *services) ContextRepository() contexts.Repository {
+	return obj.contextRepository
+}
+
+// ContextService returns the context service
+func (obj *services) ContextService() contexts.Service {
+	return obj.contextService
+}
+
+// ResourceRepository returns the resource repository
+func (obj *services) ResourceRepository() resources.Repository {
+	return obj.resourceRepository
+}
+
+// StateRepository returns the state repository
+func (obj *services) StateRepository() states.Repository {
+	return obj.stateRepository
+}
+
+// StateService returns the state service
+func (obj *services) StateService() states.Service {
+	return obj.stateService
+}
+
+// IndexRepository returns the secondary index repository
+func (obj *services) IndexRepository() indexes.Repository {
+	return obj.indexRepository
+}
+
+// SchemaRepository returns the schema repository
+func (obj *services) SchemaRepository() schemas.Repository {
+	return obj.schemaRepository
+}
+
+// SchemaService returns the schema service
+func (obj *services) SchemaService() schemas.Service {
+	return obj.schemaService
+}
+
+// Compactor returns the compactor
+func (obj *services) Compactor() Compactor {
+	return obj.compactor
+}
+
+// Archiver returns the archiver
+func (obj *services) Archiver() Archiver {
+	return obj.archiver
+}
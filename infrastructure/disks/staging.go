@@ -0,0 +1,182 @@
+package disks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// importFileExtension is the extension of the staging directory and of the marker of an import
+const importFileExtension = "import"
+
+const (
+	// stagedDatabaseName is the name of the database file in the staging directory of an import
+	stagedDatabaseName = "database"
+
+	// stagedCommitDirName is the name of the commit directory in the staging directory of an import
+	stagedCommitDirName = "commits"
+
+	// stagedContextDirName is the name of the context directory in the staging directory of an import
+	stagedContextDirName = "contexts"
+
+	// stagedSchemaDirName is the name of the schema directory in the staging directory of an import
+	stagedSchemaDirName = "schemas"
+)
+
+// staging locates the directory where an import is staged, alongside the database file, and the marker written once
+// the staged data is verified.
+//
+// The staged database file and directories replace the current ones one at a time, so a crash can interrupt the
+// activation.  While the marker exists, the activation is completed by the next one, each staged entry that remains
+// replacing its current entry, then the marker is removed.
+type staging struct {
+	dirPath    string
+	markerPath string
+	targets    map[string]string
+}
+
+func createStaging(
+	databaseFilePath string,
+	commitDirPath string,
+	contextDirPath string,
+	schemaDirPath string,
+	tmpExtension string,
+) *staging {
+	out := staging{
+		dirPath:    fmt.Sprintf("%s.%s.%s", databaseFilePath, importFileExtension, tmpExtension),
+		markerPath: fmt.Sprintf("%s.%s", databaseFilePath, importFileExtension),
+		targets: map[string]string{
+			stagedDatabaseName:   databaseFilePath,
+			stagedCommitDirName:  commitDirPath,
+			stagedContextDirName: contextDirPath,
+			stagedSchemaDirName:  schemaDirPath,
+		},
+	}
+
+	return &out
+}
+
+// path returns the path of a staged entry
+func (app *staging) path(name string) string {
+	return filepath.Join(app.dirPath, name)
+}
+
+// prepare replaces the staging directory by an empty one
+func (app *staging) prepare() error {
+	err := os.RemoveAll(app.dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, oneName := range []string{stagedCommitDirName, stagedContextDirName, stagedSchemaDirName} {
+		err = os.MkdirAll(app.path(oneName), 0777)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// discard removes the staging directory, unless the marker requires it to complete an activation
+func (app *staging) discard() error {
+	isPending, err := app.isPending()
+	if err != nil || isPending {
+		return err
+	}
+
+	return os.RemoveAll(app.dirPath)
+}
+
+// isPending returns true if an activation was interrupted
+func (app *staging) isPending() (bool, error) {
+	_, err := os.Stat(app.markerPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// recover completes an activation interrupted by a crash, if any, removing the passed journals first, since they
+// reference the replaced database file
+func (app *staging) recover(journalPaths []string) error {
+	isPending, err := app.isPending()
+	if err != nil || !isPending {
+		return err
+	}
+
+	for _, onePath := range journalPaths {
+		err = os.Remove(onePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return app.complete()
+}
+
+// activate writes the marker, then replaces the current entries by the staged ones
+func (app *staging) activate() error {
+	file, err := os.OpenFile(app.markerPath, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0777)
+	if err != nil {
+		return err
+	}
+
+	err = file.Sync()
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(app.markerPath)
+		return err
+	}
+
+	return app.complete()
+}
+
+// complete replaces the current entries by the staged ones that remain, then removes the marker and the staging directory
+func (app *staging) complete() error {
+	names := []string{}
+	for oneName := range app.targets {
+		names = append(names, oneName)
+	}
+
+	sort.Strings(names)
+	for _, oneName := range names {
+		stagedPath := app.path(oneName)
+		_, err := os.Stat(stagedPath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = os.RemoveAll(app.targets[oneName])
+		if err != nil {
+			return err
+		}
+
+		err = os.Rename(stagedPath, app.targets[oneName])
+		if err != nil {
+			return err
+		}
+	}
+
+	err := os.Remove(app.markerPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return os.RemoveAll(app.dirPath)
+}
@@ -0,0 +1,74 @@
+package disks
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/states"
+)
+
+// stateRecord represents a state persisted on its own, along with the location of the record of its previous state
+type stateRecord struct {
+	Stte    states.State
+	PrevOff uint64
+	PrevSze uint64
+}
+
+func newStateRecordMapping() map[string]interface{} {
+	mp := states.NewMapping()
+	mp["github.com/steve-care-software/database/infrastructure/disks/stateRecord"] = stateRecord{}
+	return mp
+}
+
+// readStateRecord reads and decodes the state record at the passed offset of a database file, a failure meaning that the file is
+// corrupt.  It returns false when the record is a state written before the records existed, which embeds its whole chain.
+func readStateRecord(adapter bytes.Adapter, databaseFilePath string, offset uint64, size uint64) (stateRecord, bool, error) {
+	record, isRecord, err := decodeStateRecord(adapter, databaseFilePath, offset, size)
+	if err != nil {
+		if _, ok := err.(*failures.CorruptDataError); ok {
+			return stateRecord{}, false, err
+		}
+
+		return stateRecord{}, false, &failures.CorruptDataError{
+			Reason: fmt.Sprintf("the state record (offset: %d, size: %d) could not be read: %s", offset, size, err.Error()),
+		}
+	}
+
+	return record, isRecord, nil
+}
+
+func decodeStateRecord(adapter bytes.Adapter, databaseFilePath string, offset uint64, size uint64) (stateRecord, bool, error) {
+	ptr, err := os.Open(databaseFilePath)
+	if err != nil {
+		return stateRecord{}, false, err
+	}
+
+	defer ptr.Close()
+	stateBytes := make([]byte, size, size)
+	_, err = ptr.ReadAt(stateBytes, int64(offset))
+	if err != nil {
+		return stateRecord{}, false, err
+	}
+
+	ins, _, err := adapter.ToInstance(stateBytes)
+	if err != nil {
+		return stateRecord{}, false, err
+	}
+
+	if casted, ok := ins.(states.State); ok {
+		return stateRecord{
+			Stte: casted,
+		}, false, nil
+	}
+
+	record, ok := ins.(stateRecord)
+	if !ok || record.Stte == nil {
+		return stateRecord{}, false, &failures.CorruptDataError{
+			Reason: "the State []byte could not be casted properly",
+		}
+	}
+
+	return record, true, nil
+}
@@ -1,72 +1,185 @@
 package disks
 
 import (
-	"encoding/binary"
-	"errors"
 	"os"
+	"sync"
 
+	"github.com/steve-care-software/cryptography/domain/hash"
 	"github.com/steve-care-software/database/domain/bytes"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/pointers"
 	"github.com/steve-care-software/database/domain/states"
 )
 
 type stateRepository struct {
+	mutex            sync.Mutex
 	stateAdapter     bytes.Adapter
+	pointerStore     *pointerStore
+	generation       *generation
 	databaseFilePath string
+	cachedInfo       os.FileInfo
+	cachedLayout     layout
+	cachedGeneration uint64
+	cachedHead       states.State
 }
 
 func createStateRepository(
 	stateAdapter bytes.Adapter,
+	pointerStore *pointerStore,
+	generation *generation,
 	databaseFilePath string,
 ) states.Repository {
 	out := stateRepository{
 		stateAdapter:     stateAdapter,
+		pointerStore:     pointerStore,
+		generation:       generation,
 		databaseFilePath: databaseFilePath,
+		cachedInfo:       nil,
+		cachedLayout:     layout{},
+		cachedGeneration: 0,
+		cachedHead:       nil,
 	}
 
 	return &out
 }
 
-// Retrieve returns the head state
+// Retrieve returns the head state, along with the offset from which the indexes of its pointers are relative
 func (app *stateRepository) Retrieve() (states.State, uint, error) {
-	// if the database file does not exists, return nil:
-	if _, err := os.Stat(app.databaseFilePath); errors.Is(err, os.ErrNotExist) {
+	value, unlock := app.generation.lock()
+	defer unlock()
+
+	// if the database file does not exists or is still empty, return nil:
+	layout, err := readLayout(app.databaseFilePath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if layout == nil || !layout.hasState() {
 		return nil, 0, nil
 	}
 
-	// open the file:
-	ptr, err := os.Open(app.databaseFilePath)
+	// read the head state record, its predecessors being loaded on demand:
+	state, err := app.read(value, layout.stateOffset, layout.stateSize)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	defer ptr.Close()
+	return state, uint(layout.base), nil
+}
 
-	// read the first 8 bytes:
-	stateSizeLength := 8
-	stateSizeInBytes := make([]byte, stateSizeLength, stateSizeLength)
-	_, err = ptr.Read(stateSizeInBytes)
+// read reads and decodes the state record at the passed offset of the passed generation of the database file, a failure
+// meaning that the file is corrupt
+func (app *stateRepository) read(value uint64, offset uint64, size uint64) (states.State, error) {
+	record, isRecord, err := readStateRecord(app.stateAdapter, app.databaseFilePath, offset, size)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+
+	// the states written before the records existed embed their whole chain:
+	if !isRecord {
+		return record.Stte, nil
 	}
 
-	stateSize := binary.LittleEndian.Uint64(stateSizeInBytes)
+	// the previous record is only read from the generation of the file its location was read from:
+	lazy, err := states.NewLazyState(record.Stte, func(previous hash.Hash) (states.State, error) {
+		unlock, err := app.generation.lockAt(value, previous)
+		if err != nil {
+			return nil, err
+		}
+
+		defer unlock()
+		return app.read(value, record.PrevOff, record.PrevSze)
+	})
 
-	// read the state:
-	stateBytes := make([]byte, stateSize, stateSize)
-	_, err = ptr.Read(stateBytes)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+
+	return states.NewIndexedState(lazy, func(state states.State, namespace string, resource hash.Hash) (pointers.Pointer, states.State, bool, error) {
+		return app.resolve(value, state, namespace, resource)
+	})
+}
+
+// head returns the head state along with the layout locating it and the generation of the database file, reading its
+// record only when the superblock or the database file changed since the last call
+func (app *stateRepository) head() (states.State, *layout, uint64, error) {
+	value, unlock := app.generation.lock()
+	defer unlock()
+
+	info, err := os.Stat(app.databaseFilePath)
+	if os.IsNotExist(err) {
+		return nil, nil, value, nil
 	}
 
-	// converts the bytes to a state instance:
-	state, _, err := app.stateAdapter.ToInstance(stateBytes)
 	if err != nil {
-		return nil, 0, nil
+		return nil, nil, value, err
+	}
+
+	current, err := readLayout(app.databaseFilePath)
+	if err != nil {
+		return nil, nil, value, err
+	}
+
+	if current == nil || !current.hasState() {
+		return nil, current, value, nil
 	}
 
-	if casted, ok := state.(states.State); ok {
-		return casted, uint(stateSize) + uint(stateSizeLength), nil
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	// a database file replaced by another process is never the same file:
+	isSameFile := app.cachedInfo != nil && os.SameFile(app.cachedInfo, info) && app.cachedInfo.ModTime().Equal(info.ModTime())
+	if isSameFile && app.cachedGeneration == value && app.cachedLayout == *current {
+		return app.cachedHead, current, value, nil
+	}
+
+	head, err := app.read(value, current.stateOffset, current.stateSize)
+	if err != nil {
+		return nil, nil, value, err
+	}
+
+	app.cachedInfo = info
+	app.cachedLayout = *current
+	app.cachedGeneration = value
+	app.cachedHead = head
+	return head, current, value, nil
+}
+
+// resolve resolves the pointer of a resource at a state read from the passed generation of the database file, along with
+// the state that wrote it, using the cumulative pointer index of the head
+func (app *stateRepository) resolve(value uint64, state states.State, namespace string, resource hash.Hash) (pointers.Pointer, states.State, bool, error) {
+	head, current, headValue, err := app.head()
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	// the pointers of a state read from a replaced database file reference the former file:
+	if headValue != value {
+		return nil, nil, false, &failures.StaleStateError{
+			State: state.Hash(),
+		}
+	}
+
+	entry, isResolved, err := app.pointerStore.resolve(value, current, head, state, namespace, resource)
+	if err != nil || !isResolved {
+		return nil, nil, isResolved, err
+	}
+
+	// the entries journaled before the location of the writer existed leave it unknown:
+	if entry.Sze <= 0 {
+		return entry.Ptr, nil, true, nil
+	}
+
+	unlock, err := app.generation.lockAt(value, state.Hash())
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	defer unlock()
+	writer, err := app.read(value, entry.Off, entry.Sze)
+	if err != nil {
+		return nil, nil, false, err
 	}
 
-	return nil, 0, errors.New("the State []byte could not be casted properly")
+	return entry.Ptr, writer, true, nil
 }
@@ -2,19 +2,20 @@ package disks
 
 import (
 	"bytes"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
 	domain_bytes "github.com/steve-care-software/database/domain/bytes"
 	"github.com/steve-care-software/database/domain/commits"
 	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/failures"
 	"github.com/steve-care-software/database/domain/pointers"
 	"github.com/steve-care-software/database/domain/resources"
 	"github.com/steve-care-software/database/domain/states"
@@ -23,8 +24,11 @@ import (
 type stateService struct {
 	hashAdapter        hash.Adapter
 	pointersBuilder    pointers.Builder
+	pointerBuilder     pointers.PointerBuilder
 	resourceBuilder    resources.Builder
 	resourceRepository resources.Repository
+	indexStore         *indexStore
+	pointerStore       *pointerStore
 	builder            states.Builder
 	adapter            domain_bytes.Adapter
 	repository         states.Repository
@@ -36,19 +40,25 @@ type stateService struct {
 func createStateService(
 	hashAdapter hash.Adapter,
 	pointersBuilder pointers.Builder,
+	pointerBuilder pointers.PointerBuilder,
 	resourceBuilder resources.Builder,
 	resourceRepository resources.Repository,
+	indexStore *indexStore,
+	pointerStore *pointerStore,
 	builder states.Builder,
 	adapter domain_bytes.Adapter,
 	repository states.Repository,
 	databaseFilePath string,
 	tmpExtension string,
-) states.Service {
+) *stateService {
 	out := stateService{
 		hashAdapter:        hashAdapter,
 		pointersBuilder:    pointersBuilder,
+		pointerBuilder:     pointerBuilder,
 		resourceBuilder:    resourceBuilder,
 		resourceRepository: resourceRepository,
+		indexStore:         indexStore,
+		pointerStore:       pointerStore,
 		builder:            builder,
 		adapter:            adapter,
 		repository:         repository,
@@ -61,54 +71,240 @@ func createStateService(
 
 // Insert inserts a state instance from the passed commit
 func (app *stateService) Insert(commit commits.Commit, worked states.SuccessCallBackFn, failed states.FailCallBackFn) error {
+	return app.insert(
+		[]commits.Commit{
+			commit,
+		},
+		nil,
+		func(state states.State) error {
+			return worked(commit, state)
+		},
+		func(err error) error {
+			return failed(commit, err)
+		},
+	)
+}
+
+// InsertOnto inserts a state instance from the passed commit, only if the head state is the expected one, an empty hash expecting an empty database
+func (app *stateService) InsertOnto(commit commits.Commit, expectedHead hash.Hash, worked states.SuccessCallBackFn, failed states.FailCallBackFn) error {
+	return app.insert(
+		[]commits.Commit{
+			commit,
+		},
+		&expectedHead,
+		func(state states.State) error {
+			return worked(commit, state)
+		},
+		func(err error) error {
+			return failed(commit, err)
+		},
+	)
+}
+
+// InsertAll inserts a single state instance merging the passed commits, atomically
+func (app *stateService) InsertAll(list []commits.Commit, worked states.SuccessAllCallBackFn, failed states.FailAllCallBackFn) error {
+	if len(list) <= 0 {
+		return failed(list, errors.New("there must be at least 1 Commit in order to insert a State instance"))
+	}
+
+	return app.insert(
+		list,
+		nil,
+		func(state states.State) error {
+			return worked(list, state)
+		},
+		func(err error) error {
+			return failed(list, err)
+		},
+	)
+}
+
+func (app *stateService) insert(list []commits.Commit, expectedHead *hash.Hash, worked func(state states.State) error, failed func(err error) error) error {
+	// lock the mutex during the whole insertion so that the head cannot change and unlock when we exit the fn:
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
 	// if the database directory does not exists, create it:
 	resDir := filepath.Dir(app.databaseFilePath)
 	if _, err := os.Stat(resDir); os.IsNotExist(err) {
 		err := os.MkdirAll(resDir, 0777)
 		if err != nil {
-			return failed(commit, err)
+			return failed(err)
 		}
 	}
 
-	// if the database file does not exists, create it:
-	if _, err := os.Stat(app.databaseFilePath); errors.Is(err, os.ErrNotExist) {
-		err = ioutil.WriteFile(app.databaseFilePath, []byte{}, 0777)
+	// if the database file does not exists or uses the legacy layout, create or migrate it:
+	layout, err := app.prepare()
+	if err != nil {
+		return failed(err)
+	}
+
+	// retrieve the head state, if any:
+	head, _, err := app.repository.Retrieve()
+	if err != nil {
+		return failed(err)
+	}
+
+	// make sure the head is the expected one, if any:
+	if expectedHead != nil {
+		var current hash.Hash
+		if head != nil {
+			current = head.Hash()
+		}
+
+		if !current.Compare(*expectedHead) {
+			return failed(&failures.HeadMismatchError{
+				Expected: *expectedHead,
+				Current:  current,
+			})
+		}
+	}
+
+	// make sure no state pushed after the base of each commit conflicts with it:
+	for _, oneCommit := range list {
+		err := app.verifyConflicts(oneCommit, head)
 		if err != nil {
-			return failed(commit, err)
+			return failed(err)
 		}
 	}
 
 	// create the state instance:
-	state, resources, prevStateSizeInBytes, err := app.createStateInstance(commit)
+	state, resources, err := app.createStateInstance(list, head)
+	if err != nil {
+		return failed(err)
+	}
+
+	// compute the changes of the secondary indexes, before the head changes:
+	indexEntries, err := app.indexStore.changes(state, resources)
+	if err != nil {
+		return failed(err)
+	}
+
+	// convert the state record to bytes, referencing the record of the head state:
+	record := stateRecord{
+		Stte: state,
+	}
+
+	if head != nil {
+		record.PrevOff = layout.stateOffset
+		record.PrevSze = layout.stateSize
+	}
+
+	stateBytes, err := app.adapter.ToBytes(record)
+	if err != nil {
+		return failed(err)
+	}
+
+	// combine the resources, then the state record:
+	data := []byte{}
+	for _, oneResource := range resources {
+		data = append(data, oneResource.Pointer().Resource().Bytes()...)
+		data = append(data, oneResource.Value()...)
+	}
+
+	stateOffset := layout.end + uint64(len(data))
+	data = append(data, stateBytes...)
+
+	// compute the changes of the cumulative pointer index, before the head changes:
+	pointerEntries, err := app.pointerStore.changes(layout, head, state, stateOffset, uint64(len(stateBytes)))
+	if err != nil {
+		return failed(err)
+	}
+
+	// open the database file:
+	file, err := os.OpenFile(app.databaseFilePath, os.O_WRONLY, 0777)
+	if err != nil {
+		return failed(err)
+	}
+
+	defer file.Close()
+
+	// append the data after the end of the valid data, overwriting what an interrupted push could have left:
+	_, err = file.WriteAt(data, int64(layout.end))
 	if err != nil {
-		return failed(commit, err)
+		return failed(err)
 	}
 
-	// convert the state to bytes:
-	stateBytes, err := app.adapter.ToBytes(state)
+	err = file.Sync()
 	if err != nil {
-		return failed(commit, err)
+		return failed(err)
 	}
 
-	var stateSize uint64 = uint64(len(stateBytes))
-	stateSizeBuf := new(bytes.Buffer)
-	err = binary.Write(stateSizeBuf, binary.LittleEndian, stateSize)
+	// journal the changes of the cumulative pointer index, which is rebuilt if the superblock is not written after it:
+	err = app.pointerStore.apply(state, pointerEntries)
 	if err != nil {
-		return failed(commit, err)
+		return failed(err)
 	}
 
-	// open the tmp database file:
+	// journal the changes of the secondary indexes, which are rebuilt if the superblock is not written after them:
+	err = app.indexStore.apply(indexEntries)
+	if err != nil {
+		return failed(err)
+	}
+
+	// point the superblock to the new head state record:
+	layout.stateOffset = stateOffset
+	layout.stateSize = uint64(len(stateBytes))
+	layout.end = stateOffset + layout.stateSize
+	_, err = file.WriteAt(layout.superblock(), 0)
+	if err != nil {
+		return failed(err)
+	}
+
+	err = file.Sync()
+	if err != nil {
+		return failed(err)
+	}
+
+	// execute the worked callback, once every write is durable:
+	return worked(state)
+}
+
+// prepare returns the layout of the database file, creating the file or migrating it from the legacy layout when needed
+func (app *stateService) prepare() (*layout, error) {
+	current, err := readLayout(app.databaseFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		current = newLayout(0)
+		err = ioutil.WriteFile(app.databaseFilePath, current.superblock(), 0777)
+		if err != nil {
+			return nil, err
+		}
+
+		return current, nil
+	}
+
+	if !current.isLegacy {
+		return current, nil
+	}
+
+	return app.migrate(current)
+}
+
+// migrate rewrites a legacy database file using the append-only layout.
+//
+// The resources keep their position relative to the base, so the pointers of every state remain valid.
+func (app *stateService) migrate(legacy *layout) (*layout, error) {
 	fin, err := os.Open(app.databaseFilePath)
 	if err != nil {
-		return failed(commit, err)
+		return nil, err
 	}
+
 	defer fin.Close()
 
-	// open the output tmp file:
+	stateBytes := make([]byte, legacy.stateSize, legacy.stateSize)
+	_, err = fin.ReadAt(stateBytes, int64(legacy.stateOffset))
+	if err != nil {
+		return nil, err
+	}
+
 	resTmpPath := fmt.Sprintf("%s.%s", app.databaseFilePath, app.tmpExtension)
-	fout, err := os.OpenFile(resTmpPath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0777)
+	fout, err := os.OpenFile(resTmpPath, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0777)
 	if err != nil {
-		return failed(commit, err)
+		return nil, err
 	}
 
 	defer fout.Close()
@@ -116,71 +312,146 @@ func (app *stateService) Insert(commit commits.Commit, worked states.SuccessCall
 		os.Remove(resTmpPath)
 	}()
 
-	// add the state bytes at the beginning of the file:
-	allStateBytes := stateSizeBuf.Bytes()
-	allStateBytes = append(allStateBytes, stateBytes...)
-	_, err = fout.Write(allStateBytes)
+	migrated := newLayout(0)
+	_, err = fout.Write(migrated.superblock())
 	if err != nil {
-		return failed(commit, err)
+		return nil, err
 	}
 
-	// if there is a previous state:
-	if prevStateSizeInBytes > 0 {
-		// offset the original state data from the database file:
-		_, err = fin.Seek(int64(prevStateSizeInBytes), io.SeekStart)
-		if err != nil {
-			return failed(commit, err)
+	// copy the resources:
+	_, err = fin.Seek(int64(legacy.base), io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := io.Copy(fout, fin)
+	if err != nil {
+		return nil, err
+	}
+
+	// append the head state record:
+	migrated.stateOffset = migrated.base + uint64(amount)
+	migrated.stateSize = uint64(len(stateBytes))
+	migrated.end = migrated.stateOffset + migrated.stateSize
+	_, err = fout.Write(stateBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fout.WriteAt(migrated.superblock(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fout.Sync()
+	if err != nil {
+		return nil, err
+	}
+
+	// rename and replace the tmp database file for the real database file:
+	err = os.Rename(resTmpPath, app.databaseFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrated, nil
+}
+
+func (app *stateService) verifyConflicts(commit commits.Commit, head states.State) error {
+	if !commit.HasBase() {
+		return nil
+	}
+
+	base := commit.Base()
+	if head == nil {
+		return &failures.StateNotFoundError{
+			State: base,
 		}
+	}
 
-		// copy the original resource data to the tmp file:
-		_, err = io.Copy(fout, fin)
-		if err != nil {
-			return failed(commit, err)
+	keys := map[string]map[string]bool{}
+	addKey := func(namespace string, resource hash.Hash) {
+		if _, ok := keys[namespace]; !ok {
+			keys[namespace] = map[string]bool{}
 		}
+
+		keys[namespace][resource.String()] = true
 	}
 
-	// combine the resources:
-	resBytes := []byte{}
-	for _, oneResource := range resources {
-		resBytes = append(resBytes, oneResource.Pointer().Resource().Bytes()...)
-		resBytes = append(resBytes, oneResource.Value()...)
+	for _, oneValue := range commit.Values().List() {
+		addKey(oneValue.Namespace(), oneValue.Resource())
 	}
 
-	// append the new resource to the tmp database file:
-	if _, err = fout.Write(resBytes); err != nil {
-		return failed(commit, err)
+	for _, oneRead := range commit.Reads() {
+		addKey(oneRead.Namespace(), oneRead.Resource())
 	}
 
-	// lock the mutex during the rename file operations and unlock when we exit the fn:
-	app.mutex.Lock()
-	defer app.mutex.Unlock()
+	current := head
+	for !current.Hash().Compare(base) {
+		// a compacted root is the snapshot of the last state dropped by a compaction, its pointers cumulating the dropped states:
+		if !current.HasPrevious() && current.IsCompacted() {
+			return &failures.StateNotFoundError{
+				State:       base,
+				IsCompacted: true,
+			}
+		}
 
-	// execute the worked callback:
-	err = worked(commit)
-	if err != nil {
-		return err
+		for _, onePointer := range current.Pointers().List() {
+			namespace := onePointer.Namespace()
+			resource := onePointer.Resource()
+			if _, ok := keys[namespace][resource.String()]; ok {
+				return &states.ConflictError{
+					Commit:    commit.Hash(),
+					State:     current.Hash(),
+					Namespace: namespace,
+					Resource:  resource,
+				}
+			}
+		}
+
+		if !current.HasPrevious() {
+			return &failures.StateNotFoundError{
+				State: base,
+			}
+		}
+
+		previous, err := current.Previous()
+		if err != nil {
+			return err
+		}
+
+		current = previous
 	}
 
-	// rename and replace the tmp database file for the real resource file:
-	return os.Rename(resTmpPath, app.databaseFilePath)
+	return nil
 }
 
-func (app *stateService) createStateInstance(commit commits.Commit) (states.State, []resources.Resource, uint, error) {
+func (app *stateService) createStateInstance(list []commits.Commit, prev states.State) (states.State, []resources.Resource, error) {
 	nextIndex, err := app.resourceRepository.NextIndex()
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, nil, err
 	}
 
 	ptrList := []pointers.Pointer{}
 	resources := []resources.Resource{}
-	values := commit.Values().List()
+	values := app.mergeValues(list)
 	for _, oneValue := range values {
 		namespace := oneValue.Namespace()
 		resource := oneValue.Resource()
+		if oneValue.IsDeleted() {
+			ptr, err := app.pointerBuilder.Create().WithNamespace(namespace).WithResource(resource).IsDeleted().Now()
+			if err != nil {
+				return nil, nil, err
+			}
+
+			ptrList = append(ptrList, ptr)
+			continue
+		}
+
 		data := oneValue.Data()
 		res, err := app.resourceBuilder.Create().WithNamespace(namespace).WithKey(resource).WithData(data).WithIndex(nextIndex).Now()
 		if err != nil {
-			return nil, nil, 0, err
+			return nil, nil, err
 		}
 
 		ptr := res.Pointer()
@@ -191,20 +462,55 @@ func (app *stateService) createStateInstance(commit commits.Commit) (states.Stat
 
 	ptrs, err := app.pointersBuilder.Create().WithList(ptrList).Now()
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, nil, err
 	}
 
 	createdOn := time.Now().UTC()
-	builder := app.builder.Create().WithPointers(ptrs).CreatedOn(createdOn)
-	prev, sizeInBytes, _ := app.repository.Retrieve()
+	builder := app.builder.Create().WithPointers(ptrs).WithCommits(list).CreatedOn(createdOn)
 	if prev != nil {
 		builder.WithPrevious(prev)
 	}
 
 	ins, err := builder.Now()
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, nil, err
+	}
+
+	return ins, resources, nil
+}
+
+// mergeValues merges the values of the commits, ordered by creation time then by hash, so that the latest commit writing a resource wins
+func (app *stateService) mergeValues(list []commits.Commit) []commits.Value {
+	sorted := make([]commits.Commit, len(list))
+	copy(sorted, list)
+	sort.SliceStable(sorted, func(i int, j int) bool {
+		first := sorted[i].CreatedOn()
+		second := sorted[j].CreatedOn()
+		if !first.Equal(second) {
+			return first.Before(second)
+		}
+
+		return bytes.Compare(sorted[i].Hash().Bytes(), sorted[j].Hash().Bytes()) < 0
+	})
+
+	keys := []string{}
+	values := map[string]commits.Value{}
+	for _, oneCommit := range sorted {
+		for _, oneValue := range oneCommit.Values().List() {
+			keyname := fmt.Sprintf("%s/%s", oneValue.Namespace(), oneValue.Resource().String())
+			if _, ok := values[keyname]; !ok {
+				keys = append(keys, keyname)
+			}
+
+			values[keyname] = oneValue
+		}
+	}
+
+	sort.Strings(keys)
+	out := []commits.Value{}
+	for _, keyname := range keys {
+		out = append(out, values[keyname])
 	}
 
-	return ins, resources, sizeInBytes, nil
+	return out
 }
@@ -2,16 +2,21 @@ package disks
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/steve-care-software/database/domain/commits"
 	"github.com/steve-care-software/cryptography/domain/hash"
+	"github.com/steve-care-software/database/domain/failures"
+	"github.com/steve-care-software/database/domain/states"
 )
 
 func TestState_Success(t *testing.T) {
 	baseDir := "./test_files"
 	commitDirPath := "commits"
+	contextDirPath := "contexts"
 	dbFileName := "database.db"
 	dbTmpExtension := ".tmp"
 	defer func() {
@@ -31,14 +36,18 @@ func TestState_Success(t *testing.T) {
 		},
 	})
 
-	_, _, resourceRepository, stateRepository, stateService, err := NewBuilder(baseDir, commitDirPath, dbFileName, dbTmpExtension).Create().WithApplication(*application).Now()
+	services, err := NewBuilder(baseDir, commitDirPath, contextDirPath, dbFileName, dbTmpExtension).Create().WithApplication(*application).Now()
 	if err != nil {
 		panic(err)
 	}
 
+	resourceRepository := services.ResourceRepository()
+	stateRepository := services.StateRepository()
+	stateService := services.StateService()
+
 	err = stateService.Insert(
 		commit,
-		func(ctx commits.Commit) error {
+		func(ctx commits.Commit, state states.State) error {
 			if !ctx.Hash().Compare(commit.Hash()) {
 				t.Errorf("the commit is invalid")
 				return nil
@@ -110,7 +119,7 @@ func TestState_Success(t *testing.T) {
 	// insert some more commits:
 	err = stateService.Insert(
 		secondCommit,
-		func(ctx commits.Commit) error {
+		func(ctx commits.Commit, state states.State) error {
 			if !ctx.Hash().Compare(secondCommit.Hash()) {
 				t.Errorf("the commit is invalid")
 				return nil
@@ -199,3 +208,319 @@ func TestState_Success(t *testing.T) {
 		return
 	}
 }
+
+func TestState_withDeletion_Success(t *testing.T) {
+	baseDir := "./test_files"
+	commitDirPath := "commits"
+	contextDirPath := "contexts"
+	dbFileName := "database.db"
+	dbTmpExtension := ".tmp"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	application, err := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	if err != nil {
+		panic(err)
+	}
+
+	commit := commits.NewCommitForTests(map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("this is the first element"),
+			[]byte("this is the second element"),
+		},
+	})
+
+	services, err := NewBuilder(baseDir, commitDirPath, contextDirPath, dbFileName, dbTmpExtension).Create().WithApplication(*application).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	resourceRepository := services.ResourceRepository()
+	stateRepository := services.StateRepository()
+	stateService := services.StateService()
+
+	worked := func(ctx commits.Commit, state states.State) error {
+		return nil
+	}
+
+	failed := func(ctx commits.Commit, err error) error {
+		t.Errorf("the execution was expected to work, error returned: %s", err.Error())
+		return nil
+	}
+
+	err = stateService.Insert(commit, worked, failed)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	firstState, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	deleted := commit.Values().List()[0].Resource()
+	deleteCommit, err := commits.NewBuilder().Create().CreatedOn(time.Now().UTC()).WithValues(map[string]map[string][]byte{
+		"my_namespace": map[string][]byte{
+			deleted.String(): nil,
+		},
+	}).Now()
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = stateService.Insert(deleteCommit, worked, failed)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	secondState, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	_, err = secondState.Pointer("my_namespace", deleted)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	ptr, err := firstState.Pointer("my_namespace", deleted)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	res, err := resourceRepository.Retrieve(ptr)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !res.Pointer().Resource().Compare(deleted) {
+		t.Errorf("the resource was expected to be %s, %s returned", deleted.String(), res.Pointer().Resource().String())
+		return
+	}
+
+	tombstone, err := secondState.Pointers().Fetch("my_namespace", deleted)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	_, err = resourceRepository.Retrieve(tombstone)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+}
+
+func TestState_withConflict_returnsError(t *testing.T) {
+	baseDir := "./test_files"
+	commitDirPath := "commits"
+	contextDirPath := "contexts"
+	dbFileName := "database.db"
+	dbTmpExtension := ".tmp"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	application, err := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	if err != nil {
+		panic(err)
+	}
+
+	commit := commits.NewCommitForTests(map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("this is the first element"),
+		},
+	})
+
+	services, err := NewBuilder(baseDir, commitDirPath, contextDirPath, dbFileName, dbTmpExtension).Create().WithApplication(*application).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	stateRepository := services.StateRepository()
+	stateService := services.StateService()
+
+	worked := func(ctx commits.Commit, state states.State) error {
+		return nil
+	}
+
+	failed := func(ctx commits.Commit, err error) error {
+		return err
+	}
+
+	err = stateService.Insert(commit, worked, failed)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	base, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	resource := commit.Values().List()[0].Resource()
+	createCommit := func(data []byte) commits.Commit {
+		ins, err := commits.NewBuilder().Create().CreatedOn(time.Now().UTC()).WithBase(base.Hash()).WithValues(map[string]map[string][]byte{
+			"my_namespace": map[string][]byte{
+				resource.String(): data,
+			},
+		}).Now()
+
+		if err != nil {
+			panic(err)
+		}
+
+		return ins
+	}
+
+	err = stateService.Insert(createCommit([]byte("first update")), worked, failed)
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	err = stateService.Insert(createCommit([]byte("second update")), worked, failed)
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	var conflict *states.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Errorf("the error was expected to be a conflict, %s returned", err.Error())
+		return
+	}
+
+	if !errors.Is(err, failures.ErrConflict) {
+		t.Errorf("the error was expected to match the conflict sentinel error")
+		return
+	}
+
+	if !conflict.Resource.Compare(resource) {
+		t.Errorf("the conflicting resource was expected to be %s, %s returned", resource.String(), conflict.Resource.String())
+		return
+	}
+}
+
+func TestState_InsertAll_withConflict_insertsNothing(t *testing.T) {
+	baseDir := "./test_files"
+	commitDirPath := "commits"
+	contextDirPath := "contexts"
+	dbFileName := "database.db"
+	dbTmpExtension := ".tmp"
+	defer func() {
+		os.RemoveAll(baseDir)
+	}()
+
+	application, err := hash.NewAdapter().FromBytes([]byte("this is some data"))
+	if err != nil {
+		panic(err)
+	}
+
+	services, err := NewBuilder(baseDir, commitDirPath, contextDirPath, dbFileName, dbTmpExtension).Create().WithApplication(*application).Now()
+	if err != nil {
+		panic(err)
+	}
+
+	stateRepository := services.StateRepository()
+	stateService := services.StateService()
+
+	commit := commits.NewCommitForTests(map[string][][]byte{
+		"my_namespace": [][]byte{
+			[]byte("this is the first element"),
+		},
+	})
+
+	err = stateService.Insert(commit, func(ctx commits.Commit, state states.State) error {
+		return nil
+	}, func(ctx commits.Commit, err error) error {
+		return err
+	})
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	base, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	resource := commit.Values().List()[0].Resource()
+	createCommit := func(data []byte) commits.Commit {
+		ins, err := commits.NewBuilder().Create().CreatedOn(time.Now().UTC()).WithBase(base.Hash()).WithValues(map[string]map[string][]byte{
+			"my_namespace": map[string][]byte{
+				resource.String(): data,
+			},
+		}).Now()
+
+		if err != nil {
+			panic(err)
+		}
+
+		return ins
+	}
+
+	err = stateService.Insert(createCommit([]byte("first update")), func(ctx commits.Commit, state states.State) error {
+		return nil
+	}, func(ctx commits.Commit, err error) error {
+		return err
+	})
+
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	head, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	list := []commits.Commit{
+		commits.NewCommitForTests(map[string][][]byte{
+			"my_namespace": [][]byte{
+				[]byte("this is an unrelated element"),
+			},
+		}),
+		createCommit([]byte("second update")),
+	}
+
+	err = stateService.InsertAll(list, func(list []commits.Commit, state states.State) error {
+		t.Errorf("the insertion was expected to fail")
+		return nil
+	}, func(list []commits.Commit, err error) error {
+		return err
+	})
+
+	if err == nil {
+		t.Errorf("the error was expected to be valid, nil returned")
+		return
+	}
+
+	retHead, _, err := stateRepository.Retrieve()
+	if err != nil {
+		t.Errorf("the error was expected to be nil, error returned: %s", err.Error())
+		return
+	}
+
+	if !retHead.Hash().Compare(head.Hash()) {
+		t.Errorf("the head was expected to remain %s, %s returned", head.Hash().String(), retHead.Hash().String())
+		return
+	}
+}